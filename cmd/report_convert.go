@@ -76,13 +76,15 @@ target.`)),
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		var writer writers.Writer
-		var err error
 		if convertCmdFlags.toExt == ".sqlite3" {
-			writer, err = writers.NewDbWriter(fmt.Sprintf("sqlite://%s", convertCmdFlags.toFile), false)
+			dbWriter, err := writers.NewDbWriter(fmt.Sprintf("sqlite://%s", convertCmdFlags.toFile), false)
 			if err != nil {
 				log.Error("could not get a database writer up", "err", err)
 				return
 			}
+			defer dbWriter.Close()
+
+			writer = dbWriter
 			if err := convertFromJsonlTo(convertCmdFlags.fromFile, writer); err != nil {
 				log.Error("failed to convert to SQLite", "err", err)
 				return
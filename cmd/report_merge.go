@@ -9,6 +9,7 @@ import (
 
 	"github.com/glebarez/sqlite"
 	"github.com/sensepost/gowitness/internal/ascii"
+	"github.com/sensepost/gowitness/pkg/database"
 	"github.com/sensepost/gowitness/pkg/log"
 	"github.com/sensepost/gowitness/pkg/models"
 	"github.com/spf13/cobra"
@@ -211,27 +212,50 @@ func createOutputDatabase(path string) (*gorm.DB, error) {
 		&models.TLS{},
 		&models.TLSSanList{},
 		&models.Technology{},
+		&models.Tag{},
+		&models.Category{},
+		&models.DefaultCredential{},
+		&models.ImageHash{},
+		&models.Favicon{},
 		&models.Header{},
 		&models.NetworkLog{},
 		&models.ConsoleLog{},
 		&models.Cookie{},
+		&models.Link{},
+		&models.Form{},
+		&models.FormInput{},
+		&models.Script{},
+		&models.MetaTag{},
+		&models.BlockedRequest{},
+		&models.SavedSearch{},
+		&models.AlertRule{},
 	); err != nil {
 		return nil, err
 	}
 
+	if err := database.UseFullTextIndex(db, "sqlite"); err != nil {
+		return nil, err
+	}
+
 	return db, nil
 }
 
 func copyData(source *gorm.DB, dest *gorm.DB) error {
 	batchSize := 10
 	var results []models.Result
-	if err := source.Model(&models.Result{}).Preload(clause.Associations).Preload("TLS.SanList").
+	// new IDs of copied results, to point discovered results at the
+	// result they were discovered from. Results are copied in ID order,
+	// so that one is always copied first.
+	newIDs := make(map[uint]uint)
+	if err := source.Model(&models.Result{}).Preload(clause.Associations).Preload("TLS.SanList").Preload("Forms.Inputs").
 		FindInBatches(&results, batchSize, func(tx *gorm.DB, batch int) error {
 			// Begin a transaction in the destination database
 			return dest.Transaction(func(destTx *gorm.DB) error {
 				for _, result := range results {
 					// Reset IDs
+					oldResultID := result.ID
 					result.ID = 0
+					result.DiscoveredFromID = newIDs[result.DiscoveredFromID]
 					// Remove associations
 					headers := result.Headers
 					result.Headers = nil
@@ -243,6 +267,26 @@ func copyData(source *gorm.DB, dest *gorm.DB) error {
 					result.Cookies = nil
 					technologies := result.Technologies
 					result.Technologies = nil
+					tags := result.Tags
+					result.Tags = nil
+					categories := result.Categories
+					result.Categories = nil
+					defaultCredentials := result.DefaultCredentials
+					result.DefaultCredentials = nil
+					links := result.Links
+					result.Links = nil
+					forms := result.Forms
+					result.Forms = nil
+					scripts := result.Scripts
+					result.Scripts = nil
+					metaTags := result.MetaTags
+					result.MetaTags = nil
+					blockedRequests := result.BlockedRequests
+					result.BlockedRequests = nil
+					imageHashes := result.ImageHashes
+					result.ImageHashes = nil
+					favicon := result.Favicon
+					result.Favicon = models.Favicon{}
 					tlsData := result.TLS
 					result.TLS = models.TLS{}
 
@@ -251,6 +295,7 @@ func copyData(source *gorm.DB, dest *gorm.DB) error {
 						return fmt.Errorf("failed to insert Result: %w", err)
 					}
 					newResultID := result.ID
+					newIDs[oldResultID] = newResultID
 
 					// Insert TLS Data
 					if tlsData.Protocol != "" || tlsData.Issuer != "" {
@@ -330,6 +375,125 @@ func copyData(source *gorm.DB, dest *gorm.DB) error {
 							return fmt.Errorf("failed to insert Technologies: %w", err)
 						}
 					}
+
+					// Insert Tags
+					for i := range tags {
+						tags[i].ID = 0
+						tags[i].ResultID = newResultID
+					}
+					if len(tags) > 0 {
+						if err := destTx.Create(&tags).Error; err != nil {
+							return fmt.Errorf("failed to insert Tags: %w", err)
+						}
+					}
+
+					// Insert Categories
+					for i := range categories {
+						categories[i].ID = 0
+						categories[i].ResultID = newResultID
+					}
+					if len(categories) > 0 {
+						if err := destTx.Create(&categories).Error; err != nil {
+							return fmt.Errorf("failed to insert Categories: %w", err)
+						}
+					}
+
+					// Insert Default Credentials
+					for i := range defaultCredentials {
+						defaultCredentials[i].ID = 0
+						defaultCredentials[i].ResultID = newResultID
+					}
+					if len(defaultCredentials) > 0 {
+						if err := destTx.Create(&defaultCredentials).Error; err != nil {
+							return fmt.Errorf("failed to insert Default Credentials: %w", err)
+						}
+					}
+
+					// Insert Links
+					for i := range links {
+						links[i].ID = 0
+						links[i].ResultID = newResultID
+					}
+					if len(links) > 0 {
+						if err := destTx.Create(&links).Error; err != nil {
+							return fmt.Errorf("failed to insert Links: %w", err)
+						}
+					}
+
+					// Insert Forms, along with their Inputs
+					for i := range forms {
+						forms[i].ID = 0
+						forms[i].ResultID = newResultID
+						for j := range forms[i].Inputs {
+							forms[i].Inputs[j].ID = 0
+							forms[i].Inputs[j].FormID = 0
+						}
+					}
+					if len(forms) > 0 {
+						if err := destTx.Create(&forms).Error; err != nil {
+							return fmt.Errorf("failed to insert Forms: %w", err)
+						}
+					}
+
+					// Insert Scripts
+					for i := range scripts {
+						scripts[i].ID = 0
+						scripts[i].ResultID = newResultID
+					}
+					if len(scripts) > 0 {
+						if err := destTx.Create(&scripts).Error; err != nil {
+							return fmt.Errorf("failed to insert Scripts: %w", err)
+						}
+					}
+
+					// Insert Meta Tags
+					for i := range metaTags {
+						metaTags[i].ID = 0
+						metaTags[i].ResultID = newResultID
+					}
+					if len(metaTags) > 0 {
+						if err := destTx.Create(&metaTags).Error; err != nil {
+							return fmt.Errorf("failed to insert Meta Tags: %w", err)
+						}
+					}
+
+					// Insert Blocked Requests
+					for i := range blockedRequests {
+						blockedRequests[i].ID = 0
+						blockedRequests[i].ResultID = newResultID
+					}
+					if len(blockedRequests) > 0 {
+						if err := destTx.Create(&blockedRequests).Error; err != nil {
+							return fmt.Errorf("failed to insert Blocked Requests: %w", err)
+						}
+					}
+
+					// Insert Image Hashes
+					for i := range imageHashes {
+						imageHashes[i].ID = 0
+						imageHashes[i].ResultID = newResultID
+					}
+					if len(imageHashes) > 0 {
+						if err := destTx.Create(&imageHashes).Error; err != nil {
+							return fmt.Errorf("failed to insert Image Hashes: %w", err)
+						}
+					}
+
+					// Insert Favicon
+					if len(favicon.Content) > 0 {
+						favicon.ID = 0
+						favicon.ResultID = newResultID
+						if err := destTx.Create(&favicon).Error; err != nil {
+							return fmt.Errorf("failed to insert Favicon: %w", err)
+						}
+					}
+
+					// Index the merged result for full-text search
+					result.Headers = headers
+					result.Console = consoleLogs
+					if err := database.FullText(destTx).Index(destTx, &result); err != nil {
+						return fmt.Errorf("failed to index Result: %w", err)
+					}
 				}
 				return nil
 			})
@@ -64,6 +64,7 @@ and as a result will remain empty.`)),
 			log.Error("could not open new database", "err", err)
 			return
 		}
+		defer writer.Close()
 
 		// read URLs from the source database, and write to the destination
 		var v2URLs []oldv2.URL
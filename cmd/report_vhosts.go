@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/charmbracelet/x/term"
+	"github.com/sensepost/gowitness/internal/ascii"
+	"github.com/sensepost/gowitness/pkg/database"
+	"github.com/sensepost/gowitness/pkg/log"
+	"github.com/sensepost/gowitness/pkg/models"
+	"github.com/sensepost/gowitness/pkg/vhosts"
+	"github.com/spf13/cobra"
+)
+
+var vhostsCmdFlags = struct {
+	DbURI       string
+	JsonFile    string
+	DiffersOnly bool
+}{}
+var vhostsCmd = &cobra.Command{
+	Use:   "vhosts",
+	Short: "Compare virtual host responses to the responses of their IP",
+	Long: ascii.LogoHelp(ascii.Markdown(`
+# report vhosts
+
+Compare virtual host responses to the responses of their IP.
+
+Results from _gowitness scan vhost_ are grouped by the IP, scheme and port
+they were requested from. Each virtual host is compared to the response of the
+IP without a hostname (the baseline), by status code, title and perception
+hash group. A virtual host that differs from the baseline is likely configured
+on the server, while one that matches probably got the default site.`)),
+	Example: ascii.Markdown(`
+- gowitness report vhosts
+- gowitness report vhosts --differs-only
+- gowitness report vhosts --json-file gowitness.jsonl`),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if vhostsCmdFlags.DbURI == "" && vhostsCmdFlags.JsonFile == "" {
+			return errors.New("no data source defined")
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		var results = []*models.Result{}
+
+		if vhostsCmdFlags.JsonFile != "" {
+			file, err := os.Open(vhostsCmdFlags.JsonFile)
+			if err != nil {
+				log.Error("could not open JSON Lines file", "err", err)
+				return
+			}
+			defer file.Close()
+
+			scanner := bufio.NewScanner(file)
+			scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+			for scanner.Scan() {
+				if len(scanner.Bytes()) == 0 {
+					continue
+				}
+
+				var result models.Result
+				if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+					log.Error("could not unmarshal JSON line", "err", err)
+					continue
+				}
+				results = append(results, &result)
+			}
+			if err := scanner.Err(); err != nil {
+				log.Error("error reading JSON Lines file", "err", err)
+				return
+			}
+		} else {
+			conn, err := database.Connection(vhostsCmdFlags.DbURI, true, false)
+			if err != nil {
+				log.Error("could not connect to database", "err", err)
+				return
+			}
+
+			// baselines are not marked, so every result is a candidate.
+			// only the columns compared and listed are loaded
+			if err := conn.Model(&models.Result{}).
+				Select("id", "url", "probed_at", "vhost", "vhost_ip", "response_code",
+					"title", "content_length", "failed", "perception_hash_group_id").
+				Find(&results).Error; err != nil {
+				log.Error("could not get results", "err", err)
+				return
+			}
+		}
+
+		comparisons := vhosts.Compare(results)
+		if len(comparisons) == 0 {
+			log.Warn("no virtual host results found")
+			return
+		}
+
+		renderVHostsTable(comparisons, vhostsCmdFlags.DiffersOnly)
+	},
+}
+
+func init() {
+	reportCmd.AddCommand(vhostsCmd)
+
+	vhostsCmd.Flags().StringVar(&vhostsCmdFlags.DbURI, "db-uri", "sqlite://gowitness.sqlite3", "The location of a gowitness database")
+	vhostsCmd.Flags().StringVar(&vhostsCmdFlags.JsonFile, "json-file", "", "The location of a JSON Lines results file (e.g., ./gowitness.jsonl). This flag takes precedence over --db-uri")
+	vhostsCmd.Flags().BoolVar(&vhostsCmdFlags.DiffersOnly, "differs-only", false, "Only list virtual hosts whose response differs from their IP's")
+}
+
+func renderVHostsTable(comparisons []*vhosts.Comparison, differsOnly bool) {
+	PaddedStyle := lipgloss.NewStyle().PaddingLeft(1).PaddingRight(1)
+	HeaderStyle := PaddedStyle.Bold(true).Underline(true)
+	BaselineStyle := PaddedStyle.Faint(true)
+	RowStyle := PaddedStyle
+
+	var baselines = make(map[int]bool)
+	t := table.New().
+		Border(lipgloss.RoundedBorder()).
+		BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("99"))).
+		Headers(
+			"IP", "Port", "Virtual Host", "Code", "Title", "~Size",
+			"Group", "Differs",
+		).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			switch {
+			case row == table.HeaderRow:
+				return HeaderStyle
+			case baselines[row]:
+				return BaselineStyle
+			default:
+				return RowStyle
+			}
+		})
+
+	rows := 0
+	row := func(c *vhosts.Comparison, host string, result *models.Result, differs string) {
+		t.Row(
+			c.IP,
+			fmt.Sprintf("%s/%d", c.Scheme, c.Port),
+			urlStyle(host),
+			statusCode(result.ResponseCode),
+			titleStyle(result.Title),
+			fmt.Sprintf("%dkb", result.ContentLength/1024),
+			fmt.Sprintf("%d", result.PerceptionHashGroupId),
+			differs,
+		)
+		rows++
+	}
+
+	for _, c := range comparisons {
+		if c.Baseline != nil {
+			baselines[rows] = true
+			row(c, "(baseline)", c.Baseline, "")
+		}
+
+		for _, result := range c.Hosts {
+			differs := c.Differs(result)
+			if differsOnly && !differs {
+				continue
+			}
+
+			row(c, result.VHost, result, differsStyle(differs))
+		}
+	}
+
+	w, _, _ := term.GetSize(os.Stdout.Fd())
+	fmt.Println(lipgloss.NewStyle().MaxWidth(w).Render(t.String()))
+}
+
+func differsStyle(differs bool) string {
+	if !differs {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("7")).Render("no")
+	}
+
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("42")).Render("yes")
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/sensepost/gowitness/internal/ascii"
+	"github.com/sensepost/gowitness/internal/islazy"
+	"github.com/sensepost/gowitness/pkg/log"
+	"github.com/sensepost/gowitness/pkg/readers"
+	"github.com/spf13/cobra"
+)
+
+var burpCmdOptions = &readers.BurpReaderOptions{}
+var burpCmd = &cobra.Command{
+	Use:   "burp",
+	Short: "Scan targets from a Burp Suite XML export",
+	Long: ascii.LogoHelp(ascii.Markdown(`
+# scan burp
+
+Scan targets from a Burp Suite XML export.
+
+In Burp, select the proxy history items or site map branches to scan, and use
+"Save items" (or "Save selected items" in the site map) to write them to an
+XML file. Base64 encoding the requests and responses is fine, as only the URL,
+method, status and MIME type of each item are used.
+
+Each unique URL is scanned once, or with --unique-paths, each unique path,
+ignoring the query string. Results record the method the URL was originally
+requested with, though the browser always loads targets with GET.
+
+Use --host, --status and --mime-type to limit targets to the interesting parts
+of a project, like --mime-type html to leave out scripts and images.
+
+**Note**: By default, no metadata is saved except for screenshots that are
+stored in the configured --screenshot-path. For later parsing (i.e., using the
+gowitness reporting feature), you need to specify where to write results (db,
+csv, jsonl) using the _--write-*_ set of flags. See _--help_ for available
+flags.`)),
+	Example: ascii.Markdown(`
+- gowitness scan burp -f items.xml --write-db
+- gowitness scan burp -f sitemap.xml --host example.com --mime-type html
+- gowitness scan burp -f items.xml --status 200 --status 401 --unique-paths`),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if burpCmdOptions.Source == "" {
+			return errors.New("a source must be specified")
+		}
+
+		if burpCmdOptions.Source != "-" && !islazy.FileExists(burpCmdOptions.Source) {
+			return errors.New("source is not readable")
+		}
+
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Debug("starting Burp file scanning", "file", burpCmdOptions.Source)
+
+		reader := readers.NewBurpReader(burpCmdOptions)
+		go func() {
+			if err := reader.Read(scanRunner.Targets); err != nil {
+				log.Error("error in reader.Read", "err", err)
+				return
+			}
+		}()
+
+		scanRunner.Run()
+		scanRunner.Close()
+	},
+}
+
+func init() {
+	scanCmd.AddCommand(burpCmd)
+
+	burpCmd.Flags().StringVarP(&burpCmdOptions.Source, "file", "f", "", "A Burp XML export with targets to scan. Use - for stdin")
+	burpCmd.Flags().StringSliceVar(&burpCmdOptions.Hosts, "host", []string{}, "Only scan these hosts and their subdomains. Supports multiple --host flags")
+	burpCmd.Flags().IntSliceVar(&burpCmdOptions.Statuses, "status", []int{}, "Only scan URLs that responded with these statuses. Supports multiple --status flags")
+	burpCmd.Flags().StringSliceVar(&burpCmdOptions.MIMETypes, "mime-type", []string{}, "Only scan URLs with a MIME type containing one of these, like html. Supports multiple --mime-type flags")
+	burpCmd.Flags().BoolVar(&burpCmdOptions.UniquePaths, "unique-paths", false, "Scan each unique path once, ignoring query strings, instead of each unique URL")
+}
@@ -24,10 +24,15 @@ configuration options to generate permutations for scanning web services to scre
 URL schemes are automatically added as 'http://' and 'https://' unless either
 the --no-http or --no-https flags are present.
 
+CIDRs given with --cidr and read from a --cidr-file are scanned together. Both
+IPv4 and IPv6 ranges are supported, up to 2^32 addresses each (all of IPv4, or
+an IPv6 /96). Targets are generated as they are scanned, so even very large
+ranges use little memory.
+
 By default, this command will scan targets sequentially. If the --random flag is
-set, targets will go through a shuffling phase before scanning starts. This is
-useful in cases where scanning too many ports in sequence may trigger port
-scanning-related alerts.
+set, targets are scanned in a pseudo random order instead, still without
+holding them all in memory. This is useful in cases where scanning too many
+ports in sequence may trigger port scanning-related alerts.
 
 **Note**: By default, no metadata is saved except for screenshots that are
 stored in the configured --screenshot-path. For later parsing (i.e., using the
@@ -38,7 +43,8 @@ flags.`)),
 - gowitness scan cidr --cidr 192.168.0.0/24 --cidr 10.0.50.0/24
 - gowitness scan cidr -c 10.0.50.0/24 --port 8888 --port 8443
 - gowitness scan cidr -c 172.16.1.0/24 -c 10.10.10.0/24 --no-http --ports-medium
-- gowitness scan cidr -t 20 --log-scan-errors -c 10.20.20.0/28`),
+- gowitness scan cidr -t 20 --log-scan-errors -c 10.20.20.0/28
+- gowitness scan cidr -c 2001:db8::/120 --port 8080`),
 	PreRunE: func(cmd *cobra.Command, args []string) error {
 		if cidrCmdOptions.Source == "" && len(cidrCmdOptions.Cidrs) == 0 {
 			return errors.New("need targets to scan via either a --cidr-file or --cidr")
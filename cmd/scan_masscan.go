@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/sensepost/gowitness/internal/ascii"
+	"github.com/sensepost/gowitness/internal/islazy"
+	"github.com/sensepost/gowitness/pkg/log"
+	"github.com/sensepost/gowitness/pkg/readers"
+	"github.com/spf13/cobra"
+)
+
+var masscanCmdOptions = &readers.MasscanReaderOptions{}
+var masscanCmd = &cobra.Command{
+	Use:   "masscan",
+	Short: "Scan targets from a masscan output file",
+	Long: ascii.LogoHelp(ascii.Markdown(`
+# scan masscan
+
+Scan targets from a masscan output file.
+
+masscan's XML (-oX), JSON (-oJ or -oD) and list (-oL) output formats are all
+supported, and the format is detected from the file's contents. Only open TCP
+ports are scanned, and ports masscan reports more than once (like when grabbing
+banners) are only scanned once.
+
+masscan does not identify services, so use --port to limit targets to the
+ports web services are likely to be on, or --exclude-port to leave out ports
+that are not, like SSH.
+
+**Note**: By default, no metadata is saved except for screenshots that are
+stored in the configured --screenshot-path. For later parsing (i.e., using the
+gowitness reporting feature), you need to specify where to write results (db,
+csv, jsonl) using the _--write-*_ set of flags. See _--help_ for available
+flags.`)),
+	Example: ascii.Markdown(`
+- gowitness scan masscan -f masscan.xml --write-db
+- gowitness scan masscan -f masscan.json --exclude-port 22 --exclude-port 3389
+- gowitness scan masscan -f masscan.txt --port 80 --port 443 --port 8080
+- masscan 10.0.0.0/8 -p80,443 -oL - | gowitness scan masscan -f -`),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if masscanCmdOptions.Source == "" {
+			return errors.New("a source must be specified")
+		}
+
+		if masscanCmdOptions.Source != "-" && !islazy.FileExists(masscanCmdOptions.Source) {
+			return errors.New("source is not readable")
+		}
+
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Debug("starting masscan file scanning", "file", masscanCmdOptions.Source)
+
+		reader := readers.NewMasscanReader(masscanCmdOptions)
+		go func() {
+			if err := reader.Read(scanRunner.Targets); err != nil {
+				log.Error("error in reader.Read", "err", err)
+				return
+			}
+		}()
+
+		scanRunner.Run()
+		scanRunner.Close()
+	},
+}
+
+func init() {
+	scanCmd.AddCommand(masscanCmd)
+
+	masscanCmd.Flags().StringVarP(&masscanCmdOptions.Source, "file", "f", "", "A masscan output file with targets to scan. Use - for stdin")
+	masscanCmd.Flags().BoolVar(&masscanCmdOptions.NoHTTP, "no-http", false, "Do not add 'http://' to targets where missing")
+	masscanCmd.Flags().BoolVar(&masscanCmdOptions.NoHTTPS, "no-https", false, "Do not add 'https://' to targets where missing")
+	masscanCmd.Flags().IntSliceVar(&masscanCmdOptions.Ports, "port", []int{}, "A port filter to apply. Supports multiple --port flags")
+	masscanCmd.Flags().IntSliceVar(&masscanCmdOptions.ExcludePorts, "exclude-port", []int{}, "Do not scan these ports. Supports multiple --exclude-port flags")
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/sensepost/gowitness/internal/ascii"
+	"github.com/sensepost/gowitness/internal/islazy"
+	"github.com/sensepost/gowitness/pkg/log"
+	"github.com/sensepost/gowitness/pkg/readers"
+	"github.com/spf13/cobra"
+)
+
+var naabuCmdOptions = &readers.NaabuReaderOptions{}
+var naabuCmd = &cobra.Command{
+	Use:   "naabu",
+	Short: "Scan targets from a naabu output file",
+	Long: ascii.LogoHelp(ascii.Markdown(`
+# scan naabu
+
+Scan targets from a naabu output file.
+
+naabu's JSON lines output (-json) and its default host:port lines are both
+supported. When naabu scanned hostnames, the --hostnames flag adds them as
+targets too, which is useful for virtual hosting.
+
+Use --port to limit targets to the ports web services are likely to be on, or
+--exclude-port to leave out ports that are not, like SSH.
+
+**Note**: By default, no metadata is saved except for screenshots that are
+stored in the configured --screenshot-path. For later parsing (i.e., using the
+gowitness reporting feature), you need to specify where to write results (db,
+csv, jsonl) using the _--write-*_ set of flags. See _--help_ for available
+flags.`)),
+	Example: ascii.Markdown(`
+- gowitness scan naabu -f naabu.jsonl --write-db
+- gowitness scan naabu -f naabu.jsonl --hostnames --exclude-port 22
+- naabu -host example.com -json | gowitness scan naabu -f - --hostnames`),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if naabuCmdOptions.Source == "" {
+			return errors.New("a source must be specified")
+		}
+
+		if naabuCmdOptions.Source != "-" && !islazy.FileExists(naabuCmdOptions.Source) {
+			return errors.New("source is not readable")
+		}
+
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Debug("starting naabu file scanning", "file", naabuCmdOptions.Source)
+
+		reader := readers.NewNaabuReader(naabuCmdOptions)
+		go func() {
+			if err := reader.Read(scanRunner.Targets); err != nil {
+				log.Error("error in reader.Read", "err", err)
+				return
+			}
+		}()
+
+		scanRunner.Run()
+		scanRunner.Close()
+	},
+}
+
+func init() {
+	scanCmd.AddCommand(naabuCmd)
+
+	naabuCmd.Flags().StringVarP(&naabuCmdOptions.Source, "file", "f", "", "A naabu output file with targets to scan. Use - for stdin")
+	naabuCmd.Flags().BoolVar(&naabuCmdOptions.NoHTTP, "no-http", false, "Do not add 'http://' to targets where missing")
+	naabuCmd.Flags().BoolVar(&naabuCmdOptions.NoHTTPS, "no-https", false, "Do not add 'https://' to targets where missing")
+	naabuCmd.Flags().IntSliceVar(&naabuCmdOptions.Ports, "port", []int{}, "A port filter to apply. Supports multiple --port flags")
+	naabuCmd.Flags().IntSliceVar(&naabuCmdOptions.ExcludePorts, "exclude-port", []int{}, "Do not scan these ports. Supports multiple --exclude-port flags")
+	naabuCmd.Flags().BoolVar(&naabuCmdOptions.Hostnames, "hostnames", false, "Add hostnames in URL candidates (useful for virtual hosting)")
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/sensepost/gowitness/internal/ascii"
+	"github.com/sensepost/gowitness/internal/islazy"
+	"github.com/sensepost/gowitness/pkg/log"
+	"github.com/sensepost/gowitness/pkg/readers"
+	"github.com/spf13/cobra"
+)
+
+var projectDiscoveryCmdOptions = &readers.ProjectDiscoveryReaderOptions{}
+var projectDiscoveryCmd = &cobra.Command{
+	Use:     "projectdiscovery",
+	Aliases: []string{"pd", "httpx", "nuclei", "subfinder", "dnsx"},
+	Short:   "Scan targets from httpx, nuclei, subfinder or dnsx JSON lines output",
+	Long: ascii.LogoHelp(ascii.Markdown(`
+# scan projectdiscovery
+
+Scan targets from httpx, nuclei, subfinder or dnsx JSON lines output.
+
+Run the tools with -json (or -jsonl) to write output gowitness can parse. Which
+tool wrote a line is detected from its fields, so output from several tools can
+be concatenated into one file.
+
+- httpx: the probed URL is scanned as is.
+- nuclei: the URL a template matched at is scanned. For network, DNS and SSL
+  templates, the matched host and port are scanned over HTTP and HTTPS.
+- subfinder and dnsx: the hostnames found are scanned over HTTP and HTTPS.
+
+With --tags, httpx statuses (like status:200) and nuclei template ids and
+severities (like nuclei:exposed-panel and severity:medium) are added as tags to
+results, so that they can be searched for and filtered on in reports.
+
+**Note**: By default, no metadata is saved except for screenshots that are
+stored in the configured --screenshot-path. For later parsing (i.e., using the
+gowitness reporting feature), you need to specify where to write results (db,
+csv, jsonl) using the _--write-*_ set of flags. See _--help_ for available
+flags.`)),
+	Example: ascii.Markdown(`
+- gowitness scan httpx -f httpx.jsonl --tags --write-db
+- gowitness scan nuclei -f nuclei.jsonl --tags --write-db
+- subfinder -d example.com -json | gowitness scan subfinder -f -`),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if projectDiscoveryCmdOptions.Source == "" {
+			return errors.New("a source must be specified")
+		}
+
+		if projectDiscoveryCmdOptions.Source != "-" && !islazy.FileExists(projectDiscoveryCmdOptions.Source) {
+			return errors.New("source is not readable")
+		}
+
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Debug("starting ProjectDiscovery file scanning", "file", projectDiscoveryCmdOptions.Source)
+
+		reader := readers.NewProjectDiscoveryReader(projectDiscoveryCmdOptions)
+		go func() {
+			if err := reader.Read(scanRunner.Targets); err != nil {
+				log.Error("error in reader.Read", "err", err)
+				return
+			}
+		}()
+
+		scanRunner.Run()
+		scanRunner.Close()
+	},
+}
+
+func init() {
+	scanCmd.AddCommand(projectDiscoveryCmd)
+
+	projectDiscoveryCmd.Flags().StringVarP(&projectDiscoveryCmdOptions.Source, "file", "f", "", "A JSON lines file with targets to scan. Use - for stdin")
+	projectDiscoveryCmd.Flags().BoolVar(&projectDiscoveryCmdOptions.NoHTTP, "no-http", false, "Do not add 'http://' to hosts and host:port pairs. httpx and nuclei URLs are scanned as is")
+	projectDiscoveryCmd.Flags().BoolVar(&projectDiscoveryCmdOptions.NoHTTPS, "no-https", false, "Do not add 'https://' to hosts and host:port pairs. httpx and nuclei URLs are scanned as is")
+	projectDiscoveryCmd.Flags().BoolVar(&projectDiscoveryCmdOptions.Tags, "tags", false, "Add httpx statuses and nuclei template ids and severities to results as tags")
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	"github.com/sensepost/gowitness/internal/ascii"
+	"github.com/sensepost/gowitness/pkg/readers"
 	"github.com/spf13/cobra"
 )
 
@@ -38,7 +39,7 @@ flags.`)),
 		url, _ := cmd.Flags().GetString("url")
 
 		go func() {
-			scanRunner.Targets <- url
+			scanRunner.Targets <- &readers.Target{URL: url}
 			close(scanRunner.Targets)
 		}()
 
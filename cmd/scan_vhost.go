@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/sensepost/gowitness/internal/ascii"
+	"github.com/sensepost/gowitness/pkg/log"
+	"github.com/sensepost/gowitness/pkg/readers"
+	"github.com/spf13/cobra"
+)
+
+var vhostCmdOptions = &readers.VHostReaderOptions{}
+var vhostCmd = &cobra.Command{
+	Use:   "vhost",
+	Short: "Scan virtual hosts, requesting hostnames from given IPs",
+	Long: ascii.LogoHelp(ascii.Markdown(`
+# scan vhost
+
+Scan virtual hosts, requesting hostnames from given IPs.
+
+Every hostname is requested from every IP (or CIDR) and port, no matter what
+DNS says about the hostname. This finds hosts configured on a shared web
+server, or on a load balancer that is not in DNS (yet).
+
+Unless --no-baseline is set, each IP is also requested without a hostname.
+That response is what the virtual hosts are compared to, so that hosts that
+the server does not know about (and so get the default site) can be told
+apart using the _gowitness report vhosts_ command.
+
+**Note**: Virtual host requests connect to the IP directly, so they can not
+be sent through --chrome-proxy, or a proxy pool or rule.`)),
+	Example: ascii.Markdown(`
+- gowitness scan vhost -i 10.0.0.1 -n app.internal -n intranet.internal --write-db
+- gowitness scan vhost --ip-file ips.txt --hostname-file hostnames.txt --port 8443
+- gowitness scan vhost -i 192.168.0.0/28 -n portal.example.com --no-http`),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if len(vhostCmdOptions.IPs) == 0 && vhostCmdOptions.IPsFile == "" {
+			return errors.New("at least one ip must be specified")
+		}
+
+		if len(vhostCmdOptions.Hostnames) == 0 && vhostCmdOptions.HostnamesFile == "" {
+			return errors.New("at least one hostname must be specified")
+		}
+
+		if opts.Chrome.Proxy != "" || len(opts.Chrome.Proxies) > 0 || len(opts.Chrome.ProxyRules) > 0 {
+			return errors.New("virtual hosts can not be scanned through a proxy")
+		}
+
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Debug("starting virtual host scanning")
+
+		reader := readers.NewVHostReader(vhostCmdOptions)
+		go func() {
+			if err := reader.Read(scanRunner.Targets); err != nil {
+				log.Error("error in reader.Read", "err", err)
+				return
+			}
+		}()
+
+		scanRunner.Run()
+		scanRunner.Close()
+	},
+}
+
+func init() {
+	scanCmd.AddCommand(vhostCmd)
+
+	vhostCmd.Flags().StringSliceVarP(&vhostCmdOptions.IPs, "ip", "i", []string{}, "An IP or CIDR to request the hostnames from. Supports multiple --ip flags")
+	vhostCmd.Flags().StringVar(&vhostCmdOptions.IPsFile, "ip-file", "", "A file with IPs or CIDRs to request the hostnames from, one per line")
+	vhostCmd.Flags().StringSliceVarP(&vhostCmdOptions.Hostnames, "hostname", "n", []string{}, "A hostname to request from each IP. Supports multiple --hostname flags")
+	vhostCmd.Flags().StringVar(&vhostCmdOptions.HostnamesFile, "hostname-file", "", "A file with hostnames to request from each IP, one per line")
+	vhostCmd.Flags().IntSliceVar(&vhostCmdOptions.Ports, "port", []int{}, "A port to request the hostnames on. Supports multiple --port flags. Defaults to each scheme's default port")
+	vhostCmd.Flags().BoolVar(&vhostCmdOptions.NoHTTP, "no-http", false, "Do not request hostnames over HTTP")
+	vhostCmd.Flags().BoolVar(&vhostCmdOptions.NoHTTPS, "no-https", false, "Do not request hostnames over HTTPS")
+	vhostCmd.Flags().BoolVar(&vhostCmdOptions.NoBaseline, "no-baseline", false, "Do not request each IP without a hostname to compare the virtual hosts to")
+}
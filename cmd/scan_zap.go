@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/sensepost/gowitness/internal/ascii"
+	"github.com/sensepost/gowitness/internal/islazy"
+	"github.com/sensepost/gowitness/pkg/log"
+	"github.com/sensepost/gowitness/pkg/readers"
+	"github.com/spf13/cobra"
+)
+
+var zapCmdOptions = &readers.ZapReaderOptions{}
+var zapCmd = &cobra.Command{
+	Use:   "zap",
+	Short: "Scan targets from an OWASP ZAP report",
+	Long: ascii.LogoHelp(ascii.Markdown(`
+# scan zap
+
+Scan targets from an OWASP ZAP report.
+
+ZAP's Traditional XML and JSON reports are supported, as are their "plus"
+variants. Targets are the sites in the report and the URLs of each alert
+instance. Only the plus variants include response headers, so the --status and
+--mime-type filters leave out every alert instance of the other reports.
+
+Each unique URL is scanned once, or with --unique-paths, each unique path,
+ignoring the query string. Results record the method the URL was originally
+requested with, though the browser always loads targets with GET.
+
+**Note**: By default, no metadata is saved except for screenshots that are
+stored in the configured --screenshot-path. For later parsing (i.e., using the
+gowitness reporting feature), you need to specify where to write results (db,
+csv, jsonl) using the _--write-*_ set of flags. See _--help_ for available
+flags.`)),
+	Example: ascii.Markdown(`
+- gowitness scan zap -f report.xml --write-db
+- gowitness scan zap -f report.json --host example.com --unique-paths
+- gowitness scan zap -f report-plus.json --mime-type text/html`),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if zapCmdOptions.Source == "" {
+			return errors.New("a source must be specified")
+		}
+
+		if zapCmdOptions.Source != "-" && !islazy.FileExists(zapCmdOptions.Source) {
+			return errors.New("source is not readable")
+		}
+
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Debug("starting ZAP file scanning", "file", zapCmdOptions.Source)
+
+		reader := readers.NewZapReader(zapCmdOptions)
+		go func() {
+			if err := reader.Read(scanRunner.Targets); err != nil {
+				log.Error("error in reader.Read", "err", err)
+				return
+			}
+		}()
+
+		scanRunner.Run()
+		scanRunner.Close()
+	},
+}
+
+func init() {
+	scanCmd.AddCommand(zapCmd)
+
+	zapCmd.Flags().StringVarP(&zapCmdOptions.Source, "file", "f", "", "A ZAP XML or JSON report with targets to scan. Use - for stdin")
+	zapCmd.Flags().StringSliceVar(&zapCmdOptions.Hosts, "host", []string{}, "Only scan these hosts and their subdomains. Supports multiple --host flags")
+	zapCmd.Flags().IntSliceVar(&zapCmdOptions.Statuses, "status", []int{}, "Only scan URLs that responded with these statuses. Supports multiple --status flags")
+	zapCmd.Flags().StringSliceVar(&zapCmdOptions.MIMETypes, "mime-type", []string{}, "Only scan URLs with a MIME type containing one of these, like html. Supports multiple --mime-type flags")
+	zapCmd.Flags().BoolVar(&zapCmdOptions.UniquePaths, "unique-paths", false, "Scan each unique path once, ignoring query strings, instead of each unique URL")
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/sensepost/gowitness/internal/ascii"
+	"github.com/sensepost/gowitness/internal/islazy"
+	"github.com/sensepost/gowitness/pkg/log"
+	"github.com/sensepost/gowitness/pkg/readers"
+	"github.com/spf13/cobra"
+)
+
+var zmapCmdOptions = &readers.ZmapReaderOptions{}
+var zmapCmd = &cobra.Command{
+	Use:   "zmap",
+	Short: "Scan targets from a ZMap output file",
+	Long: ascii.LogoHelp(ascii.Markdown(`
+# scan zmap
+
+Scan targets from a ZMap output file.
+
+ZMap's CSV output (-O csv) is supported when it has a header with the saddr
+field. Add the sport field (-f saddr,sport,success) to know which port each
+address responded on, and the success field to skip addresses that did not
+respond with a SYN-ACK. ZMap's default output of one address per line has no
+ports, so give the port(s) ZMap scanned with --scanned-port.
+
+**Note**: By default, no metadata is saved except for screenshots that are
+stored in the configured --screenshot-path. For later parsing (i.e., using the
+gowitness reporting feature), you need to specify where to write results (db,
+csv, jsonl) using the _--write-*_ set of flags. See _--help_ for available
+flags.`)),
+	Example: ascii.Markdown(`
+- gowitness scan zmap -f zmap.csv --write-db
+- gowitness scan zmap -f addresses.txt --scanned-port 8080 --no-https
+- zmap -p 443 10.0.0.0/8 -o - | gowitness scan zmap -f - --scanned-port 443 --no-http`),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if zmapCmdOptions.Source == "" {
+			return errors.New("a source must be specified")
+		}
+
+		if zmapCmdOptions.Source != "-" && !islazy.FileExists(zmapCmdOptions.Source) {
+			return errors.New("source is not readable")
+		}
+
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Debug("starting ZMap file scanning", "file", zmapCmdOptions.Source)
+
+		reader := readers.NewZmapReader(zmapCmdOptions)
+		go func() {
+			if err := reader.Read(scanRunner.Targets); err != nil {
+				log.Error("error in reader.Read", "err", err)
+				return
+			}
+		}()
+
+		scanRunner.Run()
+		scanRunner.Close()
+	},
+}
+
+func init() {
+	scanCmd.AddCommand(zmapCmd)
+
+	zmapCmd.Flags().StringVarP(&zmapCmdOptions.Source, "file", "f", "", "A ZMap output file with targets to scan. Use - for stdin")
+	zmapCmd.Flags().BoolVar(&zmapCmdOptions.NoHTTP, "no-http", false, "Do not add 'http://' to targets where missing")
+	zmapCmd.Flags().BoolVar(&zmapCmdOptions.NoHTTPS, "no-https", false, "Do not add 'https://' to targets where missing")
+	zmapCmd.Flags().IntSliceVar(&zmapCmdOptions.ScannedPorts, "scanned-port", []int{}, "The port ZMap scanned, for output without the sport field. Supports multiple --scanned-port flags")
+	zmapCmd.Flags().IntSliceVar(&zmapCmdOptions.Ports, "port", []int{}, "A port filter to apply. Supports multiple --port flags")
+	zmapCmd.Flags().IntSliceVar(&zmapCmdOptions.ExcludePorts, "exclude-port", []int{}, "Do not scan these ports. Supports multiple --exclude-port flags")
+}
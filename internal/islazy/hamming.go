@@ -46,3 +46,41 @@ func ParsePerceptionHash(hashStr string) ([]byte, error) {
 
 	return bytes, nil
 }
+
+// HammingGroups assigns hashes to groups. A hash joins the first group
+// whose seed hash is within Threshold bits of it.
+type HammingGroups struct {
+	Threshold int
+	groups    []HammingGroup
+}
+
+// NewHammingGroups returns an empty set of groups
+func NewHammingGroups(threshold int) *HammingGroups {
+	return &HammingGroups{Threshold: threshold}
+}
+
+// Add adds a group, seeded with hash
+func (g *HammingGroups) Add(groupID uint, hash []byte) {
+	g.groups = append(g.groups, HammingGroup{GroupID: groupID, Hash: hash})
+}
+
+// Find returns the id of the group a hash belongs to, if any
+func (g *HammingGroups) Find(hash []byte) (uint, bool, error) {
+	for _, group := range g.groups {
+		dist, err := HammingDistance(hash, group.Hash)
+		if err != nil {
+			return 0, false, err
+		}
+
+		if dist <= g.Threshold {
+			return group.GroupID, true, nil
+		}
+	}
+
+	return 0, false, nil
+}
+
+// Len returns the number of groups
+func (g *HammingGroups) Len() int {
+	return len(g.groups)
+}
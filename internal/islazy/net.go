@@ -2,32 +2,117 @@ package islazy
 
 import (
 	"encoding/binary"
-	"net"
+	"fmt"
+	"iter"
+	"math/rand/v2"
+	"net/netip"
+	"strings"
 )
 
-// IpsInCIDR returns a list of usable IP addresses in a given CIDR block
-// excluding network and broadcast addresses for CIDRs larger than /31.
-func IpsInCIDR(cidr string) ([]string, error) {
-	_, ipnet, err := net.ParseCIDR(cidr)
+// MaxCIDRBits is the most host bits a CIDR may have, which caps it at 2^32
+// addresses. That is all of IPv4, or an IPv6 /96.
+const MaxCIDRBits = 32
+
+// CIDR is a range of IP addresses that can be walked without expanding it
+// in memory
+type CIDR struct {
+	prefix netip.Prefix
+}
+
+// ParseCIDR parses a CIDR like 10.0.0.0/8 or 2001:db8::/120. A single
+// address is a CIDR of one. CIDRs with more than MaxCIDRBits host bits are
+// refused.
+func ParseCIDR(cidr string) (CIDR, error) {
+	cidr = strings.TrimSpace(cidr)
+	if !strings.Contains(cidr, "/") {
+		addr, err := netip.ParseAddr(cidr)
+		if err != nil {
+			return CIDR{}, err
+		}
+		cidr = fmt.Sprintf("%s/%d", addr, addr.BitLen())
+	}
+
+	prefix, err := netip.ParsePrefix(cidr)
 	if err != nil {
-		return nil, err
+		return CIDR{}, err
 	}
+	prefix = prefix.Masked()
 
-	mask := binary.BigEndian.Uint32(ipnet.Mask)
-	start := binary.BigEndian.Uint32(ipnet.IP)
-	end := (start & mask) | (mask ^ 0xFFFFFFFF)
+	if hostBits := prefix.Addr().BitLen() - prefix.Bits(); hostBits > MaxCIDRBits {
+		return CIDR{}, fmt.Errorf("cidr %s is too large, the largest is a /%d", cidr, prefix.Addr().BitLen()-MaxCIDRBits)
+	}
 
-	var ips []string
-	ip := make(net.IP, 4) // Preallocate buffer
+	return CIDR{prefix: prefix}, nil
+}
+
+// Size is the number of addresses in the CIDR, including ones At reports
+// as unusable
+func (c CIDR) Size() uint64 {
+	return 1 << (c.prefix.Addr().BitLen() - c.prefix.Bits())
+}
 
-	// Iterate over the range of IPs
-	for i := start; i <= end; i++ {
-		// Exclude network and broadcast addresses in larger CIDR ranges
-		if !(i&0xFF == 255 || i&0xFF == 0) || ipnet.Mask[3] >= 30 {
-			binary.BigEndian.PutUint32(ip, i)
-			ips = append(ips, ip.String())
+// At returns the i'th address in the CIDR, and whether it is usable. For
+// IPv4, network and broadcast addresses (ending in .0 or .255) are not
+// usable in CIDRs larger than /30. For IPv6, the subnet-router anycast
+// address (the first one) is not usable in CIDRs larger than /127.
+func (c CIDR) At(i uint64) (netip.Addr, bool) {
+	base := c.prefix.Addr()
+
+	if base.Is4() {
+		b := base.As4()
+		n := binary.BigEndian.Uint32(b[:]) + uint32(i)
+		binary.BigEndian.PutUint32(b[:], n)
+
+		last := b[3]
+		return netip.AddrFrom4(b), c.prefix.Bits() >= 30 || (last != 0 && last != 255)
+	}
+
+	// host bits fit in the low 64 bits, which are zero in the masked base
+	b := base.As16()
+	binary.BigEndian.PutUint64(b[8:], binary.BigEndian.Uint64(b[8:])+i)
+
+	return netip.AddrFrom16(b), i > 0 || c.prefix.Bits() >= 127
+}
+
+// All returns the usable addresses in the CIDR, in order
+func (c CIDR) All() iter.Seq[netip.Addr] {
+	return func(yield func(netip.Addr) bool) {
+		for i := range c.Size() {
+			if addr, ok := c.At(i); ok && !yield(addr) {
+				return
+			}
 		}
 	}
+}
+
+// Permutation returns the integers in [0, n) once each, in a pseudo random
+// order, using constant memory. It walks a linear congruential generator
+// with a full period over the next power of two from n, skipping values
+// that are n or more, so it takes at most twice as many steps as n.
+func Permutation(n uint64) iter.Seq[uint64] {
+	return func(yield func(uint64) bool) {
+		if n == 0 {
+			return
+		}
+
+		// the modulus is a power of two, so that arithmetic can wrap
+		// around and be masked
+		mask := uint64(1)
+		for mask < n-1 {
+			mask = mask<<1 | 1
+		}
 
-	return ips, nil
+		// Hull-Dobell: with a power of two modulus, an odd increment and a
+		// multiplier that is 1 mod 4 give a full period
+		a := rand.Uint64()&^3 | 1
+		c := rand.Uint64() | 1
+		x := rand.Uint64() & mask
+
+		for range mask + 1 {
+			x = (a*x + c) & mask
+			if x < n && !yield(x) {
+				return
+			}
+		}
+	}
 }
@@ -0,0 +1,73 @@
+package islazy
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCIDR(t *testing.T) {
+	tests := []struct {
+		cidr  string
+		count int
+		first string
+		last  string
+	}{
+		{"192.168.1.0/24", 254, "192.168.1.1", "192.168.1.254"},
+		{"192.168.1.0/30", 4, "192.168.1.0", "192.168.1.3"},
+		{"192.168.1.7/32", 1, "192.168.1.7", "192.168.1.7"},
+		{"192.168.1.7", 1, "192.168.1.7", "192.168.1.7"},
+		{"2001:db8::/120", 255, "2001:db8::1", "2001:db8::ff"},
+		{"2001:db8::/127", 2, "2001:db8::", "2001:db8::1"},
+		{"2001:db8::1", 1, "2001:db8::1", "2001:db8::1"},
+	}
+
+	for _, tt := range tests {
+		cidr, err := ParseCIDR(tt.cidr)
+		if err != nil {
+			t.Fatalf("ParseCIDR(%q) error = %v", tt.cidr, err)
+		}
+
+		var ips []string
+		for addr := range cidr.All() {
+			ips = append(ips, addr.String())
+		}
+		if len(ips) != tt.count || ips[0] != tt.first || ips[len(ips)-1] != tt.last {
+			t.Errorf("%q.All() = %d ips from %s to %s, want %d from %s to %s",
+				tt.cidr, len(ips), ips[0], ips[len(ips)-1], tt.count, tt.first, tt.last)
+		}
+	}
+
+	for _, cidr := range []string{"2001:db8::/64", "not a cidr"} {
+		if _, err := ParseCIDR(cidr); err == nil {
+			t.Errorf("ParseCIDR(%q) error = nil, want an error", cidr)
+		}
+	}
+
+	// all of ipv4 is walkable, if slowly
+	all, err := ParseCIDR("0.0.0.0/0")
+	if err != nil {
+		t.Fatalf("ParseCIDR() error = %v", err)
+	}
+	if addr, _ := all.At(all.Size() - 1); addr.String() != "255.255.255.255" {
+		t.Errorf("At() = %s, want the last ipv4 address", addr)
+	}
+}
+
+func TestPermutation(t *testing.T) {
+	for _, n := range []uint64{0, 1, 2, 3, 100, 1025} {
+		var got []uint64
+		for i := range Permutation(n) {
+			got = append(got, i)
+		}
+
+		slices.Sort(got)
+		if uint64(len(got)) != n {
+			t.Fatalf("Permutation(%d) returned %d values", n, len(got))
+		}
+		for i, v := range got {
+			if v != uint64(i) {
+				t.Fatalf("Permutation(%d) = %v, want every value in [0, %d) once", n, got, n)
+			}
+		}
+	}
+}
@@ -1,11 +1,5 @@
 package islazy
 
-import (
-	"time"
-
-	"math/rand"
-)
-
 // SliceHasStr checks if a slice has a string
 func SliceHasStr(slice []string, item string) bool {
 	for _, s := range slice {
@@ -42,15 +36,3 @@ func UniqueIntSlice(slice []int) []int {
 
 	return result
 }
-
-// ShuffleStr shuffles a slice of strings
-func ShuffleStr(slice []string) {
-	source := rand.NewSource(time.Now().UnixNano())
-	rng := rand.New(source)
-
-	// Fisher-Yates shuffle algorithm
-	for i := len(slice) - 1; i > 0; i-- {
-		j := rng.Intn(i + 1)
-		slice[i], slice[j] = slice[j], slice[i]
-	}
-}
@@ -0,0 +1,33 @@
+// Package testdb opens throwaway databases for tests
+package testdb
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// Connect opens a database by URI, as database.Connection does. It is
+// passed in, rather than called from here, so that the database package's
+// own tests can use this package too.
+type Connect func(uri string, shouldExist, debug bool) (*gorm.DB, error)
+
+// Open returns the URI of, and a connection to, a new SQLite database in a
+// temporary directory. The connection is closed when the test ends.
+func Open(t testing.TB, connect Connect) (string, *gorm.DB) {
+	t.Helper()
+
+	uri := "sqlite://" + filepath.Join(t.TempDir(), "gowitness.sqlite3")
+	conn, err := connect(uri, false, false)
+	if err != nil {
+		t.Fatalf("could not open a test database: %v", err)
+	}
+	t.Cleanup(func() {
+		if db, err := conn.DB(); err == nil {
+			db.Close()
+		}
+	})
+
+	return uri, conn
+}
@@ -0,0 +1,175 @@
+package alerts
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/sensepost/gowitness/pkg/events"
+	"github.com/sensepost/gowitness/pkg/log"
+	"github.com/sensepost/gowitness/pkg/models"
+	"github.com/sensepost/gowitness/pkg/search"
+	"gorm.io/gorm"
+)
+
+// Alert rule types
+const (
+	// WebhookAlert POSTs the notification as JSON to the rule's target URL
+	WebhookAlert = "webhook"
+	// FileAlert appends the notification as a JSON line to the rule's
+	// target file
+	FileAlert = "file"
+	// EventsAlert publishes the notification on the events stream
+	EventsAlert = "events"
+)
+
+// Types are the supported alert rule types
+var Types = []string{WebhookAlert, FileAlert, EventsAlert}
+
+// Alert is an alert rule whose saved search matched a result
+type Alert struct {
+	Search *models.SavedSearch
+	Rule   *models.AlertRule
+}
+
+// Notification is the message an alert rule emits
+type Notification struct {
+	Text   string         `json:"text"`
+	Search *SearchSummary `json:"search"`
+	Result *events.Result `json:"result"`
+}
+
+// SearchSummary describes the saved search a notification is for
+type SearchSummary struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+// Policy limits where alert rules may send notifications, as anyone who
+// can reach the API can create a rule. The zero Policy only allows events
+// alerts.
+type Policy struct {
+	// Dir is the directory file alerts are appended in, with targets being
+	// paths inside it. Empty disables file alerts
+	Dir string
+	// WebhookHosts are the hosts (or host:port) webhooks may be sent to.
+	// Empty disables webhook alerts
+	WebhookHosts []string
+}
+
+// ValidateRule checks that an alert rule has a known type and a target,
+// where one is needed, that the policy allows
+func ValidateRule(rule *models.AlertRule, policy Policy) error {
+	if !slices.Contains(Types, rule.Type) {
+		return fmt.Errorf("unknown alert type %q", rule.Type)
+	}
+
+	if rule.Type != EventsAlert && rule.Target == "" {
+		return fmt.Errorf("a target is required for %s alerts", rule.Type)
+	}
+
+	switch rule.Type {
+	case FileAlert:
+		return policy.checkFile(rule.Target)
+	case WebhookAlert:
+		return policy.checkWebhook(rule.Target)
+	}
+
+	return nil
+}
+
+// checkFile checks that a file target is a path inside the alerts
+// directory
+func (p Policy) checkFile(target string) error {
+	if p.Dir == "" {
+		return errors.New("file alerts are disabled, as no alerts directory is configured")
+	}
+	if !filepath.IsLocal(target) {
+		return fmt.Errorf("file alert target %q must be a relative path inside the alerts directory", target)
+	}
+
+	return nil
+}
+
+// checkWebhook checks that a webhook target is an http(s) URL on an
+// allowed host
+func (p Policy) checkWebhook(target string) error {
+	if len(p.WebhookHosts) == 0 {
+		return errors.New("webhook alerts are disabled, as no webhook hosts are allowed")
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		return fmt.Errorf("invalid webhook url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("webhook url %q must be http or https", target)
+	}
+	if u.User != nil {
+		return fmt.Errorf("webhook url %q may not have credentials", target)
+	}
+
+	for _, allowed := range p.WebhookHosts {
+		allowed = strings.ToLower(allowed)
+		if allowed == strings.ToLower(u.Host) || allowed == strings.ToLower(u.Hostname()) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("webhook host %s is not allowed", u.Host)
+}
+
+// Match returns the enabled alert rules whose saved search matches a
+// result. The result must already be written to the database.
+func Match(db *gorm.DB, result *models.Result) ([]*Alert, error) {
+	var searches []*models.SavedSearch
+	if err := db.Model(&models.SavedSearch{}).
+		Preload("Alerts", "enabled = ?", true).
+		Where("id in (?)", db.Model(&models.AlertRule{}).
+			Select("saved_search_id").Distinct("saved_search_id").
+			Where("enabled = ?", true)).
+		Find(&searches).Error; err != nil {
+		return nil, err
+	}
+
+	var alerts []*Alert
+	for _, saved := range searches {
+		query, err := search.Parse(saved.Query)
+		if err != nil || query == nil {
+			log.Warn("skipping saved search with an invalid query", "search", saved.Name, "err", err)
+			continue
+		}
+
+		var count int64
+		if err := search.Apply(db.Model(&models.Result{}).Where("id = ?", result.ID), query).
+			Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			continue
+		}
+
+		for i := range saved.Alerts {
+			alerts = append(alerts, &Alert{Search: saved, Rule: &saved.Alerts[i]})
+		}
+	}
+
+	return alerts, nil
+}
+
+// NewNotification returns the notification for an alert on a result
+func NewNotification(alert *Alert, result *models.Result) *Notification {
+	return &Notification{
+		Text: fmt.Sprintf("gowitness: %s matched saved search %q", result.URL, alert.Search.Name),
+		Search: &SearchSummary{
+			ID:    alert.Search.ID,
+			Name:  alert.Search.Name,
+			Query: alert.Search.Query,
+		},
+		Result: events.NewResult(result),
+	}
+}
@@ -0,0 +1,86 @@
+package alerts
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sensepost/gowitness/pkg/models"
+)
+
+func TestValidateRule(t *testing.T) {
+	policy := Policy{Dir: t.TempDir(), WebhookHosts: []string{"hooks.example.com", "10.0.0.5:8080"}}
+
+	tests := []struct {
+		rule    models.AlertRule
+		policy  Policy
+		wantErr bool
+	}{
+		{models.AlertRule{Type: EventsAlert}, Policy{}, false},
+		{models.AlertRule{Type: FileAlert, Target: "admin-panels.jsonl"}, policy, false},
+		{models.AlertRule{Type: FileAlert, Target: "team/alerts.jsonl"}, policy, false},
+		{models.AlertRule{Type: FileAlert, Target: "/etc/cron.d/gowitness"}, policy, true},
+		{models.AlertRule{Type: FileAlert, Target: "../outside.jsonl"}, policy, true},
+		{models.AlertRule{Type: FileAlert, Target: "alerts.jsonl"}, Policy{}, true},
+		{models.AlertRule{Type: WebhookAlert, Target: "https://hooks.example.com/T0/B0"}, policy, false},
+		{models.AlertRule{Type: WebhookAlert, Target: "http://10.0.0.5:8080/alert"}, policy, false},
+		{models.AlertRule{Type: WebhookAlert, Target: "http://10.0.0.5/alert"}, policy, true},
+		{models.AlertRule{Type: WebhookAlert, Target: "http://169.254.169.254/latest/meta-data"}, policy, true},
+		{models.AlertRule{Type: WebhookAlert, Target: "file:///etc/passwd"}, policy, true},
+		{models.AlertRule{Type: WebhookAlert, Target: "https://hooks.example.com/"}, Policy{}, true},
+	}
+	for _, tt := range tests {
+		if err := ValidateRule(&tt.rule, tt.policy); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateRule(%s %q) error = %v, wantErr %v", tt.rule.Type, tt.rule.Target, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNotifyFile(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+
+	// a symlink inside the alerts directory may not lead outside of it
+	if err := os.Symlink(outside, filepath.Join(dir, "escape")); err != nil {
+		t.Skipf("could not create a symlink: %v", err)
+	}
+
+	notification := &Notification{Text: "matched"}
+	if err := notifyFile(dir, "team/alerts.jsonl", notification); err != nil {
+		t.Fatalf("notifyFile() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "team", "alerts.jsonl")); err != nil {
+		t.Errorf("notifyFile() did not write the alert: %v", err)
+	}
+
+	if err := notifyFile(dir, "escape/alerts.jsonl", notification); err == nil {
+		t.Errorf("notifyFile() followed a symlink out of the alerts directory")
+	}
+}
+
+func TestNotifyWebhookCancel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := notifyWebhook(ctx, server.URL, &Notification{Text: "matched"}); err == nil {
+		t.Errorf("notifyWebhook() sent a webhook after it was cancelled")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("notifyWebhook() took %s to give up after it was cancelled", elapsed)
+	}
+}
@@ -0,0 +1,133 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sensepost/gowitness/pkg/events"
+	"github.com/sensepost/gowitness/pkg/log"
+	"github.com/sensepost/gowitness/pkg/models"
+	"gorm.io/gorm"
+)
+
+// webhookClient does not follow redirects, as they could lead to a host
+// the policy does not allow
+var webhookClient = &http.Client{
+	Timeout: 10 * time.Second,
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// fileMutex serialises appends to alert files
+var fileMutex sync.Mutex
+
+// Evaluate matches a newly written result against the saved searches
+// and notifies every matching alert rule the policy allows. Failures are
+// logged, as alerts should never stop results from being written.
+// Cancelling ctx aborts webhooks that are still being sent.
+func Evaluate(ctx context.Context, db *gorm.DB, result *models.Result, hub *events.Hub, policy Policy) {
+	alerts, err := Match(db, result)
+	if err != nil {
+		log.Error("could not match result against saved searches", "err", err)
+		return
+	}
+
+	for _, alert := range alerts {
+		if err := Notify(ctx, alert, result, hub, policy); err != nil {
+			log.Error("could not send alert", "search", alert.Search.Name, "type", alert.Rule.Type, "err", err)
+		}
+	}
+}
+
+// Notify sends the notification for an alert. Events alerts are dropped
+// when there is no hub to publish them to. Rules are checked against the
+// policy again, as they may have been saved under another one.
+func Notify(ctx context.Context, alert *Alert, result *models.Result, hub *events.Hub, policy Policy) error {
+	if err := ValidateRule(alert.Rule, policy); err != nil {
+		return err
+	}
+
+	notification := NewNotification(alert, result)
+	log.Debug("sending alert", "search", alert.Search.Name, "type", alert.Rule.Type, "url", result.URL)
+
+	switch alert.Rule.Type {
+	case WebhookAlert:
+		return notifyWebhook(ctx, alert.Rule.Target, notification)
+	case FileAlert:
+		return notifyFile(policy.Dir, alert.Rule.Target, notification)
+	case EventsAlert:
+		if hub != nil {
+			hub.Publish(events.AlertEvent, notification)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown alert type %q", alert.Rule.Type)
+}
+
+func notifyWebhook(ctx context.Context, url string, notification *Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// notifyFile appends a notification to a file inside dir. The file is
+// opened through an os.Root, so that symlinks can not lead outside of dir
+func notifyFile(dir string, path string, notification *Notification) error {
+	line, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	fileMutex.Lock()
+	defer fileMutex.Unlock()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	if err := root.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := root.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,74 @@
+package credentials
+
+import (
+	"github.com/sensepost/gowitness/pkg/models"
+	"github.com/sensepost/gowitness/pkg/signatures"
+	"gorm.io/gorm"
+)
+
+// annotateBatchSize is the number of results annotated at a time
+const annotateBatchSize = 200
+
+// Annotate replaces the categories and default credential hints of every
+// result in a database with the ones engine and database give it. Like a
+// scan, signatures are matched once per result for both. It returns the
+// number of results that were given at least one category, and at least
+// one hint.
+func Annotate(db *gorm.DB, engine *signatures.Engine, database *Database) (categorized int, annotated int, err error) {
+	var batch []*models.Result
+
+	if err := db.Model(&models.Result{}).
+		Preload("Headers").Preload("Technologies").Preload("Cookies").Order("id").
+		FindInBatches(&batch, annotateBatchSize, func(_ *gorm.DB, _ int) error {
+			ids := make([]uint, 0, len(batch))
+			var (
+				categories  []models.Category
+				credentials []models.DefaultCredential
+			)
+			for _, result := range batch {
+				ids = append(ids, result.ID)
+
+				matched := engine.Match(result)
+
+				resultCategories := signatures.Categories(matched)
+				for i := range resultCategories {
+					resultCategories[i].ResultID = result.ID
+				}
+				if len(resultCategories) > 0 {
+					categorized++
+				}
+				categories = append(categories, resultCategories...)
+
+				hints := database.Lookup(result, matched)
+				for i := range hints {
+					hints[i].ResultID = result.ID
+				}
+				if len(hints) > 0 {
+					annotated++
+				}
+				credentials = append(credentials, hints...)
+			}
+
+			return db.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Where("result_id in ?", ids).Delete(&models.Category{}).Error; err != nil {
+					return err
+				}
+				if err := tx.Where("result_id in ?", ids).Delete(&models.DefaultCredential{}).Error; err != nil {
+					return err
+				}
+				if len(categories) > 0 {
+					if err := tx.Create(&categories).Error; err != nil {
+						return err
+					}
+				}
+				if len(credentials) == 0 {
+					return nil
+				}
+				return tx.Create(&credentials).Error
+			})
+		}).Error; err != nil {
+		return 0, 0, err
+	}
+
+	return categorized, annotated, nil
+}
@@ -0,0 +1,173 @@
+package credentials
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/sensepost/gowitness/pkg/models"
+	"github.com/sensepost/gowitness/pkg/signatures"
+)
+
+//go:embed default.json
+var defaultProducts []byte
+
+// Product is a product with known default credentials. A result is
+// identified as the product when it has one of the technologies, or
+// matched one of the signatures.
+type Product struct {
+	Name         string        `json:"product"`
+	Technologies []string      `json:"technologies,omitempty"`
+	Signatures   []string      `json:"signatures,omitempty"`
+	Credentials  []*Credential `json:"credentials"`
+	Notes        string        `json:"notes,omitempty"`
+	Reference    string        `json:"reference,omitempty"`
+}
+
+// Credential is a username and password pair. An empty password means
+// the password is blank.
+type Credential struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Database is an offline database of default credentials. Nothing in it
+// is ever tried against a target, it only annotates results.
+type Database struct {
+	products []*Product
+}
+
+// New returns a database with the bundled default credentials, as well as
+// any in paths. A path may be a credentials file, or a directory of .json
+// files. A product in a later file replaces an earlier one with the same
+// name, which is how the bundled entries are updated.
+func New(paths ...string) (*Database, error) {
+	database := &Database{}
+	if err := database.add("default credentials", defaultProducts); err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		files := []string{path}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			if files, err = filepath.Glob(filepath.Join(path, "*.json")); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			if err := database.add(file, data); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return database, nil
+}
+
+// add parses and validates a credentials file
+func (d *Database) add(source string, data []byte) error {
+	var products []*Product
+	if err := json.Unmarshal(data, &products); err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+
+	for _, product := range products {
+		if err := product.validate(); err != nil {
+			return fmt.Errorf("%s: product %q: %w", source, product.Name, err)
+		}
+
+		d.products = slices.DeleteFunc(d.products, func(existing *Product) bool {
+			return strings.EqualFold(existing.Name, product.Name)
+		})
+		d.products = append(d.products, product)
+	}
+
+	return nil
+}
+
+// Products returns the products in the database
+func (d *Database) Products() []*Product {
+	return d.products
+}
+
+// Lookup returns the default credentials for the products a result was
+// identified as, given the signatures it matched
+func (d *Database) Lookup(result *models.Result, matched []*signatures.Signature) []models.DefaultCredential {
+	var credentials []models.DefaultCredential
+
+	for _, product := range d.products {
+		match := product.identify(result, matched)
+		if match == "" {
+			continue
+		}
+
+		for _, credential := range product.Credentials {
+			credentials = append(credentials, models.DefaultCredential{
+				Product:   product.Name,
+				Username:  credential.Username,
+				Password:  credential.Password,
+				Match:     match,
+				Notes:     product.Notes,
+				Reference: product.Reference,
+			})
+		}
+	}
+
+	return credentials
+}
+
+func (p *Product) validate() error {
+	if p.Name == "" {
+		return errors.New("a product name is required")
+	}
+	if len(p.Technologies) == 0 && len(p.Signatures) == 0 {
+		return errors.New("at least one technology or signature is required")
+	}
+	if len(p.Credentials) == 0 {
+		return errors.New("at least one credential is required")
+	}
+	for _, credential := range p.Credentials {
+		if credential.Username == "" {
+			return errors.New("credentials need a username")
+		}
+	}
+
+	return nil
+}
+
+// identify returns what identified a result as the product, or an empty
+// string if nothing did
+func (p *Product) identify(result *models.Result, matched []*signatures.Signature) string {
+	for _, technology := range p.Technologies {
+		for _, tech := range result.Technologies {
+			if strings.EqualFold(tech.Value, technology) {
+				return "tech:" + tech.Value
+			}
+		}
+	}
+
+	for _, name := range p.Signatures {
+		for _, signature := range matched {
+			if signature.Name == name {
+				return "signature:" + signature.Name
+			}
+		}
+	}
+
+	return ""
+}
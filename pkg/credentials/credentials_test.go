@@ -0,0 +1,117 @@
+package credentials
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sensepost/gowitness/pkg/models"
+	"github.com/sensepost/gowitness/pkg/signatures"
+)
+
+func TestLookup(t *testing.T) {
+	engine, err := signatures.New()
+	if err != nil {
+		t.Fatalf("signatures.New() error = %v", err)
+	}
+	database, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		result  *models.Result
+		product string
+		match   string
+	}{
+		{
+			name:    "technology",
+			result:  &models.Result{Title: "Apache Tomcat/9.0.80", Technologies: []models.Technology{{Value: "Apache Tomcat"}}},
+			product: "Apache Tomcat Manager",
+			match:   "tech:Apache Tomcat",
+		},
+		{
+			name: "signature",
+			result: &models.Result{ResponseCode: 401, Headers: []models.Header{
+				{Key: "WWW-Authenticate", Value: `Basic realm="Tomcat Manager Application"`},
+			}},
+			product: "Apache Tomcat Manager",
+			match:   "signature:tomcat-manager",
+		},
+		{
+			name:    "title signature",
+			result:  &models.Result{ResponseCode: 200, Title: "iDRAC9 - Login"},
+			product: "Dell iDRAC",
+			match:   "signature:idrac",
+		},
+		{
+			name:   "nothing",
+			result: &models.Result{ResponseCode: 200, Title: "Welcome to our shop"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := database.Lookup(tt.result, engine.Match(tt.result))
+			if tt.product == "" {
+				if len(got) != 0 {
+					t.Errorf("Lookup() = %+v, want no credentials", got)
+				}
+				return
+			}
+
+			if len(got) == 0 {
+				t.Fatalf("Lookup() returned no credentials, want %s", tt.product)
+			}
+			for _, credential := range got {
+				if credential.Product != tt.product || credential.Match != tt.match {
+					t.Errorf("Lookup() = %+v, want product %q matched by %q", credential, tt.product, tt.match)
+				}
+			}
+		})
+	}
+}
+
+func TestNewWithFiles(t *testing.T) {
+	dir := t.TempDir()
+	custom := `[{"product": "Dell iDRAC", "signatures": ["idrac"],
+		"credentials": [{"username": "root", "password": "changed"}]}]`
+	if err := os.WriteFile(filepath.Join(dir, "custom.json"), []byte(custom), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	database, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	idrac := 0
+	for _, product := range database.Products() {
+		if product.Name == "Dell iDRAC" {
+			idrac++
+			if product.Credentials[0].Password != "changed" {
+				t.Errorf("Dell iDRAC password = %q, want the updated one", product.Credentials[0].Password)
+			}
+		}
+	}
+	if idrac != 1 {
+		t.Errorf("found %d Dell iDRAC products, want the bundled one replaced", idrac)
+	}
+
+	invalid := []string{
+		`[{"product": "", "signatures": ["x"], "credentials": [{"username": "a"}]}]`,
+		`[{"product": "x", "credentials": [{"username": "a"}]}]`,
+		`[{"product": "x", "signatures": ["x"], "credentials": []}]`,
+		`[{"product": "x", "signatures": ["x"], "credentials": [{"password": "a"}]}]`,
+	}
+	for _, products := range invalid {
+		path := filepath.Join(t.TempDir(), "invalid.json")
+		if err := os.WriteFile(path, []byte(products), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := New(path); err == nil {
+			t.Errorf("New() with %s should fail", products)
+		}
+	}
+}
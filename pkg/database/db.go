@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/glebarez/sqlite"
+	"github.com/sensepost/gowitness/pkg/log"
 	"github.com/sensepost/gowitness/pkg/models"
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
@@ -79,14 +80,33 @@ func Connection(uri string, shouldExist, debug bool) (*gorm.DB, error) {
 		&models.TLS{},
 		&models.TLSSanList{},
 		&models.Technology{},
+		&models.Tag{},
+		&models.Category{},
+		&models.DefaultCredential{},
+		&models.ImageHash{},
+		&models.Favicon{},
 		&models.Header{},
 		&models.NetworkLog{},
 		&models.ConsoleLog{},
 		&models.Cookie{},
+		&models.Link{},
+		&models.Form{},
+		&models.FormInput{},
+		&models.Script{},
+		&models.MetaTag{},
+		&models.BlockedRequest{},
+		&models.SavedSearch{},
+		&models.AlertRule{},
+		&models.Setting{},
 	); err != nil {
 		return nil, err
 	}
 
+	// searching still works without a full-text index, just slower
+	if err := UseFullTextIndex(c, db.Scheme); err != nil {
+		log.Warn("could not set up the full-text search index", "err", err)
+	}
+
 	return c, nil
 }
 
@@ -0,0 +1,171 @@
+package database
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/sensepost/gowitness/pkg/log"
+	"github.com/sensepost/gowitness/pkg/models"
+	"gorm.io/gorm"
+)
+
+// DeleteBatchSize keeps the number of bind variables in a single
+// statement below what every supported database allows. Lists of ids
+// should be chunked to it.
+const DeleteBatchSize = 500
+
+// ResultArtifacts returns the names of the files stored on disk for a
+// result, relative to the screenshot path. Any new per-result file (a PDF,
+// a HAR etc.) should be added here so that deletes clean it up.
+func ResultArtifacts(result *models.Result) []string {
+	var artifacts []string
+	if result.Filename != "" {
+		artifacts = append(artifacts, result.Filename)
+	}
+
+	return artifacts
+}
+
+// Artifacts returns the paths of the files in artifactPath that belong to
+// the results with the given ids and that no other result refers to. These
+// are the files DeleteResults removes.
+func Artifacts(db *gorm.DB, artifactPath string, ids []uint) ([]string, error) {
+	// how many of the results being deleted refer to each file
+	deleting := make(map[string]int)
+	for batch := range slices.Chunk(ids, DeleteBatchSize) {
+		var results []*models.Result
+		if err := db.Model(&models.Result{}).Select("id", "filename").
+			Where("id in ?", batch).Find(&results).Error; err != nil {
+			return nil, err
+		}
+
+		for _, result := range results {
+			for _, name := range ResultArtifacts(result) {
+				deleting[name]++
+			}
+		}
+	}
+
+	names := make([]string, 0, len(deleting))
+	for name := range deleting {
+		names = append(names, name)
+	}
+
+	// files are named after the URL, so rescans of a URL share the
+	// same file. only remove files no other result refers to.
+	var paths []string
+	for batch := range slices.Chunk(names, DeleteBatchSize) {
+		var counts []struct {
+			Filename string
+			Count    int
+		}
+		if err := db.Model(&models.Result{}).Select("filename, count(*) as count").
+			Where("filename in ?", batch).Group("filename").Scan(&counts).Error; err != nil {
+			return nil, err
+		}
+
+		for _, count := range counts {
+			if count.Count > deleting[count.Filename] {
+				continue
+			}
+
+			path := filepath.Join(artifactPath, filepath.Base(count.Filename))
+			if _, err := os.Stat(path); err == nil {
+				paths = append(paths, path)
+			}
+		}
+	}
+
+	return paths, nil
+}
+
+// resultTables are the models holding rows related to a result by a
+// result_id. Forms and TLS have rows of their own, see DeleteRelated.
+var resultTables = []interface{}{
+	&models.TLS{},
+	&models.Technology{},
+	&models.Tag{},
+	&models.Category{},
+	&models.DefaultCredential{},
+	&models.ImageHash{},
+	&models.Favicon{},
+	&models.Header{},
+	&models.NetworkLog{},
+	&models.ConsoleLog{},
+	&models.Cookie{},
+	&models.Link{},
+	&models.Form{},
+	&models.Script{},
+	&models.MetaTag{},
+	&models.BlockedRequest{},
+}
+
+// DeleteRelated deletes the rows related to results, and removes them from
+// the full-text index, leaving the results themselves. Cascading foreign
+// keys can't be relied on for this, as SQLite only enforces them on the
+// connections that enabled them.
+func DeleteRelated(tx *gorm.DB, ids []uint) error {
+	for batch := range slices.Chunk(ids, DeleteBatchSize) {
+		if err := tx.Where("tls_id in (?)", tx.Model(&models.TLS{}).Select("id").
+			Where("result_id in ?", batch)).Delete(&models.TLSSanList{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("form_id in (?)", tx.Model(&models.Form{}).Select("id").
+			Where("result_id in ?", batch)).Delete(&models.FormInput{}).Error; err != nil {
+			return err
+		}
+
+		for _, model := range resultTables {
+			if err := tx.Where("result_id in ?", batch).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		if index := FullText(tx); index != nil {
+			if err := index.Delete(tx, batch...); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// DeleteResults deletes results and their related data, along with any
+// files in artifactPath that only they refer to. It returns the paths of
+// the files that were removed; files that could not be removed are logged.
+func DeleteResults(db *gorm.DB, artifactPath string, ids []uint) ([]string, error) {
+	paths, err := Artifacts(db, artifactPath, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		for batch := range slices.Chunk(ids, DeleteBatchSize) {
+			if err := DeleteRelated(tx, batch); err != nil {
+				return err
+			}
+			if err := tx.Delete(&models.Result{}, batch).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// the results are gone at this point, so a file that can't be
+	// removed is not worth failing the delete over
+	var removed []string
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Warn("could not remove result file", "path", path, "err", err)
+			continue
+		}
+		removed = append(removed, path)
+	}
+
+	return removed, nil
+}
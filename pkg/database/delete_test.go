@@ -0,0 +1,139 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/sensepost/gowitness/internal/testdb"
+	"github.com/sensepost/gowitness/pkg/models"
+)
+
+func TestDeleteResultsSharedFile(t *testing.T) {
+	_, conn := testdb.Open(t, Connection)
+	dir := t.TempDir()
+
+	// rescans of a url share its screenshot file
+	shared := "https-example.com.jpeg"
+	path := filepath.Join(dir, shared)
+	if err := os.WriteFile(path, []byte("screenshot"), 0o644); err != nil {
+		t.Fatalf("could not write a test screenshot: %v", err)
+	}
+
+	first := &models.Result{URL: "https://example.com", Filename: shared}
+	second := &models.Result{URL: "https://example.com", Filename: shared}
+	if err := conn.Create([]*models.Result{first, second}).Error; err != nil {
+		t.Fatalf("could not create test results: %v", err)
+	}
+
+	removed, err := DeleteResults(conn, dir, []uint{first.ID})
+	if err != nil {
+		t.Fatalf("DeleteResults() error = %v", err)
+	}
+	if len(removed) != 0 {
+		t.Errorf("DeleteResults() of one result removed %v, want nothing", removed)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("shared file was removed while a result still refers to it: %v", err)
+	}
+
+	removed, err = DeleteResults(conn, dir, []uint{second.ID})
+	if err != nil {
+		t.Fatalf("DeleteResults() error = %v", err)
+	}
+	if want := []string{path}; !slices.Equal(removed, want) {
+		t.Errorf("DeleteResults() of the last result removed %v, want %v", removed, want)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("shared file still exists after deleting every result: %v", err)
+	}
+
+	var count int64
+	if err := conn.Model(&models.Result{}).Count(&count).Error; err != nil {
+		t.Fatalf("could not count results: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("%d results left, want 0", count)
+	}
+}
+
+func TestDeleteResultsBothSharingFile(t *testing.T) {
+	_, conn := testdb.Open(t, Connection)
+	dir := t.TempDir()
+
+	shared := "https-example.com.jpeg"
+	path := filepath.Join(dir, shared)
+	if err := os.WriteFile(path, []byte("screenshot"), 0o644); err != nil {
+		t.Fatalf("could not write a test screenshot: %v", err)
+	}
+
+	results := []*models.Result{
+		{URL: "https://example.com", Filename: shared},
+		{URL: "https://example.com", Filename: shared},
+	}
+	if err := conn.Create(results).Error; err != nil {
+		t.Fatalf("could not create test results: %v", err)
+	}
+
+	removed, err := DeleteResults(conn, dir, []uint{results[0].ID, results[1].ID})
+	if err != nil {
+		t.Fatalf("DeleteResults() error = %v", err)
+	}
+	if want := []string{path}; !slices.Equal(removed, want) {
+		t.Errorf("DeleteResults() removed %v, want %v", removed, want)
+	}
+}
+
+func TestDeleteRelated(t *testing.T) {
+	_, conn := testdb.Open(t, Connection)
+	index := FullText(conn)
+	if index == nil {
+		t.Fatal("FullText() = nil, want the sqlite index")
+	}
+
+	result := &models.Result{
+		URL:     "https://example.com",
+		Title:   "welcome to nginx",
+		Tags:    []models.Tag{{Value: "nginx"}},
+		Headers: []models.Header{{Key: "Server", Value: "nginx"}},
+	}
+	if err := conn.Create(result).Error; err != nil {
+		t.Fatalf("could not create a test result: %v", err)
+	}
+	if err := index.Index(conn, result); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+
+	if err := DeleteRelated(conn, []uint{result.ID}); err != nil {
+		t.Fatalf("DeleteRelated() error = %v", err)
+	}
+
+	var indexed int64
+	if err := conn.Raw(`SELECT count(*) FROM `+fullTextTable+` WHERE rowid = ?`, result.ID).
+		Scan(&indexed).Error; err != nil {
+		t.Fatalf("could not count full-text rows: %v", err)
+	}
+	if indexed != 0 {
+		t.Errorf("%d full-text rows left for the result, want 0", indexed)
+	}
+
+	for _, model := range []interface{}{&models.Tag{}, &models.Header{}} {
+		var count int64
+		if err := conn.Model(model).Where("result_id = ?", result.ID).Count(&count).Error; err != nil {
+			t.Fatalf("could not count related rows: %v", err)
+		}
+		if count != 0 {
+			t.Errorf("%d %T rows left for the result, want 0", count, model)
+		}
+	}
+
+	// the result itself is kept
+	var count int64
+	if err := conn.Model(&models.Result{}).Where("id = ?", result.ID).Count(&count).Error; err != nil {
+		t.Fatalf("could not count results: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("%d results left, want 1", count)
+	}
+}
@@ -0,0 +1,697 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"unicode"
+
+	"github.com/sensepost/gowitness/pkg/log"
+	"github.com/sensepost/gowitness/pkg/models"
+	xhtml "golang.org/x/net/html"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	fullTextPluginName = "gowitness:fulltext"
+	fullTextTable      = "result_fulltext"
+
+	// fullTextBackfillSetting is the setting a backfill stores its progress
+	// in, which is fullTextBackfillDone once every result is indexed
+	fullTextBackfillSetting   = "fulltext_backfill"
+	fullTextBackfillDone      = "done"
+	fullTextBackfillBatchSize = 100
+
+	// fullTextRankBatchSize is how many result ids are ranked at a time,
+	// keeping the bind variables of a statement in bounds
+	fullTextRankBatchSize = 500
+
+	// maxFullTextBody limits how much of a page's text is indexed
+	maxFullTextBody = 512 * 1024
+
+	// snippet highlight markers. these are replaced with <mark> tags once
+	// the rest of the snippet has been escaped.
+	highlightStart = "\x02"
+	highlightStop  = "\x03"
+)
+
+// FullTextIndex is a full-text index over the title, HTML, headers and
+// console logs of results. Implementations are database specific.
+type FullTextIndex interface {
+	gorm.Plugin
+
+	// Index adds or replaces the indexed content for results. Results must
+	// have their Headers and Console preloaded.
+	Index(db *gorm.DB, results ...*models.Result) error
+	// Delete removes results from the index
+	Delete(db *gorm.DB, ids ...uint) error
+	// Match returns a condition matching the ids of results whose indexed
+	// content matches value. If body is set, only the HTML is searched.
+	Match(value string, body bool) (string, []interface{})
+	// Rank returns a rank (higher is better) and highlighted snippet for
+	// each of the result ids that match any of the values. ids are looked
+	// up in batches, so there may be any number of them.
+	Rank(db *gorm.DB, values []string, ids []uint) (map[uint]FullTextHit, error)
+
+	// backfilled checks if every result written before the index existed
+	// has been indexed
+	backfilled(db *gorm.DB) bool
+}
+
+// FullTextHit is a ranked full-text match
+type FullTextHit struct {
+	Rank float64 `json:"rank"`
+	// Snippet is HTML escaped, with matches wrapped in <mark> tags
+	Snippet string `json:"snippet"`
+}
+
+// fullTextIndexes are the full-text index implementations by URI scheme
+var fullTextIndexes = map[string]func() FullTextIndex{
+	"sqlite":   func() FullTextIndex { return &sqliteFullText{} },
+	"postgres": func() FullTextIndex { return &postgresFullText{} },
+	"mysql":    func() FullTextIndex { return &mysqlFullText{} },
+}
+
+// UseFullTextIndex registers the full-text index for scheme on a connection,
+// creating the index if it does not exist yet. Results written before the
+// index existed are only indexed by BackfillFullText; until then, searches
+// don't use the index (see FullTextReady).
+func UseFullTextIndex(db *gorm.DB, scheme string) error {
+	index, ok := fullTextIndexes[scheme]
+	if !ok {
+		return fmt.Errorf("no full-text index for %s", scheme)
+	}
+
+	return db.Use(index())
+}
+
+// FullText returns the full-text index registered on a connection, or nil
+// if there is none. Writes should keep it up to date, but searches should
+// use FullTextReady.
+func FullText(db *gorm.DB) FullTextIndex {
+	if plugin, ok := db.Config.Plugins[fullTextPluginName]; ok {
+		return plugin.(FullTextIndex)
+	}
+
+	return nil
+}
+
+// FullTextReady returns the full-text index registered on a connection if
+// it holds every result, or nil if there is none or it is still missing
+// results written before it existed.
+func FullTextReady(db *gorm.DB) FullTextIndex {
+	if index := FullText(db); index != nil && index.backfilled(db) {
+		return index
+	}
+
+	return nil
+}
+
+// BackfillFullText indexes the results in a database that were written
+// before it had a full-text index. Progress is stored in the database, so
+// a backfill that stops part-way carries on from there when run again.
+func BackfillFullText(db *gorm.DB) error {
+	index := FullText(db)
+	if index == nil {
+		return errors.New("the database has no full-text index")
+	}
+
+	return backfill(db, index)
+}
+
+// fullTextDocument is the content of a result that gets indexed
+type fullTextDocument struct {
+	ResultID uint
+	Title    string
+	Body     string
+	Headers  string
+	Console  string
+}
+
+func newFullTextDocument(result *models.Result) *fullTextDocument {
+	var headers, console strings.Builder
+	for _, header := range result.Headers {
+		fmt.Fprintf(&headers, "%s: %s\n", header.Key, header.Value)
+	}
+	for _, entry := range result.Console {
+		fmt.Fprintf(&console, "%s\n", entry.Value)
+	}
+
+	body := htmlText(result.HTML)
+	if len(body) > maxFullTextBody {
+		body = strings.ToValidUTF8(body[:maxFullTextBody], "")
+	}
+
+	return &fullTextDocument{
+		ResultID: result.ID,
+		Title:    result.Title,
+		Body:     body,
+		Headers:  headers.String(),
+		Console:  console.String(),
+	}
+}
+
+// htmlText returns the text content of an HTML document, ignoring scripts
+// and styles
+func htmlText(document string) string {
+	var text strings.Builder
+	var skip int
+
+	tokenizer := xhtml.NewTokenizer(strings.NewReader(document))
+	for {
+		switch tokenizer.Next() {
+		case xhtml.ErrorToken:
+			return strings.TrimSpace(text.String())
+		case xhtml.StartTagToken:
+			if name, _ := tokenizer.TagName(); isHiddenTag(name) {
+				skip++
+			}
+		case xhtml.EndTagToken:
+			if name, _ := tokenizer.TagName(); isHiddenTag(name) && skip > 0 {
+				skip--
+			}
+		case xhtml.TextToken:
+			if skip > 0 {
+				continue
+			}
+			if value := strings.TrimSpace(string(tokenizer.Text())); value != "" {
+				text.WriteString(value)
+				text.WriteString(" ")
+			}
+		}
+	}
+}
+
+func isHiddenTag(name []byte) bool {
+	switch string(name) {
+	case "script", "style", "noscript", "template":
+		return true
+	}
+	return false
+}
+
+// backfill indexes the results in a database that were written before it
+// had a full-text index. progress is stored as the id of the last indexed
+// result.
+func backfill(db *gorm.DB, index FullTextIndex) error {
+	progress, err := backfillProgress(db)
+	if err != nil || progress == fullTextBackfillDone {
+		return err
+	}
+	after, _ := strconv.ParseUint(progress, 10, 64)
+
+	var count int64
+	if err := db.Model(&models.Result{}).Where("id > ?", after).Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count > 0 {
+		log.Info("building full-text search index", "results", count)
+
+		var indexed int64
+		var results []*models.Result
+		if err := db.Model(&models.Result{}).Where("id > ?", after).
+			Preload("Headers").Preload("Console").
+			FindInBatches(&results, fullTextBackfillBatchSize, func(tx *gorm.DB, batch int) error {
+				if err := index.Index(db, results...); err != nil {
+					return err
+				}
+				if err := setBackfillProgress(db, strconv.FormatUint(uint64(results[len(results)-1].ID), 10)); err != nil {
+					return err
+				}
+
+				indexed += int64(len(results))
+				if batch%10 == 0 || indexed == count {
+					log.Info("building full-text search index", "indexed", indexed, "results", count)
+				}
+				return nil
+			}).Error; err != nil {
+			return err
+		}
+	}
+
+	return setBackfillProgress(db, fullTextBackfillDone)
+}
+
+// backfillProgress returns how far a backfill got, which is empty if one
+// never ran
+func backfillProgress(db *gorm.DB) (string, error) {
+	var settings []*models.Setting
+	if err := db.Where("name = ?", fullTextBackfillSetting).Limit(1).Find(&settings).Error; err != nil {
+		return "", err
+	}
+	if len(settings) == 0 {
+		return "", nil
+	}
+
+	return settings[0].Value, nil
+}
+
+// initBackfill marks a new index over a database without results as
+// backfilled, as there is nothing to backfill
+func initBackfill(db *gorm.DB) error {
+	progress, err := backfillProgress(db)
+	if err != nil || progress == fullTextBackfillDone {
+		return err
+	}
+
+	var results []uint
+	if err := db.Model(&models.Result{}).Limit(1).Pluck("id", &results).Error; err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		return setBackfillProgress(db, fullTextBackfillDone)
+	}
+
+	log.Warn("the full-text search index is missing results, so searches won't use it until report reindex or report server builds it")
+	return nil
+}
+
+// fullTextProgress tracks whether an index has been backfilled
+type fullTextProgress struct {
+	done atomic.Bool
+}
+
+// backfilled checks if a backfill completed. once one has, it is not
+// checked again.
+func (p *fullTextProgress) backfilled(db *gorm.DB) bool {
+	if p.done.Load() {
+		return true
+	}
+
+	progress, err := backfillProgress(db)
+	if err != nil {
+		return false
+	}
+
+	done := progress == fullTextBackfillDone
+	p.done.Store(done)
+	return done
+}
+
+// setBackfillProgress stores how far a backfill got
+func setBackfillProgress(db *gorm.DB, value string) error {
+	return db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&models.Setting{
+		Name:  fullTextBackfillSetting,
+		Value: value,
+	}).Error
+}
+
+// fullTextWords splits a value into the words a full-text search would
+// match on
+func fullTextWords(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// highlight escapes a snippet and converts highlight markers to <mark> tags
+func highlight(snippet string) string {
+	snippet = html.EscapeString(strings.TrimSpace(snippet))
+	snippet = strings.ReplaceAll(snippet, highlightStart, "<mark>")
+	return strings.ReplaceAll(snippet, highlightStop, "</mark>")
+}
+
+// sqliteFullText is an FTS5 based full-text index
+type sqliteFullText struct {
+	fullTextProgress
+}
+
+func (s *sqliteFullText) Name() string { return fullTextPluginName }
+
+func (s *sqliteFullText) Initialize(db *gorm.DB) error {
+	if err := db.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS ` + fullTextTable +
+		` USING fts5(title, body, headers, console)`).Error; err != nil {
+		return err
+	}
+
+	// fts tables can't have foreign keys, so use a trigger to remove
+	// deleted results from the index
+	if err := db.Exec(`CREATE TRIGGER IF NOT EXISTS ` + fullTextTable + `_delete
+		AFTER DELETE ON results BEGIN
+			DELETE FROM ` + fullTextTable + ` WHERE rowid = old.id;
+		END`).Error; err != nil {
+		return err
+	}
+
+	return initBackfill(db)
+}
+
+func (s *sqliteFullText) Index(db *gorm.DB, results ...*models.Result) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, result := range results {
+			doc := newFullTextDocument(result)
+
+			if err := tx.Exec(`DELETE FROM `+fullTextTable+` WHERE rowid = ?`, doc.ResultID).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(`INSERT INTO `+fullTextTable+` (rowid, title, body, headers, console) VALUES (?, ?, ?, ?, ?)`,
+				doc.ResultID, doc.Title, doc.Body, doc.Headers, doc.Console).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *sqliteFullText) Delete(db *gorm.DB, ids ...uint) error {
+	return db.Exec(`DELETE FROM `+fullTextTable+` WHERE rowid IN ?`, ids).Error
+}
+
+// expression converts a value to an FTS5 phrase, with the last word
+// matched as a prefix
+func (s *sqliteFullText) expression(value string) string {
+	words := fullTextWords(value)
+	if len(words) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(`"%s"*`, strings.Join(words, " "))
+}
+
+func (s *sqliteFullText) Match(value string, body bool) (string, []interface{}) {
+	expression := s.expression(value)
+	if expression == "" {
+		return "1 = 0", nil
+	}
+	if body {
+		expression = "body : " + expression
+	}
+
+	return `id in (SELECT rowid FROM ` + fullTextTable + ` WHERE ` + fullTextTable + ` MATCH ?)`,
+		[]interface{}{expression}
+}
+
+func (s *sqliteFullText) Rank(db *gorm.DB, values []string, ids []uint) (map[uint]FullTextHit, error) {
+	var expressions []string
+	for _, value := range values {
+		if expression := s.expression(value); expression != "" {
+			expressions = append(expressions, expression)
+		}
+	}
+
+	hits := make(map[uint]FullTextHit)
+	if len(expressions) == 0 || len(ids) == 0 {
+		return hits, nil
+	}
+
+	for batch := range slices.Chunk(ids, fullTextRankBatchSize) {
+		var rows []struct {
+			ID      uint
+			Rank    float64
+			Snippet string
+		}
+		if err := db.Raw(`SELECT rowid AS id, -bm25(`+fullTextTable+`) AS rank,
+			snippet(`+fullTextTable+`, -1, ?, ?, '…', 16) AS snippet
+			FROM `+fullTextTable+` WHERE `+fullTextTable+` MATCH ? AND rowid IN ?`,
+			highlightStart, highlightStop, strings.Join(expressions, " OR "), batch).
+			Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			hits[row.ID] = FullTextHit{Rank: row.Rank, Snippet: highlight(row.Snippet)}
+		}
+	}
+
+	return hits, nil
+}
+
+// postgresFullText is a tsvector based full-text index
+type postgresFullText struct {
+	fullTextProgress
+}
+
+func (p *postgresFullText) Name() string { return fullTextPluginName }
+
+func (p *postgresFullText) Initialize(db *gorm.DB) error {
+	for _, statement := range []string{
+		`CREATE TABLE IF NOT EXISTS ` + fullTextTable + ` (
+			result_id bigint PRIMARY KEY REFERENCES results(id) ON DELETE CASCADE,
+			title text,
+			body text,
+			headers text,
+			console text,
+			body_document tsvector GENERATED ALWAYS AS (to_tsvector('simple', coalesce(body, ''))) STORED,
+			document tsvector GENERATED ALWAYS AS (to_tsvector('simple',
+				coalesce(title, '') || ' ' || coalesce(body, '') || ' ' ||
+				coalesce(headers, '') || ' ' || coalesce(console, ''))) STORED
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_` + fullTextTable + `_body ON ` + fullTextTable + ` USING GIN (body_document)`,
+		`CREATE INDEX IF NOT EXISTS idx_` + fullTextTable + `_document ON ` + fullTextTable + ` USING GIN (document)`,
+	} {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	return initBackfill(db)
+}
+
+func (p *postgresFullText) Index(db *gorm.DB, results ...*models.Result) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, result := range results {
+			doc := newFullTextDocument(result)
+
+			// postgres text can't hold NUL bytes
+			if err := tx.Exec(`INSERT INTO `+fullTextTable+` (result_id, title, body, headers, console)
+				VALUES (?, ?, ?, ?, ?)
+				ON CONFLICT (result_id) DO UPDATE SET
+					title = excluded.title, body = excluded.body,
+					headers = excluded.headers, console = excluded.console`,
+				doc.ResultID, stripNul(doc.Title), stripNul(doc.Body),
+				stripNul(doc.Headers), stripNul(doc.Console)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (p *postgresFullText) Delete(db *gorm.DB, ids ...uint) error {
+	return db.Exec(`DELETE FROM `+fullTextTable+` WHERE result_id IN ?`, ids).Error
+}
+
+func (p *postgresFullText) Match(value string, body bool) (string, []interface{}) {
+	column := "document"
+	if body {
+		column = "body_document"
+	}
+
+	return `id in (SELECT result_id FROM ` + fullTextTable + ` WHERE ` + column + ` @@ phraseto_tsquery('simple', ?))`,
+		[]interface{}{value}
+}
+
+func (p *postgresFullText) Rank(db *gorm.DB, values []string, ids []uint) (map[uint]FullTextHit, error) {
+	hits := make(map[uint]FullTextHit)
+	if len(values) == 0 || len(ids) == 0 {
+		return hits, nil
+	}
+
+	// OR the phrases together
+	var queries []string
+	var vars []interface{}
+	for _, value := range values {
+		queries = append(queries, "phraseto_tsquery('simple', ?)")
+		vars = append(vars, value)
+	}
+	query := strings.Join(queries, " || ")
+
+	options := fmt.Sprintf(`StartSel="%s", StopSel="%s", MaxFragments=2, MaxWords=20, MinWords=8`, highlightStart, highlightStop)
+
+	for batch := range slices.Chunk(ids, fullTextRankBatchSize) {
+		var rows []struct {
+			ID      uint
+			Rank    float64
+			Snippet string
+		}
+		if err := db.Raw(`SELECT result_id AS id, ts_rank(document, q) AS rank,
+			ts_headline('simple', coalesce(title, '') || ' ' || coalesce(body, '') || ' ' ||
+				coalesce(headers, '') || ' ' || coalesce(console, ''), q, ?) AS snippet
+			FROM `+fullTextTable+`, (SELECT `+query+` AS q) AS query
+			WHERE document @@ q AND result_id IN ?`,
+			append(append([]interface{}{options}, vars...), batch)...).
+			Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			hits[row.ID] = FullTextHit{Rank: row.Rank, Snippet: highlight(row.Snippet)}
+		}
+	}
+
+	return hits, nil
+}
+
+func stripNul(s string) string {
+	return strings.ReplaceAll(s, "\x00", "")
+}
+
+// mysqlFullText is a FULLTEXT index based full-text index
+type mysqlFullText struct {
+	fullTextProgress
+}
+
+func (m *mysqlFullText) Name() string { return fullTextPluginName }
+
+func (m *mysqlFullText) Initialize(db *gorm.DB) error {
+	if err := db.Exec(`CREATE TABLE IF NOT EXISTS ` + fullTextTable + ` (
+		result_id bigint unsigned NOT NULL PRIMARY KEY,
+		title text,
+		body longtext,
+		headers longtext,
+		console longtext,
+		FULLTEXT INDEX idx_` + fullTextTable + `_body (body),
+		FULLTEXT INDEX idx_` + fullTextTable + `_document (title, body, headers, console),
+		CONSTRAINT fk_` + fullTextTable + `_result FOREIGN KEY (result_id) REFERENCES results(id) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`).Error; err != nil {
+		return err
+	}
+
+	return initBackfill(db)
+}
+
+func (m *mysqlFullText) Index(db *gorm.DB, results ...*models.Result) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, result := range results {
+			doc := newFullTextDocument(result)
+
+			if err := tx.Exec(`INSERT INTO `+fullTextTable+` (result_id, title, body, headers, console)
+				VALUES (?, ?, ?, ?, ?)
+				ON DUPLICATE KEY UPDATE
+					title = VALUES(title), body = VALUES(body),
+					headers = VALUES(headers), console = VALUES(console)`,
+				doc.ResultID, doc.Title, doc.Body, doc.Headers, doc.Console).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// expression converts a value to a boolean mode phrase. single words are
+// matched as a prefix.
+func (m *mysqlFullText) expression(value string) string {
+	words := fullTextWords(value)
+	switch len(words) {
+	case 0:
+		return ""
+	case 1:
+		return words[0] + "*"
+	}
+
+	return fmt.Sprintf(`"%s"`, strings.Join(words, " "))
+}
+
+func (m *mysqlFullText) Delete(db *gorm.DB, ids ...uint) error {
+	return db.Exec(`DELETE FROM `+fullTextTable+` WHERE result_id IN ?`, ids).Error
+}
+
+func (m *mysqlFullText) Match(value string, body bool) (string, []interface{}) {
+	expression := m.expression(value)
+	if expression == "" {
+		return "1 = 0", nil
+	}
+
+	columns := "title, body, headers, console"
+	if body {
+		columns = "body"
+	}
+
+	return `id in (SELECT result_id FROM ` + fullTextTable + ` WHERE MATCH(` + columns + `) AGAINST (? IN BOOLEAN MODE))`,
+		[]interface{}{expression}
+}
+
+func (m *mysqlFullText) Rank(db *gorm.DB, values []string, ids []uint) (map[uint]FullTextHit, error) {
+	var expressions []string
+	for _, value := range values {
+		if expression := m.expression(value); expression != "" {
+			expressions = append(expressions, expression)
+		}
+	}
+
+	hits := make(map[uint]FullTextHit)
+	if len(expressions) == 0 || len(ids) == 0 {
+		return hits, nil
+	}
+
+	expression := strings.Join(expressions, " ")
+
+	// mysql has no highlighting, so build the snippet here
+	var words []string
+	for _, value := range values {
+		words = append(words, fullTextWords(value)...)
+	}
+
+	for batch := range slices.Chunk(ids, fullTextRankBatchSize) {
+		var rows []struct {
+			ID       uint
+			Rank     float64
+			Document string
+		}
+		if err := db.Raw(`SELECT result_id AS id,
+			MATCH(title, body, headers, console) AGAINST (? IN BOOLEAN MODE) AS `+"`rank`"+`,
+			CONCAT_WS(' ', title, body, headers, console) AS document
+			FROM `+fullTextTable+`
+			WHERE MATCH(title, body, headers, console) AGAINST (? IN BOOLEAN MODE) AND result_id IN ?`,
+			expression, expression, batch).
+			Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			hits[row.ID] = FullTextHit{Rank: row.Rank, Snippet: highlight(snippet(row.Document, words, 80))}
+		}
+	}
+
+	return hits, nil
+}
+
+// snippet returns the text surrounding the first match of any of the words
+// in document, with matches wrapped in highlight markers
+func snippet(document string, words []string, context int) string {
+	if len(words) == 0 {
+		return ""
+	}
+
+	var patterns []string
+	for _, word := range words {
+		patterns = append(patterns, regexp.QuoteMeta(word))
+	}
+	matcher := regexp.MustCompile(`(?i)(` + strings.Join(patterns, "|") + `)`)
+
+	location := matcher.FindStringIndex(document)
+	if location == nil {
+		return ""
+	}
+
+	start, end := max(location[0]-context, 0), min(location[1]+context, len(document))
+	// don't cut a multi byte character in half
+	for start > 0 && !isRuneStart(document[start]) {
+		start--
+	}
+	for end < len(document) && !isRuneStart(document[end]) {
+		end++
+	}
+
+	window := strings.Join(strings.Fields(document[start:end]), " ")
+	window = matcher.ReplaceAllString(window, highlightStart+"$1"+highlightStop)
+	if start > 0 {
+		window = "…" + window
+	}
+	if end < len(document) {
+		window += "…"
+	}
+
+	return window
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
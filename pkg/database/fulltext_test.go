@@ -0,0 +1,99 @@
+package database
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/sensepost/gowitness/internal/testdb"
+	"github.com/sensepost/gowitness/pkg/models"
+)
+
+func TestRankBatches(t *testing.T) {
+	_, conn := testdb.Open(t, Connection)
+	index := FullText(conn)
+	if index == nil {
+		t.Fatal("FullText() = nil, want the sqlite index")
+	}
+
+	// more results than fit in one batch
+	var results []*models.Result
+	for i := range fullTextRankBatchSize + 10 {
+		results = append(results, &models.Result{
+			URL:   fmt.Sprintf("https://%d.example.com", i),
+			Title: "welcome to nginx",
+		})
+	}
+	if err := conn.CreateInBatches(results, 100).Error; err != nil {
+		t.Fatalf("could not create test results: %v", err)
+	}
+	if err := index.Index(conn, results...); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+
+	var ids []uint
+	for _, result := range results {
+		ids = append(ids, result.ID)
+	}
+
+	hits, err := index.Rank(conn, []string{"nginx"}, ids)
+	if err != nil {
+		t.Fatalf("Rank() error = %v", err)
+	}
+	if len(hits) != len(ids) {
+		t.Errorf("Rank() returned %d hits, want %d", len(hits), len(ids))
+	}
+	if hit := hits[ids[len(ids)-1]]; hit.Snippet == "" {
+		t.Errorf("Rank() hit for the last result has no snippet")
+	}
+}
+
+func TestBackfillResumes(t *testing.T) {
+	_, conn := testdb.Open(t, Connection)
+
+	// results written without indexing them, with a backfill that stopped
+	// after the first one
+	results := []*models.Result{
+		{URL: "https://a.example.com", Title: "indexed already"},
+		{URL: "https://b.example.com", Title: "welcome to nginx"},
+		{URL: "https://c.example.com", Title: "welcome to nginx"},
+	}
+	if err := conn.Create(results).Error; err != nil {
+		t.Fatalf("could not create test results: %v", err)
+	}
+	if err := setBackfillProgress(conn, fmt.Sprint(results[0].ID)); err != nil {
+		t.Fatalf("setBackfillProgress() error = %v", err)
+	}
+
+	// searches don't use an index that is missing results
+	if FullTextReady(conn) != nil {
+		t.Fatal("FullTextReady() returned an index that is missing results")
+	}
+
+	if err := BackfillFullText(conn); err != nil {
+		t.Fatalf("BackfillFullText() error = %v", err)
+	}
+
+	index := FullTextReady(conn)
+	if index == nil {
+		t.Fatal("FullTextReady() = nil after a backfill")
+	}
+
+	condition, vars := index.Match("nginx", false)
+	var got []string
+	if err := conn.Model(&models.Result{}).Where(condition, vars...).Order("id").
+		Pluck("url", &got).Error; err != nil {
+		t.Fatalf("could not match results: %v", err)
+	}
+	if want := []string{"https://b.example.com", "https://c.example.com"}; !slices.Equal(got, want) {
+		t.Errorf("matched %v, want %v", got, want)
+	}
+}
+
+func TestFullTextReadyEmpty(t *testing.T) {
+	// an index made along with the database has nothing to backfill
+	_, conn := testdb.Open(t, Connection)
+	if FullTextReady(conn) == nil {
+		t.Error("FullTextReady() = nil for a new database")
+	}
+}
@@ -0,0 +1,180 @@
+package discovery
+
+import (
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/sensepost/gowitness/pkg/models"
+)
+
+// Sources a host can be discovered from
+const (
+	ViaRedirect = "redirect"
+	ViaLink     = "link"
+	ViaNetwork  = "network"
+	ViaCSP      = "csp"
+	ViaSAN      = "san"
+)
+
+var hostRegex = regexp.MustCompile(`^[a-z0-9_]([a-z0-9_.-]*[a-z0-9_])?$`)
+
+// Reference is a host a result refers to
+type Reference struct {
+	// URL is the root of the host, like https://api.example.com:8443
+	URL string
+	// Host is the hostname or IP address, without a port
+	Host string
+	// Via is how the host was found, like link or san
+	Via string
+}
+
+// References returns the hosts a result refers to through its redirect,
+// links, network requests, Content-Security-Policy headers and TLS
+// certificate names. Hosts without a scheme of their own use the result's.
+// Wildcards can't be scanned and are skipped. The result's own host is
+// never returned.
+func References(result *models.Result) []Reference {
+	origin, err := url.Parse(result.URL)
+	if err != nil {
+		return nil
+	}
+
+	r := &references{origin: origin, seen: map[string]bool{rootURL(origin): true}}
+
+	if result.FinalURL != "" {
+		r.addURL(result.FinalURL, ViaRedirect)
+	}
+	for _, link := range result.Links {
+		r.addURL(link.URL, ViaLink)
+	}
+	for _, entry := range result.Network {
+		r.addURL(entry.URL, ViaNetwork)
+	}
+	for _, header := range result.Headers {
+		if strings.EqualFold(header.Key, "Content-Security-Policy") ||
+			strings.EqualFold(header.Key, "Content-Security-Policy-Report-Only") {
+			r.addCSP(header.Value)
+		}
+	}
+	for _, san := range result.TLS.SanList {
+		r.addHost(san.Value, ViaSAN)
+	}
+
+	return r.found
+}
+
+// references collects the unique hosts of a result
+type references struct {
+	origin *url.URL
+	seen   map[string]bool
+	found  []Reference
+}
+
+// addURL adds the host of an absolute URL. Websockets are scanned over
+// http(s).
+func (r *references) addURL(raw string, via string) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return
+	}
+
+	switch u.Scheme {
+	case "ws":
+		u.Scheme = "http"
+	case "wss":
+		u.Scheme = "https"
+	case "http", "https":
+	default:
+		return
+	}
+
+	r.add(u, via)
+}
+
+// addCSP adds the hosts in a Content-Security-Policy's source lists
+func (r *references) addCSP(policy string) {
+	for _, directive := range strings.Split(policy, ";") {
+		fields := strings.Fields(directive)
+		if len(fields) < 2 {
+			continue
+		}
+
+		for _, source := range fields[1:] {
+			// keywords like 'self', scheme sources like data: and wildcards
+			if strings.HasPrefix(source, "'") || strings.HasSuffix(source, ":") || strings.Contains(source, "*") {
+				continue
+			}
+
+			if strings.Contains(source, "://") {
+				r.addURL(source, ViaCSP)
+				continue
+			}
+			r.addURL(r.origin.Scheme+"://"+source, ViaCSP)
+		}
+	}
+}
+
+// addHost adds a bare hostname, like a certificate's subject alternative
+// name, on the result's scheme and port
+func (r *references) addHost(host string, via string) {
+	host = strings.TrimSpace(host)
+	if strings.Contains(host, "*") {
+		return
+	}
+
+	u := &url.URL{Scheme: r.origin.Scheme, Host: host}
+	if ip := net.ParseIP(host); ip != nil && ip.To4() == nil {
+		u.Host = "[" + host + "]"
+	}
+	if port := r.origin.Port(); port != "" {
+		u.Host = net.JoinHostPort(strings.Trim(u.Host, "[]"), port)
+	}
+
+	r.add(u, via)
+}
+
+func (r *references) add(u *url.URL, via string) {
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" || (net.ParseIP(host) == nil && !hostRegex.MatchString(host)) {
+		return
+	}
+
+	root := rootURL(u)
+	if r.seen[root] {
+		return
+	}
+	r.seen[root] = true
+
+	r.found = append(r.found, Reference{URL: root, Host: host, Via: via})
+}
+
+// rootURL returns scheme://host[:port] for a URL, leaving out default ports
+func rootURL(u *url.URL) string {
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	if port != "" {
+		host += ":" + port
+	}
+
+	return u.Scheme + "://" + host
+}
+
+// Root returns the scheme://host[:port] of a target URL, leaving out
+// default ports, so that targets can be compared with references
+func Root(target string) (string, bool) {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return "", false
+	}
+
+	return rootURL(u), true
+}
@@ -0,0 +1,46 @@
+package discovery
+
+import (
+	"testing"
+
+	"github.com/sensepost/gowitness/pkg/models"
+)
+
+func TestReferences(t *testing.T) {
+	result := &models.Result{
+		URL:      "https://www.example.com:8443/",
+		FinalURL: "https://login.example.com/sso",
+		Links: []models.Link{
+			{URL: "https://www.example.com:8443/about"},
+			{URL: "http://blog.example.com/post"},
+			{URL: "https://login.example.com/"},
+		},
+		Network: []models.NetworkLog{
+			{URL: "wss://ws.example.com/socket"},
+			{URL: "data:image/png;base64,AAAA"},
+		},
+		Headers: []models.Header{
+			{Key: "content-security-policy", Value: "default-src 'self' data: *.cdn.example.com; connect-src api.example.com https://10.1.2.3:9000/v1"},
+		},
+		TLS: models.TLS{SanList: []models.TLSSanList{{Value: "www.example.com"}, {Value: "*.example.com"}, {Value: "admin.example.com"}}},
+	}
+
+	want := []Reference{
+		{URL: "https://login.example.com", Host: "login.example.com", Via: ViaRedirect},
+		{URL: "http://blog.example.com", Host: "blog.example.com", Via: ViaLink},
+		{URL: "https://ws.example.com", Host: "ws.example.com", Via: ViaNetwork},
+		{URL: "https://api.example.com", Host: "api.example.com", Via: ViaCSP},
+		{URL: "https://10.1.2.3:9000", Host: "10.1.2.3", Via: ViaCSP},
+		{URL: "https://admin.example.com:8443", Host: "admin.example.com", Via: ViaSAN},
+	}
+
+	got := References(result)
+	if len(got) != len(want) {
+		t.Fatalf("References() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("References()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
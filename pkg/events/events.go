@@ -0,0 +1,116 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sensepost/gowitness/pkg/models"
+)
+
+// Event types that can be published on a Hub
+const (
+	// ResultEvent is published when a new result was written
+	ResultEvent = "result"
+	// JobEvent is published as API submitted jobs make progress
+	JobEvent = "job"
+	// DeleteEvent is published when results are removed
+	DeleteEvent = "delete"
+	// AlertEvent is published when a new result matches a saved search
+	// with an events alert rule
+	AlertEvent = "alert"
+)
+
+// subscriberBuffer is the number of events a subscriber may lag behind
+// before new events are dropped for it.
+const subscriberBuffer = 64
+
+// Event is a message published on a Hub
+type Event struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// Result is the summary of a result that is published with a ResultEvent
+type Result struct {
+	ID           uint      `json:"id"`
+	ProbedAt     time.Time `json:"probed_at"`
+	URL          string    `json:"url"`
+	ResponseCode int       `json:"response_code"`
+	Title        string    `json:"title"`
+	Filename     string    `json:"file_name"`
+	Failed       bool      `json:"failed"`
+}
+
+// Job is the progress of a scanning job published with a JobEvent
+type Job struct {
+	ID       string `json:"id"`
+	Total    int    `json:"total"`
+	Done     int64  `json:"done"`
+	Finished bool   `json:"finished"`
+}
+
+// Delete holds the result ids that were removed for a DeleteEvent
+type Delete struct {
+	IDs []uint `json:"ids"`
+}
+
+// NewResult returns a Result event summary for a models.Result
+func NewResult(result *models.Result) *Result {
+	return &Result{
+		ID:           result.ID,
+		ProbedAt:     result.ProbedAt,
+		URL:          result.URL,
+		ResponseCode: result.ResponseCode,
+		Title:        result.Title,
+		Filename:     result.Filename,
+		Failed:       result.Failed,
+	}
+}
+
+// Hub is a simple, in-process publish / subscribe hub
+type Hub struct {
+	mutex       sync.RWMutex
+	subscribers map[chan Event]struct{}
+}
+
+// NewHub returns a new Hub
+func NewHub() *Hub {
+	return &Hub{
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+// Subscribe returns a channel that receives published events, as well as
+// a function that should be called to unsubscribe.
+func (h *Hub) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	h.mutex.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.mutex.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mutex.Lock()
+			delete(h.subscribers, ch)
+			h.mutex.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish sends an event to all subscribers. Subscribers that are not
+// keeping up will miss the event rather than block the publisher.
+func (h *Hub) Publish(eventType string, data interface{}) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	event := Event{Type: eventType, Data: data}
+	for ch := range h.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package events
+
+import "testing"
+
+func TestHub(t *testing.T) {
+	hub := NewHub()
+
+	first, unsubscribeFirst := hub.Subscribe()
+	second, unsubscribeSecond := hub.Subscribe()
+	defer unsubscribeSecond()
+
+	hub.Publish(DeleteEvent, Delete{IDs: []uint{1}})
+
+	for _, ch := range []<-chan Event{first, second} {
+		event := <-ch
+		if event.Type != DeleteEvent {
+			t.Errorf("event type = %q, want %q", event.Type, DeleteEvent)
+		}
+	}
+
+	// unsubscribing closes the channel and may be done more than once
+	unsubscribeFirst()
+	unsubscribeFirst()
+	if _, ok := <-first; ok {
+		t.Error("channel of an unsubscribed subscriber is still open")
+	}
+
+	hub.Publish(DeleteEvent, Delete{IDs: []uint{2}})
+	if event := <-second; event.Data.(Delete).IDs[0] != 2 {
+		t.Errorf("event data = %v, want ids [2]", event.Data)
+	}
+}
+
+func TestHubSlowSubscriber(t *testing.T) {
+	hub := NewHub()
+
+	ch, unsubscribe := hub.Subscribe()
+	defer unsubscribe()
+
+	// a subscriber that doesn't read misses events instead of blocking
+	for i := range subscriberBuffer + 10 {
+		hub.Publish(JobEvent, Job{Done: int64(i)})
+	}
+
+	if len(ch) != subscriberBuffer {
+		t.Errorf("%d events buffered, want %d", len(ch), subscriberBuffer)
+	}
+	if event := <-ch; event.Data.(Job).Done != 0 {
+		t.Errorf("first buffered event = %v, want the first published", event.Data)
+	}
+}
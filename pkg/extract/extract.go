@@ -0,0 +1,276 @@
+package extract
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/sensepost/gowitness/pkg/models"
+	xhtml "golang.org/x/net/html"
+)
+
+// limits on what is kept from a single page, so that huge pages don't
+// bloat the database
+const (
+	maxLinks      = 1000
+	maxForms      = 50
+	maxFormInputs = 100
+	maxScripts    = 200
+	maxMetaTags   = 100
+	maxTextLength = 256
+)
+
+// Elements are the links, forms, scripts and meta tags of a page
+type Elements struct {
+	Links    []models.Link
+	Forms    []models.Form
+	Scripts  []models.Script
+	MetaTags []models.MetaTag
+}
+
+// extractor walks a parsed document, collecting its elements
+type extractor struct {
+	// host is the page's host, base is what its URLs are resolved against
+	host     string
+	base     *url.URL
+	elements Elements
+
+	links   map[string]bool
+	scripts map[string]bool
+	forms   []*models.Form
+	// formless collects the inputs that are not in a form, as scripted
+	// login pages often don't use one
+	formless *models.Form
+}
+
+// Parse extracts the elements of an html document. Relative URLs are
+// resolved against pageURL, or the document's <base> if it has one. Only
+// http and https links and scripts are kept. Inputs outside of a form are
+// returned in a form without an action or method.
+func Parse(pageURL string, document string) (*Elements, error) {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := xhtml.Parse(strings.NewReader(document))
+	if err != nil {
+		return nil, err
+	}
+
+	e := &extractor{
+		host:    base.Host,
+		base:    base,
+		links:   make(map[string]bool),
+		scripts: make(map[string]bool),
+	}
+	e.walk(root, nil)
+
+	for _, form := range e.forms {
+		e.elements.Forms = append(e.elements.Forms, *form)
+	}
+
+	return &e.elements, nil
+}
+
+// walk visits a node and its children. form is the form the node is in,
+// if any.
+func (e *extractor) walk(node *xhtml.Node, form *models.Form) {
+	if node.Type == xhtml.ElementNode {
+		switch node.Data {
+		case "base":
+			e.setBase(attr(node, "href"))
+		case "a", "area":
+			e.addLink(node)
+		case "form":
+			form = e.addForm(node)
+		case "input", "select", "textarea", "button":
+			e.addFormInput(node, form)
+		case "script":
+			e.addScript(node)
+		case "meta":
+			e.addMetaTag(node)
+		}
+	}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		e.walk(child, form)
+	}
+}
+
+func (e *extractor) setBase(href string) {
+	if href == "" {
+		return
+	}
+	if base, err := e.base.Parse(href); err == nil {
+		e.base = base
+	}
+}
+
+func (e *extractor) addLink(node *xhtml.Node) {
+	target, ok := e.resolve(attr(node, "href"))
+	if !ok || e.links[target.String()] || len(e.elements.Links) >= maxLinks {
+		return
+	}
+
+	e.links[target.String()] = true
+	e.elements.Links = append(e.elements.Links, models.Link{
+		URL:      target.String(),
+		Text:     truncate(text(node)),
+		External: !strings.EqualFold(target.Host, e.host),
+	})
+}
+
+func (e *extractor) addForm(node *xhtml.Node) *models.Form {
+	if len(e.forms) >= maxForms {
+		return nil
+	}
+
+	// an empty action submits to the page itself
+	action := e.base.String()
+	if href := strings.TrimSpace(attr(node, "action")); href != "" {
+		if target, err := e.base.Parse(href); err == nil {
+			action = target.String()
+		}
+	}
+
+	method := strings.ToUpper(strings.TrimSpace(attr(node, "method")))
+	if method == "" {
+		method = "GET"
+	}
+
+	form := &models.Form{Action: action, Method: method}
+	e.forms = append(e.forms, form)
+
+	return form
+}
+
+func (e *extractor) addFormInput(node *xhtml.Node, form *models.Form) {
+	if form == nil {
+		// buttons outside of forms are everywhere, and not interesting
+		if node.Data == "button" {
+			return
+		}
+		if e.formless == nil {
+			if len(e.forms) >= maxForms {
+				return
+			}
+			e.formless = &models.Form{}
+			e.forms = append(e.forms, e.formless)
+		}
+		form = e.formless
+	}
+	if len(form.Inputs) >= maxFormInputs {
+		return
+	}
+
+	inputType := strings.ToLower(strings.TrimSpace(attr(node, "type")))
+	switch {
+	case node.Data == "select" || node.Data == "textarea":
+		inputType = node.Data
+	case inputType == "" && node.Data == "button":
+		inputType = "submit"
+	case inputType == "":
+		inputType = "text"
+	}
+
+	switch inputType {
+	case "password":
+		form.HasPassword = true
+	case "file":
+		form.HasFile = true
+	}
+
+	form.Inputs = append(form.Inputs, models.FormInput{
+		Name:  attr(node, "name"),
+		Type:  inputType,
+		Value: truncate(attr(node, "value")),
+	})
+}
+
+func (e *extractor) addScript(node *xhtml.Node) {
+	src, ok := e.resolve(attr(node, "src"))
+	if !ok || e.scripts[src.String()] || len(e.elements.Scripts) >= maxScripts {
+		return
+	}
+
+	e.scripts[src.String()] = true
+	e.elements.Scripts = append(e.elements.Scripts, models.Script{Src: src.String()})
+}
+
+func (e *extractor) addMetaTag(node *xhtml.Node) {
+	if len(e.elements.MetaTags) >= maxMetaTags {
+		return
+	}
+
+	var name string
+	for _, key := range []string{"name", "property", "http-equiv"} {
+		if name = strings.TrimSpace(attr(node, key)); name != "" {
+			break
+		}
+	}
+	if name == "" {
+		return
+	}
+
+	e.elements.MetaTags = append(e.elements.MetaTags, models.MetaTag{
+		Name:    strings.ToLower(name),
+		Content: attr(node, "content"),
+	})
+}
+
+// resolve resolves an href against the base URL, returning false for
+// empty, fragment only and non http(s) references
+func (e *extractor) resolve(href string) (*url.URL, bool) {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") {
+		return nil, false
+	}
+
+	target, err := e.base.Parse(href)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+		return nil, false
+	}
+	target.Fragment = ""
+
+	return target, true
+}
+
+// attr returns the value of a node's attribute
+func attr(node *xhtml.Node, key string) string {
+	for _, a := range node.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+
+	return ""
+}
+
+// text returns the text in a node, with whitespace collapsed
+func text(node *xhtml.Node) string {
+	var b strings.Builder
+
+	var collect func(*xhtml.Node)
+	collect = func(n *xhtml.Node) {
+		if n.Type == xhtml.TextNode {
+			b.WriteString(n.Data)
+			b.WriteString(" ")
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			collect(child)
+		}
+	}
+	collect(node)
+
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// truncate limits a value to maxTextLength runes
+func truncate(value string) string {
+	runes := []rune(value)
+	if len(runes) <= maxTextLength {
+		return value
+	}
+
+	return string(runes[:maxTextLength])
+}
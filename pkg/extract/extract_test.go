@@ -0,0 +1,78 @@
+package extract
+
+import (
+	"testing"
+)
+
+const page = `<html><head>
+<base href="https://example.com/app/">
+<meta name="Generator" content="WordPress 6.4">
+<meta property="og:title" content="Example">
+<meta charset="utf-8">
+<script src="js/jquery-1.12.4.min.js"></script>
+<script src="js/jquery-1.12.4.min.js"></script>
+<script>console.log("inline")</script>
+</head><body>
+<a href="/about">About <b>us</b></a>
+<a href="https://other.example.org/page#section">Other</a>
+<a href="#top">Top</a>
+<a href="javascript:void(0)">Nothing</a>
+<a href="mailto:admin@example.com">Mail</a>
+<form action="login.php" method="post">
+  <input name="user">
+  <input name="pass" type="Password">
+  <input type="hidden" name="csrf" value="abc">
+  <button>Log in</button>
+</form>
+<form enctype="multipart/form-data"><input type="file" name="upload"><select name="kind"></select></form>
+<input type="password" name="pin"><button>Go</button>
+</body></html>`
+
+func TestParse(t *testing.T) {
+	elements, err := Parse("http://example.com/index.html", page)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if len(elements.Links) != 2 {
+		t.Fatalf("Parse() links = %+v, want 2 http links", elements.Links)
+	}
+	if link := elements.Links[0]; link.URL != "https://example.com/about" || link.Text != "About us" || link.External {
+		t.Errorf("first link = %+v, want the internal about link", link)
+	}
+	if link := elements.Links[1]; link.URL != "https://other.example.org/page" || !link.External {
+		t.Errorf("second link = %+v, want the external link without a fragment", link)
+	}
+
+	if len(elements.Scripts) != 1 || elements.Scripts[0].Src != "https://example.com/app/js/jquery-1.12.4.min.js" {
+		t.Errorf("Parse() scripts = %+v, want the one resolved script", elements.Scripts)
+	}
+
+	if len(elements.MetaTags) != 2 || elements.MetaTags[0].Name != "generator" || elements.MetaTags[1].Name != "og:title" {
+		t.Errorf("Parse() meta tags = %+v, want generator and og:title", elements.MetaTags)
+	}
+
+	if len(elements.Forms) != 3 {
+		t.Fatalf("Parse() forms = %+v, want 3", elements.Forms)
+	}
+
+	login := elements.Forms[0]
+	if login.Action != "https://example.com/app/login.php" || login.Method != "POST" || !login.HasPassword || login.HasFile {
+		t.Errorf("login form = %+v, want a POST password form", login)
+	}
+	if len(login.Inputs) != 4 || login.Inputs[0].Type != "text" || login.Inputs[1].Type != "password" ||
+		login.Inputs[2].Value != "abc" || login.Inputs[3].Type != "submit" {
+		t.Errorf("login form inputs = %+v", login.Inputs)
+	}
+
+	upload := elements.Forms[1]
+	if upload.Action != "https://example.com/app/" || upload.Method != "GET" || !upload.HasFile ||
+		len(upload.Inputs) != 2 || upload.Inputs[1].Type != "select" {
+		t.Errorf("upload form = %+v, want a GET file upload form", upload)
+	}
+
+	formless := elements.Forms[2]
+	if formless.Action != "" || formless.Method != "" || !formless.HasPassword || len(formless.Inputs) != 1 {
+		t.Errorf("formless inputs = %+v, want the password input without its button", formless)
+	}
+}
@@ -0,0 +1,148 @@
+package favicon
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sensepost/gowitness/pkg/httpclient"
+	"github.com/sensepost/gowitness/pkg/models"
+	"github.com/sensepost/gowitness/pkg/resolver"
+)
+
+// Script is evaluated on a page to find the URL of its icon. It prefers
+// the icon the page declares, falling back to /favicon.ico. Letting the
+// browser resolve it means <base> and relative links are handled.
+const Script = `() => {
+	const link = document.querySelector('link[rel~="icon" i], link[rel="apple-touch-icon" i]');
+	if (link && link.href) return link.href;
+	return new URL('/favicon.ico', location.href).href;
+}`
+
+// MaxSize is the largest favicon that will be stored
+const MaxSize = 1 << 20
+
+// Fetcher downloads favicons
+type Fetcher struct {
+	client *httpclient.Client
+}
+
+// NewFetcher returns a Fetcher that requests favicons the way the browser
+// would (see httpclient.New)
+func NewFetcher(proxy string, userAgent string, headers []string, timeout time.Duration, res *resolver.Resolver) (*Fetcher, error) {
+	client, err := httpclient.New(proxy, userAgent, headers, timeout, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Fetcher{client: client}, nil
+}
+
+// Fetch downloads the favicon at iconURL, which may also be a data URL
+func (f *Fetcher) Fetch(ctx context.Context, iconURL string) (*models.Favicon, error) {
+	if strings.HasPrefix(iconURL, "data:") {
+		mimeType, content, err := decodeDataURL(iconURL)
+		if err != nil {
+			return nil, err
+		}
+		return New(iconURL, mimeType, content)
+	}
+
+	res, err := f.client.Get(ctx, iconURL)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	// servers that don't have a favicon.ico often serve an html error
+	// page with a 200 instead
+	mimeType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if mimeType == "text/html" {
+		return nil, errors.New("favicon is an html page")
+	}
+
+	content, err := io.ReadAll(io.LimitReader(res.Body, MaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > MaxSize {
+		return nil, fmt.Errorf("favicon is larger than %d bytes", MaxSize)
+	}
+
+	return New(iconURL, mimeType, content)
+}
+
+// New returns a favicon with its hashes calculated
+func New(iconURL string, mimeType string, content []byte) (*models.Favicon, error) {
+	if len(content) == 0 {
+		return nil, errors.New("favicon is empty")
+	}
+
+	return &models.Favicon{
+		URL:      iconURL,
+		MIMEType: mimeType,
+		Content:  content,
+		MMH3:     MMH3(content),
+		MD5:      MD5(content),
+	}, nil
+}
+
+// MMH3 is the favicon hash Shodan uses: the murmur3 hash of the content
+// base64 encoded with a newline every 76 characters, as a signed int.
+func MMH3(content []byte) int32 {
+	encoded := base64.StdEncoding.EncodeToString(content)
+
+	var wrapped strings.Builder
+	for len(encoded) > 76 {
+		wrapped.WriteString(encoded[:76])
+		wrapped.WriteByte('\n')
+		encoded = encoded[76:]
+	}
+	wrapped.WriteString(encoded)
+	wrapped.WriteByte('\n')
+
+	return int32(murmur3([]byte(wrapped.String()), 0))
+}
+
+// MD5 is the hex encoded MD5 of the content
+func MD5(content []byte) string {
+	sum := md5.Sum(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// decodeDataURL decodes a data: URL, returning its MIME type and content
+func decodeDataURL(dataURL string) (string, []byte, error) {
+	header, data, ok := strings.Cut(strings.TrimPrefix(dataURL, "data:"), ",")
+	if !ok {
+		return "", nil, errors.New("invalid data url")
+	}
+
+	mimeType, isBase64 := strings.CutSuffix(header, ";base64")
+	if !isBase64 {
+		decoded, err := url.PathUnescape(data)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid data url: %w", err)
+		}
+		return mimeType, []byte(decoded), nil
+	}
+
+	content, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid data url: %w", err)
+	}
+
+	return mimeType, content, nil
+}
@@ -0,0 +1,61 @@
+package favicon
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMurmur3(t *testing.T) {
+	tests := []struct {
+		data string
+		seed uint32
+		want uint32
+	}{
+		{"", 0, 0},
+		{"", 1, 0x514e28b7},
+		{"hello", 0, 0x248bfa47},
+		{"The quick brown fox jumps over the lazy dog", 0, 0x2e4ff723},
+	}
+
+	for _, tt := range tests {
+		if got := murmur3([]byte(tt.data), tt.seed); got != tt.want {
+			t.Errorf("murmur3(%q, %d) = %#x, want %#x", tt.data, tt.seed, got, tt.want)
+		}
+	}
+}
+
+func TestMMH3(t *testing.T) {
+	// 100 bytes encode to 136 base64 characters, wrapped over two lines
+	content := bytes.Repeat([]byte{0xff}, 100)
+	encoded := "////////////////////////////////////////////////////////////////////////////\n" +
+		"/////////////////////////////////////////////////////////w==\n"
+
+	if got, want := MMH3(content), int32(murmur3([]byte(encoded), 0)); got != want {
+		t.Errorf("MMH3() = %d, want %d", got, want)
+	}
+}
+
+func TestDecodeDataURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		mimeType string
+		content  string
+		wantErr  bool
+	}{
+		{"data:image/png;base64,aGVsbG8=", "image/png", "hello", false},
+		{"data:image/svg+xml,%3Csvg%3E", "image/svg+xml", "<svg>", false},
+		{"data:image/png;base64,!!", "", "", true},
+		{"data:nocomma", "", "", true},
+	}
+
+	for _, tt := range tests {
+		mimeType, content, err := decodeDataURL(tt.url)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("decodeDataURL(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			continue
+		}
+		if mimeType != tt.mimeType || string(content) != tt.content {
+			t.Errorf("decodeDataURL(%q) = %q, %q, want %q, %q", tt.url, mimeType, content, tt.mimeType, tt.content)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Package httpclient builds the HTTP client for requests gowitness makes
+// next to the browser, so that they go out the way the browser's do
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sensepost/gowitness/pkg/proxies"
+	"github.com/sensepost/gowitness/pkg/resolver"
+)
+
+// maxRedirects is the number of redirects a request follows, like the
+// default of net/http
+const maxRedirects = 10
+
+// urlCheckKey is the context key of the check WithURLCheck adds
+type urlCheckKey struct{}
+
+// WithURLCheck returns a context whose requests, and each of the redirects
+// they follow, are only sent to URLs check allows. Scans use it to keep
+// requests in scope.
+func WithURLCheck(ctx context.Context, check func(target string) error) context.Context {
+	return context.WithValue(ctx, urlCheckKey{}, check)
+}
+
+// checkURL runs the URL check of a context, if it has one
+func checkURL(ctx context.Context, target string) error {
+	if check, ok := ctx.Value(urlCheckKey{}).(func(string) error); ok {
+		return check(target)
+	}
+
+	return nil
+}
+
+// checkRedirect stops redirects to URLs the request's check does not
+// allow
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	if err := checkURL(req.Context(), req.URL.String()); err != nil {
+		return fmt.Errorf("not following redirect to %s: %w", req.URL, err)
+	}
+
+	return nil
+}
+
+// Client makes the requests gowitness sends alongside the browser, such
+// as for favicons and soft 404 probes, looking like the browser to targets
+type Client struct {
+	// HTTP is the underlying client, for callers to tune (e.g. redirects)
+	HTTP *http.Client
+
+	userAgent string
+	headers   http.Header
+}
+
+// New returns a Client that uses the same proxy, user agent, extra headers
+// and resolver as the browser. Headers are "Name: value" strings, and res
+// may be nil to use the system resolver.
+func New(proxy string, userAgent string, headers []string, timeout time.Duration, res *resolver.Resolver) (*Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+
+	if proxy != "" {
+		// chrome accepts a proxy without a scheme, defaulting to http
+		if !strings.Contains(proxy, "://") {
+			proxy = "http://" + proxy
+		}
+		proxyURL, err := url.Parse(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	// targets may go through a proxy of their own
+	transport.Proxy = proxies.ProxyFunc(transport.Proxy)
+
+	client := &Client{
+		HTTP: &http.Client{
+			Transport:     resolver.NewTransport(transport, res),
+			Timeout:       timeout,
+			CheckRedirect: checkRedirect,
+		},
+		userAgent: userAgent,
+		headers:   make(http.Header),
+	}
+
+	for _, header := range headers {
+		kv := strings.SplitN(header, ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		client.headers.Add(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]))
+	}
+
+	return client, nil
+}
+
+// Get requests target with the browser's user agent and extra headers. A
+// URL check on ctx (see WithURLCheck) is applied to target too.
+func (c *Client) Get(ctx context.Context, target string) (*http.Response, error) {
+	if err := checkURL(ctx, target); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = c.headers.Clone()
+	req.Header.Set("User-Agent", c.userAgent)
+
+	return c.HTTP.Do(req)
+}
@@ -0,0 +1,53 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestURLCheck(t *testing.T) {
+	denied := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("a request was sent to a denied url: %s", r.URL)
+	}))
+	defer denied.Close()
+
+	allowed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, denied.URL+"/favicon.ico", http.StatusFound)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer allowed.Close()
+
+	client, err := New("", "gowitness", nil, 5*time.Second, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := WithURLCheck(context.Background(), func(target string) error {
+		if strings.HasPrefix(target, denied.URL) {
+			return errors.New("out of scope")
+		}
+		return nil
+	})
+
+	res, err := client.Get(ctx, allowed.URL+"/favicon.ico")
+	if err != nil {
+		t.Fatalf("Get() of an allowed url error = %v", err)
+	}
+	res.Body.Close()
+
+	if _, err := client.Get(ctx, denied.URL+"/favicon.ico"); err == nil {
+		t.Errorf("Get() of a denied url did not fail")
+	}
+	if _, err := client.Get(ctx, allowed.URL+"/redirect"); err == nil {
+		t.Errorf("Get() followed a redirect to a denied url")
+	}
+}
@@ -0,0 +1,84 @@
+package imagehash
+
+import (
+	"encoding/hex"
+	"fmt"
+	"image"
+	"strings"
+)
+
+// Algorithm is a perceptual hash algorithm
+type Algorithm struct {
+	// Name identifies the algorithm in stored hashes and search queries
+	Name string
+	// Bits is the size of the hashes the algorithm produces
+	Bits int
+	// Hash calculates the hash of an image
+	Hash func(img image.Image) ([]byte, error)
+}
+
+// Threshold is the default distance, in bits, within which two hashes
+// are considered similar. It scales with the size of the hash, matching
+// the 10 bits out of 64 used for perception hash groups.
+func (a *Algorithm) Threshold() int {
+	return a.Bits * 10 / 64
+}
+
+// PHash is the name of the 8x8 DCT hash stored as a result's PerceptionHash
+const PHash = "phash"
+
+var (
+	registry = make(map[string]*Algorithm)
+	ordered  []*Algorithm
+)
+
+// Register makes a hash algorithm available. It panics if an algorithm
+// with the same name is already registered.
+func Register(algorithm *Algorithm) {
+	if _, exists := registry[algorithm.Name]; exists {
+		panic(fmt.Sprintf("imagehash: algorithm %q registered twice", algorithm.Name))
+	}
+
+	registry[algorithm.Name] = algorithm
+	ordered = append(ordered, algorithm)
+}
+
+// Lookup returns the registered algorithm with a name. p is accepted as
+// an alias for phash, as perception hashes are stored as p:<hex>.
+func Lookup(name string) (*Algorithm, bool) {
+	name = strings.ToLower(name)
+	if name == "p" {
+		name = PHash
+	}
+
+	algorithm, ok := registry[name]
+	return algorithm, ok
+}
+
+// Algorithms returns the registered algorithms, in registration order
+func Algorithms() []*Algorithm {
+	return ordered
+}
+
+// Hashes calculates the hash of an image with every registered algorithm,
+// hex encoded and keyed by algorithm name
+func Hashes(img image.Image) (map[string]string, error) {
+	hashes := make(map[string]string, len(ordered))
+	for _, algorithm := range ordered {
+		hash, err := algorithm.Hash(img)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", algorithm.Name, err)
+		}
+		hashes[algorithm.Name] = hex.EncodeToString(hash)
+	}
+
+	return hashes, nil
+}
+
+func init() {
+	Register(&Algorithm{Name: PHash, Bits: 64, Hash: pHash})
+	Register(&Algorithm{Name: "phash16", Bits: 256, Hash: pHash16})
+	Register(&Algorithm{Name: "ahash", Bits: 64, Hash: aHash})
+	Register(&Algorithm{Name: "dhash", Bits: 64, Hash: dHash})
+	Register(&Algorithm{Name: "whash", Bits: 64, Hash: wHash})
+}
@@ -0,0 +1,74 @@
+package imagehash
+
+import (
+	"image"
+)
+
+const (
+	// blankSampleSize is the width and height of the grid of pixels
+	// sampled to decide if an image is blank
+	blankSampleSize = 128
+	// blankTolerance is how far (out of 255) a channel may be from the
+	// background colour and still count as background
+	blankTolerance = 8
+	// blankRatio is the share of sampled pixels that need to be the
+	// background colour for an image to be blank
+	blankRatio = 0.995
+)
+
+// Blank reports whether an image is near uniform, like the screenshot of
+// a blank white page. The most common colour in a grid of sampled pixels
+// is taken as the background, and the image is blank when almost every
+// sampled pixel is within a small tolerance of it.
+func Blank(img image.Image) bool {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return true
+	}
+
+	scaleX := float64(width) / float64(blankSampleSize)
+	scaleY := float64(height) / float64(blankSampleSize)
+
+	samples := make([][3]uint8, 0, blankSampleSize*blankSampleSize)
+	counts := make(map[[3]uint8]int)
+	for y := range blankSampleSize {
+		sy := bounds.Min.Y + sampleCoordinate(scaleY, height, y)
+		for x := range blankSampleSize {
+			sx := bounds.Min.X + sampleCoordinate(scaleX, width, x)
+			r, g, b, _ := img.At(sx, sy).RGBA()
+			pixel := [3]uint8{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8)}
+
+			samples = append(samples, pixel)
+			counts[pixel]++
+		}
+	}
+
+	var background [3]uint8
+	for pixel, count := range counts {
+		if count > counts[background] {
+			background = pixel
+		}
+	}
+
+	matching := 0
+	for _, pixel := range samples {
+		if near(pixel, background) {
+			matching++
+		}
+	}
+
+	return float64(matching)/float64(len(samples)) >= blankRatio
+}
+
+// near checks if every channel of two pixels is within blankTolerance
+func near(a, b [3]uint8) bool {
+	for i := range a {
+		diff := int(a[i]) - int(b[i])
+		if diff < -blankTolerance || diff > blankTolerance {
+			return false
+		}
+	}
+
+	return true
+}
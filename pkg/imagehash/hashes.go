@@ -0,0 +1,171 @@
+package imagehash
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"math"
+
+	"github.com/corona10/goimagehash/etcs"
+	"github.com/corona10/goimagehash/transforms"
+)
+
+// cellSamples is the number of samples, in each direction, averaged into
+// a single downsampled pixel
+const cellSamples = 4
+
+// pHash is the 8x8 DCT hash, as stored in a result's PerceptionHash
+func pHash(img image.Image) ([]byte, error) {
+	hash, err := perceptionHash(img)
+	if err != nil {
+		return nil, err
+	}
+
+	return binary.BigEndian.AppendUint64(nil, hash), nil
+}
+
+// pHash16 is a 16x16 DCT hash. The extra low frequencies make it better
+// at telling apart pages that only differ in small details, like text.
+func pHash16(img image.Image) ([]byte, error) {
+	if err := checkImage(img); err != nil {
+		return nil, err
+	}
+
+	pixels := grayscale(img, sampleSize, sampleSize)
+	transforms.DCT2DFast64(&pixels)
+
+	const size = 16
+	flattened := make([]float64, 0, size*size)
+	for y := range size {
+		flattened = append(flattened, pixels[y*sampleSize:y*sampleSize+size]...)
+	}
+
+	return threshold(flattened, etcs.MedianOfPixels(flattened)), nil
+}
+
+// aHash is the average hash: an 8x8 thumbnail, with each pixel compared
+// to the mean
+func aHash(img image.Image) ([]byte, error) {
+	if err := checkImage(img); err != nil {
+		return nil, err
+	}
+
+	pixels := grayscale(img, 8, 8)
+	return threshold(pixels, etcs.MeanOfPixels(pixels)), nil
+}
+
+// dHash is the difference hash: a 9x8 thumbnail, with each pixel compared
+// to its neighbour on the right. It tracks gradients, so it copes well
+// with changes in brightness and contrast.
+func dHash(img image.Image) ([]byte, error) {
+	if err := checkImage(img); err != nil {
+		return nil, err
+	}
+
+	pixels := grayscale(img, 9, 8)
+
+	bits := make([]bool, 0, 64)
+	for y := range 8 {
+		for x := range 8 {
+			bits = append(bits, pixels[y*9+x] < pixels[y*9+x+1])
+		}
+	}
+
+	return pack(bits), nil
+}
+
+// wHash is the wavelet hash: the approximation (LL) band of a 3 level
+// Haar wavelet transform of a 64x64 thumbnail, compared to its median.
+// Working from a larger thumbnail makes it less sensitive to viewport
+// size than the 8x8 hashes.
+func wHash(img image.Image) ([]byte, error) {
+	if err := checkImage(img); err != nil {
+		return nil, err
+	}
+
+	pixels := grayscale(img, sampleSize, sampleSize)
+	size := sampleSize
+	for size > 8 {
+		pixels, size = haarApproximation(pixels, size), size/2
+	}
+
+	return threshold(pixels, etcs.MedianOfPixels(pixels)), nil
+}
+
+// haarApproximation applies a single level of the 2D Haar wavelet
+// transform to a size x size image, returning the approximation band
+func haarApproximation(pixels []float64, size int) []float64 {
+	half := size / 2
+	band := make([]float64, half*half)
+
+	for y := range half {
+		for x := range half {
+			i := (2*y)*size + 2*x
+			// the orthonormal Haar low pass filter in both directions
+			band[y*half+x] = (pixels[i] + pixels[i+1] + pixels[i+size] + pixels[i+size+1]) / 2
+		}
+	}
+
+	return band
+}
+
+// grayscale downsamples an image to width x height grayscale pixels,
+// averaging a grid of samples from each cell
+func grayscale(img image.Image, width, height int) []float64 {
+	bounds := img.Bounds()
+	cellWidth := float64(bounds.Dx()) / float64(width)
+	cellHeight := float64(bounds.Dy()) / float64(height)
+
+	pixels := make([]float64, width*height)
+	for y := range height {
+		for x := range width {
+			var sum float64
+			for sy := range cellSamples {
+				py := bounds.Min.Y + int(math.Floor((float64(y)+(float64(sy)+0.5)/cellSamples)*cellHeight))
+				for sx := range cellSamples {
+					px := bounds.Min.X + int(math.Floor((float64(x)+(float64(sx)+0.5)/cellSamples)*cellWidth))
+					r, g, b, _ := img.At(px, py).RGBA()
+					sum += toGray(r, g, b)
+				}
+			}
+			pixels[y*width+x] = sum / (cellSamples * cellSamples)
+		}
+	}
+
+	return pixels
+}
+
+// threshold sets a bit for every pixel above a value
+func threshold(pixels []float64, value float64) []byte {
+	bits := make([]bool, len(pixels))
+	for i, p := range pixels {
+		bits[i] = p > value
+	}
+
+	return pack(bits)
+}
+
+// pack packs bits into bytes, most significant bit first
+func pack(bits []bool) []byte {
+	packed := make([]byte, (len(bits)+7)/8)
+	for i, bit := range bits {
+		if bit {
+			packed[i/8] |= 1 << (7 - i%8)
+		}
+	}
+
+	return packed
+}
+
+func checkImage(img image.Image) error {
+	if img == nil {
+		return errors.New("image is nil")
+	}
+
+	bounds := img.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 {
+		return errors.New("image has invalid bounds")
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package imagehash
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// halves is black on the left half, and white on the right
+func halves() image.Image {
+	img := image.NewGray(image.Rect(0, 0, 72, 64))
+	for y := range 64 {
+		for x := 36; x < 72; x++ {
+			img.SetGray(x, y, color.Gray{Y: 255})
+		}
+	}
+
+	return img
+}
+
+// gradient gets brighter towards the bottom right
+func gradient() image.Image {
+	img := image.NewGray(image.Rect(0, 0, 256, 256))
+	for y := range 256 {
+		for x := range 256 {
+			img.SetGray(x, y, color.Gray{Y: uint8((x + y) / 2)})
+		}
+	}
+
+	return img
+}
+
+// uniform is a single colour, with an optional square of another colour
+// in the middle
+func uniform(background color.Gray, dot int) image.Image {
+	img := image.NewGray(image.Rect(0, 0, 1280, 800))
+	for y := range 800 {
+		for x := range 1280 {
+			img.SetGray(x, y, background)
+		}
+	}
+	for y := 400; y < 400+dot; y++ {
+		for x := 640; x < 640+dot; x++ {
+			img.SetGray(x, y, color.Gray{Y: 255 - background.Y})
+		}
+	}
+
+	return img
+}
+
+func TestHashes(t *testing.T) {
+	tests := []struct {
+		name  string
+		image image.Image
+		want  map[string]string
+	}{
+		{
+			// each row is the same step, so the 8x8 hashes set the right
+			// four bits of every row, dhash the two columns across the
+			// step, and the DCT hashes the DC and odd horizontal terms
+			name:  "halves",
+			image: halves(),
+			want: map[string]string{
+				"phash":   "9100000000000000",
+				"phash16": "9111000000000000000000000000000000000000000000000000000000000000",
+				"ahash":   "0f0f0f0f0f0f0f0f",
+				"dhash":   "1818181818181818",
+				"whash":   "0f0f0f0f0f0f0f0f",
+			},
+		},
+		{
+			name:  "gradient",
+			image: gradient(),
+			want: map[string]string{
+				"phash":   "8a7ee043f813f615",
+				"phash16": "8220340243355264251712757402615716342503467565072543033531434774",
+				"ahash":   "000103070f1f3f7f",
+				"dhash":   "ffffffffffffffff",
+				"whash":   "000103070f1f3f7f",
+			},
+		},
+	}
+	for _, tt := range tests {
+		got, err := Hashes(tt.image)
+		if err != nil {
+			t.Fatalf("Hashes(%s) error = %v", tt.name, err)
+		}
+
+		for algorithm, want := range tt.want {
+			if got[algorithm] != want {
+				t.Errorf("Hashes(%s)[%s] = %s, want %s", tt.name, algorithm, got[algorithm], want)
+			}
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("Hashes(%s) returned %d hashes, want %d", tt.name, len(got), len(tt.want))
+		}
+	}
+}
+
+func TestPerceptionHash(t *testing.T) {
+	// the stored perception hash is the phash algorithm's
+	got, err := PerceptionHash(gradient())
+	if err != nil {
+		t.Fatalf("PerceptionHash() error = %v", err)
+	}
+	if want := "p:8a7ee043f813f615"; got != want {
+		t.Errorf("PerceptionHash() = %s, want %s", got, want)
+	}
+}
+
+func TestHashesInvalidImage(t *testing.T) {
+	if _, err := Hashes(image.NewGray(image.Rect(0, 0, 0, 10))); err == nil {
+		t.Errorf("Hashes() of an empty image did not fail")
+	}
+}
+
+func TestBlank(t *testing.T) {
+	tests := []struct {
+		name  string
+		image image.Image
+		want  bool
+	}{
+		{"white", uniform(color.Gray{Y: 255}, 0), true},
+		{"black", uniform(color.Gray{Y: 0}, 0), true},
+		{"white with a speck", uniform(color.Gray{Y: 255}, 8), true},
+		{"white with a block", uniform(color.Gray{Y: 255}, 200), false},
+		{"halves", halves(), false},
+		{"gradient", gradient(), false},
+		{"empty", image.NewGray(image.Rect(0, 0, 0, 0)), true},
+	}
+	for _, tt := range tests {
+		if got := Blank(tt.image); got != tt.want {
+			t.Errorf("Blank(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
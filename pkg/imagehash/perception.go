@@ -1,7 +1,6 @@
 package imagehash
 
 import (
-	"errors"
 	"image"
 	"math"
 	"sync"
@@ -28,13 +27,17 @@ var pixelPool = sync.Pool{
 // the heavy intermediate allocations required by github.com/nfnt/resize as implemented
 // in goimagehash. Essentially, this is just a small reimplemenation.
 func PerceptionHash(img image.Image) (string, error) {
-	if img == nil {
-		return "", errors.New("image is nil")
+	hash, err := perceptionHash(img)
+	if err != nil {
+		return "", err
 	}
 
-	bounds := img.Bounds()
-	if bounds.Dx() == 0 || bounds.Dy() == 0 {
-		return "", errors.New("image has invalid bounds")
+	return goimagehash.NewImageHash(hash, goimagehash.PHash).ToString(), nil
+}
+
+func perceptionHash(img image.Image) (uint64, error) {
+	if err := checkImage(img); err != nil {
+		return 0, err
 	}
 
 	pixelsPtr := pixelPool.Get().(*[]float64)
@@ -54,7 +57,7 @@ func PerceptionHash(img image.Image) (string, error) {
 		}
 	}
 
-	return goimagehash.NewImageHash(hash, goimagehash.PHash).ToString(), nil
+	return hash, nil
 }
 
 func fillDownsampledGrayscale(src image.Image, dst []float64) {
@@ -29,6 +29,12 @@ type Result struct {
 	PerceptionHashGroupId uint      `json:"perception_hash_group_id" gorm:"index"`
 	Screenshot            string    `json:"screenshot"`
 
+	// Additional perceptual hashes of the screenshot, one per algorithm
+	ImageHashes []ImageHash `json:"image_hashes" gorm:"constraint:OnDelete:CASCADE"`
+
+	// The site's favicon, used to fingerprint products
+	Favicon Favicon `json:"favicon" gorm:"constraint:OnDelete:CASCADE"`
+
 	// Name of the screenshot file
 	Filename string `json:"file_name"`
 	IsPDF    bool   `json:"is_pdf"`
@@ -37,6 +43,48 @@ type Result struct {
 	Failed       bool   `json:"failed"`
 	FailedReason string `json:"failed_reason"`
 
+	// Pages that clutter a gallery. Blank is set for near uniform
+	// screenshots, ErrorPage for browser error pages (chrome-error://)
+	// and Soft404 for success responses that are a host's catch-all page
+	Blank     bool `json:"blank" gorm:"index"`
+	ErrorPage bool `json:"error_page" gorm:"index"`
+	Soft404   bool `json:"soft404" gorm:"index"`
+
+	// Provenance of results found by recursive discovery. DiscoveredFromID
+	// is the result whose page referenced this target, DiscoveredVia how it
+	// was referenced (like link or san) and DiscoveryDepth how many
+	// discoveries away from an original target it is
+	DiscoveredFromID  uint   `json:"discovered_from_id" gorm:"index"`
+	DiscoveredFromURL string `json:"discovered_from_url"`
+	DiscoveredVia     string `json:"discovered_via"`
+	DiscoveryDepth    int    `json:"discovery_depth"`
+
+	// OriginalMethod is the method a target was requested with, where its
+	// reader knows it, like from a proxy's history. Browsers always
+	// navigate to targets with GET
+	OriginalMethod string `json:"original_method"`
+
+	// Virtual host scans request VHost from VHostIP, whatever DNS says
+	// VHost resolves to, if anything
+	VHost   string `json:"vhost" gorm:"column:vhost;index"`
+	VHostIP string `json:"vhost_ip" gorm:"column:vhost_ip;index"`
+
+	// ResolvedIP is the address the target's response came from, as the
+	// browser resolved it. With a proxy, it is the proxy's address
+	ResolvedIP string `json:"resolved_ip" gorm:"index"`
+	// Proxy is the proxy selected for the target from a pool or rules
+	Proxy string `json:"proxy"`
+
+	// Analyst triage of the result
+	Reviewed bool   `json:"reviewed" gorm:"index"`
+	Notes    string `json:"notes" gorm:"type:longtext"`
+	Tags     []Tag  `json:"tags" gorm:"constraint:OnDelete:CASCADE"`
+
+	// Categories assigned by page signatures, like login form
+	Categories []Category `json:"categories" gorm:"constraint:OnDelete:CASCADE"`
+	// Known default credentials for the products a result was identified as
+	DefaultCredentials []DefaultCredential `json:"default_credentials" gorm:"constraint:OnDelete:CASCADE"`
+
 	TLS          TLS          `json:"tls" gorm:"constraint:OnDelete:CASCADE"`
 	Technologies []Technology `json:"technologies" gorm:"constraint:OnDelete:CASCADE"`
 
@@ -44,6 +92,16 @@ type Result struct {
 	Network []NetworkLog `json:"network" gorm:"constraint:OnDelete:CASCADE"`
 	Console []ConsoleLog `json:"console" gorm:"constraint:OnDelete:CASCADE"`
 	Cookies []Cookie     `json:"cookies" gorm:"constraint:OnDelete:CASCADE"`
+
+	// Elements extracted from the page's DOM
+	Links    []Link    `json:"links" gorm:"constraint:OnDelete:CASCADE"`
+	Forms    []Form    `json:"forms" gorm:"constraint:OnDelete:CASCADE"`
+	Scripts  []Script  `json:"scripts" gorm:"constraint:OnDelete:CASCADE"`
+	MetaTags []MetaTag `json:"meta_tags" gorm:"constraint:OnDelete:CASCADE"`
+
+	// Requests the browser was stopped from making, as they were out of
+	// the scan's scope
+	BlockedRequests []BlockedRequest `json:"blocked_requests" gorm:"constraint:OnDelete:CASCADE"`
 }
 
 func (r *Result) HeaderMap() map[string][]string {
@@ -86,6 +144,130 @@ type Technology struct {
 	Value string `json:"value" gorm:"index"`
 }
 
+// ImageHash is a perceptual hash of a result's screenshot
+type ImageHash struct {
+	ID       uint `json:"id" gorm:"primarykey"`
+	ResultID uint `json:"result_id" gorm:"index"`
+
+	// Algorithm is the name of an imagehash algorithm, like dhash
+	Algorithm string `json:"algorithm" gorm:"index"`
+	// Value is the hex encoded hash
+	Value string `json:"value"`
+}
+
+// Favicon is the icon a result's page declares, or its /favicon.ico
+type Favicon struct {
+	ID       uint `json:"id" gorm:"primarykey"`
+	ResultID uint `json:"result_id" gorm:"index"`
+
+	URL      string `json:"url"`
+	MIMEType string `json:"mime_type"`
+	Content  []byte `json:"content"`
+	// MMH3 is the Shodan style hash, as used by http.favicon.hash
+	MMH3 int32  `json:"mmh3" gorm:"index"`
+	MD5  string `json:"md5" gorm:"index"`
+}
+
+// Tag is a user defined label on a result
+type Tag struct {
+	ID       uint `json:"id" gorm:"primarykey"`
+	ResultID uint `json:"result_id" gorm:"index"`
+
+	Value string `json:"value" gorm:"index"`
+}
+
+// Category is a page category assigned to a result by a signature
+type Category struct {
+	ID       uint `json:"id" gorm:"primarykey"`
+	ResultID uint `json:"result_id" gorm:"index"`
+
+	Value string `json:"value" gorm:"index"`
+	// Signature is the name of the signature that matched
+	Signature string `json:"signature"`
+}
+
+// DefaultCredential is a known default login for a product a result was
+// identified as. These are hints only, they have not been tried.
+type DefaultCredential struct {
+	ID       uint `json:"id" gorm:"primarykey"`
+	ResultID uint `json:"result_id" gorm:"index"`
+
+	Product  string `json:"product" gorm:"index"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// Match is the technology or signature that identified the product,
+	// like tech:Apache Tomcat or signature:tomcat-manager
+	Match     string `json:"match"`
+	Notes     string `json:"notes"`
+	Reference string `json:"reference"`
+}
+
+// Link is a link on a result's page
+type Link struct {
+	ID       uint `json:"id" gorm:"primarykey"`
+	ResultID uint `json:"result_id" gorm:"index"`
+
+	// URL is resolved against the page's URL
+	URL  string `json:"url" gorm:"type:longtext;index:,length:191"`
+	Text string `json:"text"`
+	// External is set for links to another host
+	External bool `json:"external" gorm:"index"`
+}
+
+// Form is a form on a result's page
+type Form struct {
+	ID       uint `json:"id" gorm:"primarykey"`
+	ResultID uint `json:"result_id" gorm:"index"`
+
+	// Action is resolved against the page's URL
+	Action string      `json:"action" gorm:"type:longtext;index:,length:191"`
+	Method string      `json:"method"`
+	Inputs []FormInput `json:"inputs" gorm:"constraint:OnDelete:CASCADE"`
+	// HasPassword and HasFile are set if the form has a password or file
+	// upload input
+	HasPassword bool `json:"has_password" gorm:"index"`
+	HasFile     bool `json:"has_file" gorm:"index"`
+}
+
+// FormInput is an input, select, textarea or button of a form
+type FormInput struct {
+	ID     uint `json:"id" gorm:"primarykey"`
+	FormID uint `json:"form_id" gorm:"index"`
+
+	Name  string `json:"name" gorm:"index"`
+	Type  string `json:"type" gorm:"index"`
+	Value string `json:"value"`
+}
+
+// Script is a script a result's page loads
+type Script struct {
+	ID       uint `json:"id" gorm:"primarykey"`
+	ResultID uint `json:"result_id" gorm:"index"`
+
+	// Src is resolved against the page's URL
+	Src string `json:"src" gorm:"type:longtext;index:,length:191"`
+}
+
+// MetaTag is a meta tag on a result's page. Name is the tag's name,
+// property or http-equiv attribute.
+type MetaTag struct {
+	ID       uint `json:"id" gorm:"primarykey"`
+	ResultID uint `json:"result_id" gorm:"index"`
+
+	Name    string `json:"name" gorm:"index"`
+	Content string `json:"content" gorm:"type:longtext"`
+}
+
+// BlockedRequest is a request a result's page made that was out of scope.
+// Reason says which scope rule it broke.
+type BlockedRequest struct {
+	ID       uint `json:"id" gorm:"primarykey"`
+	ResultID uint `json:"result_id" gorm:"index"`
+
+	URL    string `json:"url" gorm:"type:longtext"`
+	Reason string `json:"reason"`
+}
+
 type Header struct {
 	ID       uint `json:"id" gorm:"primarykey"`
 	ResultID uint `json:"result_id"`
@@ -133,3 +315,32 @@ type Cookie struct {
 	SourceScheme string    `json:"source_scheme"`
 	SourcePort   int64     `json:"source_port"`
 }
+
+// Setting is a value stored alongside the results in a database, such as
+// the threshold its perception hash groups were computed with
+type Setting struct {
+	Name  string `json:"name" gorm:"primarykey"`
+	Value string `json:"value"`
+}
+
+// SavedSearch is a named search query
+type SavedSearch struct {
+	ID uint `json:"id" gorm:"primarykey"`
+
+	Name      string      `json:"name"`
+	Query     string      `json:"query"`
+	CreatedAt time.Time   `json:"created_at"`
+	Alerts    []AlertRule `json:"alerts" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// AlertRule emits a notification when a new result matches a saved search
+type AlertRule struct {
+	ID            uint `json:"id" gorm:"primarykey"`
+	SavedSearchID uint `json:"saved_search_id" gorm:"index"`
+
+	// Type is one of webhook, file or events
+	Type string `json:"type"`
+	// Target is the webhook URL or file path, depending on Type
+	Target  string `json:"target"`
+	Enabled bool   `json:"enabled" gorm:"index"`
+}
@@ -0,0 +1,325 @@
+// Package proxies picks the proxy each target is scanned through, from a
+// pool of proxies used in turn and rules that route hosts to a proxy
+package proxies
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sensepost/gowitness/pkg/log"
+	"github.com/sensepost/gowitness/pkg/resolver"
+	"github.com/sensepost/gowitness/pkg/scope"
+)
+
+// Direct is the proxy of targets that are not proxied, even when the
+// browser has a proxy
+const Direct = "direct://"
+
+// Options configure a proxy pool
+type Options struct {
+	// Proxies are used in turn for targets that no rule matches, like
+	// socks5://10.0.0.1:1080 or http://10.0.0.2:3128
+	Proxies []string
+	// Rules route hosts to a proxy, as hosts=proxy. Hosts are comma
+	// separated domains, wildcards, IPs and CIDRs, like in a scope, and
+	// the proxy may be "direct". The first matching rule wins.
+	Rules []string
+	// HealthCheck is how often proxies are checked to be up. Zero only
+	// checks them once
+	HealthCheck time.Duration
+	// Timeout for connecting to a proxy when checking it
+	Timeout time.Duration
+	// Resolver resolves hostnames for rules with networks. nil uses the
+	// system resolver
+	Resolver *resolver.Resolver
+}
+
+// Pool selects the proxy each target is scanned through
+type Pool struct {
+	pool  []*proxy
+	rules []*rule
+	next  atomic.Uint64
+
+	// proxies are all the proxies of the pool and rules, for health checks
+	proxies map[string]*proxy
+	timeout time.Duration
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// proxy is a proxy server, and whether it was up when last checked
+type proxy struct {
+	url     string
+	addr    string
+	healthy atomic.Bool
+}
+
+// rule routes the hosts it matches to a proxy. proxy is nil for direct
+type rule struct {
+	hosts *scope.Scope
+	proxy *proxy
+}
+
+// New returns a pool for the options, after checking its proxies once.
+// It returns nil if there are no proxies or rules.
+func New(opts Options) (*Pool, error) {
+	if len(opts.Proxies) == 0 && len(opts.Rules) == 0 {
+		return nil, nil
+	}
+
+	p := &Pool{
+		proxies: make(map[string]*proxy),
+		timeout: opts.Timeout,
+		done:    make(chan struct{}),
+	}
+	if p.timeout <= 0 {
+		p.timeout = 5 * time.Second
+	}
+
+	for _, raw := range opts.Proxies {
+		proxy, err := p.proxy(raw)
+		if err != nil {
+			return nil, err
+		}
+		p.pool = append(p.pool, proxy)
+	}
+
+	for _, raw := range opts.Rules {
+		hosts, target, ok := strings.Cut(raw, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid proxy rule %q, want hosts=proxy", raw)
+		}
+
+		var allow []string
+		for _, host := range strings.Split(hosts, ",") {
+			if host = strings.TrimSpace(host); host != "" {
+				allow = append(allow, host)
+			}
+		}
+		if len(allow) == 0 {
+			return nil, fmt.Errorf("proxy rule %q has no hosts", raw)
+		}
+
+		s, err := scope.New(scope.Options{Allow: allow, Resolver: opts.Resolver})
+		if err != nil {
+			return nil, fmt.Errorf("proxy rule %q: %w", raw, err)
+		}
+
+		r := &rule{hosts: s}
+		if target = strings.TrimSpace(target); !isDirect(target) {
+			if r.proxy, err = p.proxy(target); err != nil {
+				return nil, err
+			}
+		}
+		p.rules = append(p.rules, r)
+	}
+
+	p.check()
+	for _, proxy := range p.proxies {
+		if !proxy.healthy.Load() {
+			log.Warn("proxy is down", "proxy", proxy.url)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	go p.monitor(ctx, opts.HealthCheck)
+
+	return p, nil
+}
+
+// proxy returns the pool's proxy for a URL, adding it if it is new
+func (p *Pool) proxy(raw string) (*proxy, error) {
+	if existing, ok := p.proxies[raw]; ok {
+		return existing, nil
+	}
+
+	// chrome accepts a proxy without a scheme, defaulting to http
+	u, err := proxyURL(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %q: %w", raw, err)
+	}
+
+	port := u.Port()
+	if port == "" {
+		switch u.Scheme {
+		case "http":
+			port = "80"
+		case "https":
+			port = "443"
+		case "socks4", "socks5":
+			port = "1080"
+		default:
+			return nil, fmt.Errorf("invalid proxy %q: unsupported scheme %s", raw, u.Scheme)
+		}
+	}
+
+	proxy := &proxy{url: raw, addr: net.JoinHostPort(u.Hostname(), port)}
+	p.proxies[raw] = proxy
+
+	return proxy, nil
+}
+
+// Select returns the proxy to scan a URL through: Direct, a proxy URL, or
+// "" for the browser's own proxy settings. Rules are matched first, then
+// the pool's healthy proxies are used in turn.
+func (p *Pool) Select(rawURL string) (string, error) {
+	if p == nil {
+		return "", nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	for _, r := range p.rules {
+		if !r.hosts.AllowsHost(u.Hostname()) {
+			continue
+		}
+
+		if r.proxy == nil {
+			return Direct, nil
+		}
+		// another proxy may not reach the hosts the rule routes
+		if !r.proxy.healthy.Load() {
+			return "", fmt.Errorf("proxy %s for %s is down", r.proxy.url, u.Hostname())
+		}
+
+		return r.proxy.url, nil
+	}
+
+	if len(p.pool) == 0 {
+		return "", nil
+	}
+
+	start := p.next.Add(1)
+	for i := range uint64(len(p.pool)) {
+		proxy := p.pool[(start+i)%uint64(len(p.pool))]
+		if proxy.healthy.Load() {
+			return proxy.url, nil
+		}
+	}
+
+	return "", errors.New("no proxy in the pool is up")
+}
+
+// Close stops health checks
+func (p *Pool) Close() {
+	if p == nil {
+		return
+	}
+
+	p.cancel()
+	<-p.done
+}
+
+// monitor checks the proxies every interval, until ctx is done
+func (p *Pool) monitor(ctx context.Context, interval time.Duration) {
+	defer close(p.done)
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.check()
+		}
+	}
+}
+
+// check connects to each proxy, logging the ones that went down or came
+// back up
+func (p *Pool) check() {
+	var wg sync.WaitGroup
+	for _, proxy := range p.proxies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			conn, err := net.DialTimeout("tcp", proxy.addr, p.timeout)
+			if err == nil {
+				conn.Close()
+			}
+
+			healthy := err == nil
+			switch was := proxy.healthy.Swap(healthy); {
+			case healthy && !was:
+				log.Info("proxy is up", "proxy", proxy.url)
+			case !healthy && was:
+				log.Warn("proxy went down", "proxy", proxy.url, "err", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+type proxyKey struct{}
+
+// WithProxy returns a context for requests to go through a proxy, as
+// returned by Select
+func WithProxy(ctx context.Context, proxy string) context.Context {
+	return context.WithValue(ctx, proxyKey{}, proxy)
+}
+
+// ContextProxy returns the proxy a context's requests go through, or an
+// empty string if it has none
+func ContextProxy(ctx context.Context) string {
+	proxy, _ := ctx.Value(proxyKey{}).(string)
+	return proxy
+}
+
+// ProxyFunc returns an http.Transport Proxy function that uses the proxy
+// of a request's context, or fallback (which may be nil) if it has none
+func ProxyFunc(fallback func(*http.Request) (*url.URL, error)) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		proxy := ContextProxy(req.Context())
+		switch {
+		case proxy == "":
+			if fallback == nil {
+				return nil, nil
+			}
+			return fallback(req)
+		case isDirect(proxy):
+			return nil, nil
+		default:
+			return proxyURL(proxy)
+		}
+	}
+}
+
+// proxyURL parses a proxy the way chrome does, defaulting to http
+func proxyURL(proxy string) (*url.URL, error) {
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, err
+	}
+	if u.Hostname() == "" {
+		return nil, errors.New("no host")
+	}
+
+	return u, nil
+}
+
+// isDirect checks if a proxy means no proxy
+func isDirect(proxy string) bool {
+	return proxy == Direct || strings.EqualFold(proxy, "direct")
+}
@@ -0,0 +1,72 @@
+package proxies
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+)
+
+func TestPool(t *testing.T) {
+	up, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer up.Close()
+
+	// a port nothing listens on anymore
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	down := "socks5://" + closed.Addr().String()
+	closed.Close()
+
+	pivot := "socks5://" + up.Addr().String()
+	pool, err := New(Options{
+		Proxies: []string{pivot, down},
+		Rules: []string{
+			"10.1.0.0/16=" + pivot,
+			"*.corp.local, intranet=direct",
+			"10.9.0.0/16=" + down,
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer pool.Close()
+
+	tests := []struct {
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{"http://10.1.2.3/", pivot, false},
+		{"https://app.corp.local/", Direct, false},
+		{"http://intranet:8080/", Direct, false},
+		{"http://10.9.0.1/", "", true},
+		// the pool skips proxies that are down
+		{"https://example.com/", pivot, false},
+		{"https://example.com/", pivot, false},
+	}
+	for _, tt := range tests {
+		got, err := pool.Select(tt.url)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Select(%q) = %q, %v, want %q", tt.url, got, err, tt.want)
+		}
+	}
+
+	proxy := ProxyFunc(nil)
+	req, _ := http.NewRequestWithContext(WithProxy(context.Background(), pivot), http.MethodGet, "http://10.1.2.3/", nil)
+	if u, err := proxy(req); err != nil || u == nil || u.String() != pivot {
+		t.Errorf("ProxyFunc() = %v, %v, want %s", u, err, pivot)
+	}
+	req, _ = http.NewRequestWithContext(WithProxy(context.Background(), Direct), http.MethodGet, "http://app.corp.local/", nil)
+	if u, err := proxy(req); err != nil || u != nil {
+		t.Errorf("ProxyFunc() = %v, %v, want no proxy", u, err)
+	}
+
+	if _, err := New(Options{Rules: []string{"10.0.0.0/8"}}); err == nil {
+		t.Errorf("New() accepted a rule without a proxy")
+	}
+}
@@ -0,0 +1,94 @@
+package readers
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// BurpReader is a Burp Suite results reader. It reads the XML Burp saves
+// proxy history items and site map branches as.
+type BurpReader struct {
+	Options *BurpReaderOptions
+}
+
+// BurpReaderOptions are options for the Burp reader
+type BurpReaderOptions struct {
+	// Path to a Burp XML export
+	Source string
+	// Hosts to limit targets to, along with their subdomains
+	Hosts []string
+	// Response statuses to limit targets to
+	Statuses []int
+	// MIME types to limit targets to, like html
+	MIMETypes []string
+	// UniquePaths deduplicates targets by path instead of by URL
+	UniquePaths bool
+}
+
+// burpItem is an item in a Burp XML export
+type burpItem struct {
+	URL      string `xml:"url"`
+	Method   string `xml:"method"`
+	Status   string `xml:"status"`
+	MIMEType string `xml:"mimetype"`
+}
+
+// NewBurpReader prepares a new Burp reader
+func NewBurpReader(opts *BurpReaderOptions) *BurpReader {
+	return &BurpReader{
+		Options: opts,
+	}
+}
+
+// Read a Burp XML export
+func (br *BurpReader) Read(ch chan<- *Target) error {
+	defer close(ch)
+
+	file, err := openSource(br.Options.Source)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	filter := newProxyFilter(ch)
+	filter.Hosts = br.Options.Hosts
+	filter.Statuses = br.Options.Statuses
+	filter.MIMETypes = br.Options.MIMETypes
+	filter.UniquePaths = br.Options.UniquePaths
+
+	// exports have each response in them, so items are decoded one at a
+	// time rather than reading the whole file in
+	decoder := xml.NewDecoder(file)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		element, ok := token.(xml.StartElement)
+		if !ok || element.Name.Local != "item" {
+			continue
+		}
+
+		var item burpItem
+		if err := decoder.DecodeElement(&item, &element); err != nil {
+			return err
+		}
+
+		// items without a response have an empty status
+		status, _ := strconv.Atoi(strings.TrimSpace(item.Status))
+		filter.add(proxyRequest{
+			URL:      item.URL,
+			Method:   strings.TrimSpace(item.Method),
+			Status:   status,
+			MIMEType: strings.TrimSpace(item.MIMEType),
+		})
+	}
+
+	return nil
+}
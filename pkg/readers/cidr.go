@@ -3,7 +3,9 @@ package readers
 import (
 	"bufio"
 	"fmt"
+	"math"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/sensepost/gowitness/internal/islazy"
@@ -32,52 +34,111 @@ func NewCidrReader(opts *CidrReaderOptions) *CidrReader {
 	}
 }
 
-func (cr *CidrReader) Read(ch chan<- string) error {
+// Read streams the targets in the CIDRs, without expanding them in memory.
+// With Random set, targets are read in a pseudo random order instead.
+func (cr *CidrReader) Read(ch chan<- *Target) error {
 	defer close(ch)
 
-	candidates, err := cr.candidates()
+	cidrs, err := cr.cidrs()
 	if err != nil {
 		return err
 	}
 
-	log.Debug("total candidates to scan", "total", len(candidates))
+	space := newCandidateSpace(cidrs, cr.ports(), cr.schemes())
+	if space.size > maxCandidates {
+		return fmt.Errorf("too many candidates to scan (%d), use fewer or smaller cidrs", space.size)
+	}
+
+	log.Debug("total candidates to scan", "total", space.size)
+
+	order := func(yield func(uint64) bool) {
+		for i := range space.size {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	if cr.Options.Random {
+		order = islazy.Permutation(space.size)
+	}
 
-	for _, target := range candidates {
-		ch <- target
+	for i := range order {
+		if target, ok := space.at(i); ok {
+			ch <- &Target{URL: target}
+		}
 	}
 
 	return nil
 }
 
-// candidates creates url candidates from ports and ips
-func (cr *CidrReader) candidates() ([]string, error) {
-	var candidates []string
+// maxCandidates caps the candidates in a scan, so that counting them can't
+// overflow
+const maxCandidates = 1 << 62
+
+// candidateSpace indexes every scheme, port and address combination of a
+// set of CIDRs, so that candidates can be generated one at a time. Indexes
+// are ordered by address, then port, then scheme.
+type candidateSpace struct {
+	cidrs   []islazy.CIDR
+	ports   []int
+	schemes []string
+	// offsets are the index of each cidr's first address
+	offsets []uint64
+	// addresses is the total number of addresses in cidrs
+	addresses uint64
+	size      uint64
+}
+
+func newCandidateSpace(cidrs []islazy.CIDR, ports []int, schemes []string) *candidateSpace {
+	space := &candidateSpace{cidrs: cidrs, ports: ports, schemes: schemes}
 
-	ports := cr.ports()
-	ips, err := cr.ips()
-	if err != nil {
-		return nil, err
+	for _, cidr := range cidrs {
+		space.offsets = append(space.offsets, space.addresses)
+		space.addresses += cidr.Size()
 	}
 
-	for _, ip := range ips {
-		for _, port := range ports {
-			partial := fmt.Sprintf("%s:%d", ip, port)
+	perAddress := uint64(len(ports) * len(schemes))
+	if perAddress > 0 && space.addresses > maxCandidates/perAddress {
+		space.size = math.MaxUint64
+	} else {
+		space.size = space.addresses * perAddress
+	}
 
-			if !cr.Options.NoHTTP {
-				candidates = append(candidates, fmt.Sprintf("http://%s", partial))
-			}
+	return space
+}
 
-			if !cr.Options.NoHTTPS {
-				candidates = append(candidates, fmt.Sprintf("https://%s", partial))
-			}
-		}
+// at returns the candidate URL at an index, or false if its address is
+// not usable
+func (s *candidateSpace) at(i uint64) (string, bool) {
+	scheme := s.schemes[i%uint64(len(s.schemes))]
+	i /= uint64(len(s.schemes))
+	port := s.ports[i%uint64(len(s.ports))]
+	i /= uint64(len(s.ports))
+
+	// the last cidr that starts at or before the address
+	c := sort.Search(len(s.offsets), func(j int) bool { return s.offsets[j] > i }) - 1
+
+	addr, ok := s.cidrs[c].At(i - s.offsets[c])
+	if !ok {
+		return "", false
 	}
 
-	if cr.Options.Random {
-		islazy.ShuffleStr(candidates)
+	return targetURL(scheme, addr.String(), port), true
+}
+
+// schemes returns the url schemes to scan
+func (cr *CidrReader) schemes() []string {
+	var schemes []string
+
+	if !cr.Options.NoHTTP {
+		schemes = append(schemes, "http")
 	}
 
-	return candidates, nil
+	if !cr.Options.NoHTTPS {
+		schemes = append(schemes, "https")
+	}
+
+	return schemes
 }
 
 // ports returns all of the ports to scan
@@ -99,10 +160,11 @@ func (cr *CidrReader) ports() []int {
 	return islazy.UniqueIntSlice(ports)
 }
 
-// ips gets ips from a file and cidr agruments
-func (cr *CidrReader) ips() ([]string, error) {
-	var cidrs = cr.Options.Cidrs
-	var ips []string
+// cidrs parses the cidr arguments and the cidrs in the source file, if
+// there is one. Blank lines and # comments in the file are ignored.
+func (cr *CidrReader) cidrs() ([]islazy.CIDR, error) {
+	var entries = cr.Options.Cidrs
+	var cidrs []islazy.CIDR
 
 	// Slurp a file if we have one
 	if cr.Options.Source != "" {
@@ -120,23 +182,23 @@ func (cr *CidrReader) ips() ([]string, error) {
 
 		scanner := bufio.NewScanner(file)
 		for scanner.Scan() {
-			cidrs = append(cidrs, strings.TrimSpace(scanner.Text()))
+			if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+				entries = append(entries, line)
+			}
 		}
-	}
-
-	// populate ips from the collected cidrs to return
-	for _, cidr := range cidrs {
-		if !strings.Contains(cidr, "/") {
-			cidr += "/32"
+		if err := scanner.Err(); err != nil {
+			return nil, err
 		}
+	}
 
-		ip, err := islazy.IpsInCIDR(cidr)
+	for _, entry := range entries {
+		cidr, err := islazy.ParseCIDR(entry)
 		if err != nil {
 			return nil, err
 		}
 
-		ips = append(ips, ip...)
+		cidrs = append(cidrs, cidr)
 	}
 
-	return ips, nil
+	return cidrs, nil
 }
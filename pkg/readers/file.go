@@ -2,7 +2,7 @@ package readers
 
 import (
 	"bufio"
-	"fmt"
+	"net/netip"
 	"net/url"
 	"os"
 	"strconv"
@@ -38,7 +38,7 @@ func NewFileReader(opts *FileReaderOptions) *FileReader {
 
 // Read from a file that contains targets.
 // FilePath can be "-" indicating that we should read from stdin.
-func (fr *FileReader) Read(ch chan<- string) error {
+func (fr *FileReader) Read(ch chan<- *Target) error {
 	defer close(ch)
 
 	var file *os.File
@@ -65,7 +65,7 @@ func (fr *FileReader) Read(ch chan<- string) error {
 		}
 
 		for _, url := range fr.urlsFor(candidate, ports) {
-			ch <- url
+			ch <- &Target{URL: url}
 		}
 	}
 
@@ -86,7 +86,7 @@ func (fr *FileReader) urlsFor(candidate string, ports []int) []string {
 	// check if we got a scheme, add
 	hasScheme := strings.Contains(candidate, "://")
 	if !hasScheme {
-		candidate = "http://" + candidate
+		candidate = "http://" + bracketIPv6(candidate)
 	}
 
 	parsedURL, err := url.Parse(candidate)
@@ -152,19 +152,9 @@ func (fr *FileReader) urlsFor(candidate string, ports []int) []string {
 	// generate the urls
 	for _, scheme := range schemes {
 		for _, port := range targetPorts {
-			host := hostname
-
-			if port != 0 {
-				if isIPv6(hostname) {
-					host = fmt.Sprintf("[%s]:%d", hostname, port)
-				} else {
-					host = fmt.Sprintf("%s:%d", hostname, port)
-				}
-			}
-
 			fullURL := url.URL{
 				Scheme:   scheme,
-				Host:     host,
+				Host:     hostPort(hostname, port),
 				Path:     parsedURL.Path,
 				RawQuery: parsedURL.RawQuery,
 			}
@@ -195,6 +185,21 @@ func (fr *FileReader) ports() []int {
 	return islazy.UniqueIntSlice(ports)
 }
 
-func isIPv6(hostname string) bool {
-	return len(hostname) > 0 && hostname[0] == '[' && hostname[len(hostname)-1] == ']'
+// bracketIPv6 brackets a candidate that starts with a bare IPv6 literal,
+// like 2001:db8::1 or fe80::1%eth0/path, so that it parses as a URL host
+func bracketIPv6(candidate string) string {
+	host, path, hasPath := strings.Cut(candidate, "/")
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !addr.Is6() {
+		return candidate
+	}
+
+	// zones are percent-encoded in URLs
+	host = "[" + strings.Replace(host, "%", "%25", 1) + "]"
+	if hasPath {
+		host += "/" + path
+	}
+
+	return host
 }
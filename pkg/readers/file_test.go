@@ -95,6 +95,43 @@ func TestUrlsFor(t *testing.T) {
 				"https://192.168.1.1:8080/path",
 			},
 		},
+		{
+			name:      "Test with IPv6",
+			candidate: "2001:db8::1",
+			ports:     []int{80, 8443},
+			want: []string{
+				"http://[2001:db8::1]:80",
+				"http://[2001:db8::1]:8443",
+				"https://[2001:db8::1]:80",
+				"https://[2001:db8::1]:8443",
+			},
+		},
+		{
+			name:      "Test with bracketed IPv6 and port",
+			candidate: "[2001:db8::1]:8080",
+			ports:     []int{80, 443, 8443},
+			want: []string{
+				"http://[2001:db8::1]:8080",
+				"https://[2001:db8::1]:8080",
+			},
+		},
+		{
+			name:      "Test with scheme, IPv6 and path",
+			candidate: "https://[2001:db8::1]/admin",
+			ports:     []int{8443},
+			want: []string{
+				"https://[2001:db8::1]:8443/admin",
+			},
+		},
+		{
+			name:      "Test with IPv6, zone and path",
+			candidate: "fe80::1%eth0/path",
+			ports:     []int{80},
+			want: []string{
+				"http://[fe80::1%25eth0]:80/path",
+				"https://[fe80::1%25eth0]:80/path",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,174 @@
+package readers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strconv"
+	"strings"
+
+	"github.com/sensepost/gowitness/pkg/log"
+)
+
+// MasscanReader is a masscan results reader. It reads masscan's XML (-oX),
+// JSON (-oJ and -oD) and list (-oL) output formats.
+type MasscanReader struct {
+	Options *MasscanReaderOptions
+}
+
+// MasscanReaderOptions are options for the masscan reader
+type MasscanReaderOptions struct {
+	// Path to a masscan output file
+	Source  string
+	NoHTTP  bool
+	NoHTTPS bool
+	// Ports to limit scans to
+	Ports []int
+	// Ports to exclude, no matter what
+	ExcludePorts []int
+}
+
+// structures for masscan output parsing
+type masscanXMLHost struct {
+	Address struct {
+		Addr string `xml:"addr,attr"`
+	} `xml:"address"`
+	Ports []struct {
+		Protocol string `xml:"protocol,attr"`
+		PortId   int    `xml:"portid,attr"`
+		State    struct {
+			State string `xml:"state,attr"`
+		} `xml:"state"`
+	} `xml:"ports>port"`
+}
+
+type masscanJSONHost struct {
+	IP    string `json:"ip"`
+	Ports []struct {
+		Port   int    `json:"port"`
+		Proto  string `json:"proto"`
+		Status string `json:"status"`
+	} `json:"ports"`
+}
+
+// NewMasscanReader prepares a new masscan reader
+func NewMasscanReader(opts *MasscanReaderOptions) *MasscanReader {
+	return &MasscanReader{
+		Options: opts,
+	}
+}
+
+// Read a masscan file, detecting its format
+func (mr *MasscanReader) Read(ch chan<- *Target) error {
+	defer close(ch)
+
+	data, err := readSource(mr.Options.Source)
+	if err != nil {
+		return err
+	}
+
+	emitter := newTargetEmitter(ch, mr.Options.NoHTTP, mr.Options.NoHTTPS)
+	filter := portFilter{Ports: mr.Options.Ports, ExcludePorts: mr.Options.ExcludePorts}
+	add := func(ip string, proto string, port int, status string) {
+		// only tcp ports can be web services. banners have no status
+		if proto != "tcp" || (status != "" && status != "open") || !filter.allows(port) {
+			return
+		}
+		emitter.emit(ip, port)
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return mr.readXML(trimmed, add)
+	case bytes.HasPrefix(trimmed, []byte("[")), bytes.HasPrefix(trimmed, []byte("{")):
+		return mr.readJSON(trimmed, add)
+	default:
+		return mr.readList(trimmed, add)
+	}
+}
+
+func (mr *MasscanReader) readXML(data []byte, add func(string, string, int, string)) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil || token == nil {
+			break // EOF or error
+		}
+
+		element, ok := token.(xml.StartElement)
+		if !ok || element.Name.Local != "host" {
+			continue
+		}
+
+		var host masscanXMLHost
+		if err := decoder.DecodeElement(&host, &element); err != nil {
+			return err
+		}
+
+		for _, port := range host.Ports {
+			add(host.Address.Addr, port.Protocol, port.PortId, port.State.State)
+		}
+	}
+
+	return nil
+}
+
+// readJSON reads masscan's json output. Older versions of masscan write
+// an invalid array, with a trailing comma and an unquoted finished key,
+// so if the file does not parse as a whole it is read a line at a time.
+func (mr *MasscanReader) readJSON(data []byte, add func(string, string, int, string)) error {
+	var hosts []masscanJSONHost
+	if err := json.Unmarshal(data, &hosts); err != nil {
+		hosts = nil
+
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := strings.Trim(strings.TrimSpace(scanner.Text()), "[],")
+			if line == "" {
+				continue
+			}
+
+			var host masscanJSONHost
+			if err := json.Unmarshal([]byte(line), &host); err != nil {
+				log.Debug("skipping a masscan json line that does not parse", "line", line, "err", err)
+				continue
+			}
+			hosts = append(hosts, host)
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+	}
+
+	for _, host := range hosts {
+		for _, port := range host.Ports {
+			add(host.IP, port.Proto, port.Port, port.Status)
+		}
+	}
+
+	return nil
+}
+
+// readList reads masscan's list output, lines like "open tcp 80 10.0.0.1
+// 1700000000"
+func (mr *MasscanReader) readList(data []byte, add func(string, string, int, string)) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || strings.HasPrefix(fields[0], "#") || fields[0] != "open" {
+			continue
+		}
+
+		port, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+
+		add(fields[3], fields[1], port, fields[0])
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,142 @@
+package readers
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/sensepost/gowitness/pkg/log"
+)
+
+// NaabuReader is a naabu results reader. It reads naabu's JSON lines
+// (-json) output, as well as its default host:port lines.
+type NaabuReader struct {
+	Options *NaabuReaderOptions
+}
+
+// NaabuReaderOptions are options for the naabu reader
+type NaabuReaderOptions struct {
+	// Path to a naabu output file
+	Source  string
+	NoHTTP  bool
+	NoHTTPS bool
+	// Ports to limit scans to
+	Ports []int
+	// Ports to exclude, no matter what
+	ExcludePorts []int
+	// Hostnames adds the scanned hostname, not just its ip, as a target
+	Hostnames bool
+}
+
+// naabuResult is a line of naabu's json output
+type naabuResult struct {
+	Host     string    `json:"host"`
+	IP       string    `json:"ip"`
+	Port     naabuPort `json:"port"`
+	Protocol string    `json:"protocol"`
+}
+
+// naabuPort is a port number. Older versions of naabu write an object
+// like {"Port": 80, "Protocol": 0, "TLS": false} instead.
+type naabuPort int
+
+func (p *naabuPort) UnmarshalJSON(data []byte) error {
+	var port int
+	if err := json.Unmarshal(data, &port); err == nil {
+		*p = naabuPort(port)
+		return nil
+	}
+
+	var object struct {
+		Port int `json:"Port"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+	*p = naabuPort(object.Port)
+
+	return nil
+}
+
+// NewNaabuReader prepares a new naabu reader
+func NewNaabuReader(opts *NaabuReaderOptions) *NaabuReader {
+	return &NaabuReader{
+		Options: opts,
+	}
+}
+
+// Read a naabu file
+func (nr *NaabuReader) Read(ch chan<- *Target) error {
+	defer close(ch)
+
+	file, err := openSource(nr.Options.Source)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	emitter := newTargetEmitter(ch, nr.Options.NoHTTP, nr.Options.NoHTTPS)
+	filter := portFilter{Ports: nr.Options.Ports, ExcludePorts: nr.Options.ExcludePorts}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		result, ok := parseNaabuLine(line)
+		if !ok {
+			log.Debug("skipping a naabu line that does not parse", "line", line)
+			continue
+		}
+
+		if (result.Protocol != "" && result.Protocol != "tcp") || !filter.allows(int(result.Port)) {
+			continue
+		}
+
+		if nr.Options.Hostnames && result.Host != "" && result.Host != result.IP {
+			emitter.emit(result.Host, int(result.Port))
+		}
+
+		host := result.IP
+		if host == "" {
+			host = result.Host
+		}
+		emitter.emit(host, int(result.Port))
+	}
+
+	return scanner.Err()
+}
+
+// parseNaabuLine parses a json line, or a host:port line
+func parseNaabuLine(line string) (naabuResult, bool) {
+	var result naabuResult
+
+	if strings.HasPrefix(line, "{") {
+		if err := json.Unmarshal([]byte(line), &result); err != nil {
+			return result, false
+		}
+		return result, true
+	}
+
+	host, port, err := net.SplitHostPort(line)
+	if err != nil {
+		return result, false
+	}
+	portNumber, err := strconv.Atoi(port)
+	if err != nil {
+		return result, false
+	}
+
+	result.Port = naabuPort(portNumber)
+	if net.ParseIP(host) != nil {
+		result.IP = host
+	} else {
+		result.Host = host
+	}
+
+	return result, true
+}
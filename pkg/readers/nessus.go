@@ -2,8 +2,6 @@ package readers
 
 import (
 	"encoding/xml"
-	"fmt"
-	"net"
 	"os"
 
 	"github.com/sensepost/gowitness/internal/islazy"
@@ -53,7 +51,7 @@ func NewNessusReader(opts *NessusReaderOptions) *NessusReader {
 	}
 }
 
-func (nr *NessusReader) Read(ch chan<- string) error {
+func (nr *NessusReader) Read(ch chan<- *Target) error {
 	defer close(ch)
 
 	nessus, err := os.Open(nr.Options.Source)
@@ -124,7 +122,7 @@ func (nr *NessusReader) Read(ch chan<- string) error {
 
 	for host, ports := range targets {
 		for _, target := range nr.urlsFor(host, ports) {
-			ch <- target
+			ch <- &Target{URL: target}
 		}
 	}
 
@@ -135,19 +133,12 @@ func (nr *NessusReader) Read(ch chan<- string) error {
 func (nr *NessusReader) urlsFor(target string, ports []int) []string {
 	var urls []string
 
-	ip := net.ParseIP(target)
-
-	host := target
-	if ip != nil && ip.To4() == nil {
-		host = fmt.Sprintf("[%s]", target)
-	}
-
 	for _, port := range ports {
 		if !nr.Options.NoHTTP {
-			urls = append(urls, fmt.Sprintf("http://%s:%d", host, port))
+			urls = append(urls, targetURL("http", target, port))
 		}
 		if !nr.Options.NoHTTPS {
-			urls = append(urls, fmt.Sprintf("https://%s:%d", host, port))
+			urls = append(urls, targetURL("https", target, port))
 		}
 	}
 
@@ -1,7 +1,6 @@
 package readers
 
 import (
-	"fmt"
 	"os"
 	"strings"
 
@@ -44,7 +43,7 @@ func NewNmapReader(opts *NmapReaderOptions) *NmapReader {
 }
 
 // Read an nmap file
-func (nr *NmapReader) Read(ch chan<- string) error {
+func (nr *NmapReader) Read(ch chan<- *Target) error {
 	defer close(ch)
 
 	xml, err := os.ReadFile(nr.Options.Source)
@@ -100,21 +99,14 @@ func (nr *NmapReader) Read(ch chan<- string) error {
 				if nr.Options.Hostnames {
 					for _, hostaName := range host.Hostnames {
 						for _, target := range nr.urlsFor(hostaName.Name, port.PortId) {
-							ch <- target
+							ch <- &Target{URL: target}
 						}
 					}
 				}
 
-				// ip:port candidates
-				if address.AddrType == "ipv4" {
-					for _, target := range nr.urlsFor(address.Addr, port.PortId) {
-						ch <- target
-					}
-				} else {
-					addr := fmt.Sprintf("[%s]", address.Addr)
-					for _, target := range nr.urlsFor(addr, port.PortId) {
-						ch <- target
-					}
+				// ip:port candidates. ipv6 literals are bracketed by urlsFor
+				for _, target := range nr.urlsFor(address.Addr, port.PortId) {
+					ch <- &Target{URL: target}
 				}
 			}
 		}
@@ -130,11 +122,11 @@ func (nr *NmapReader) urlsFor(target string, port int) []string {
 	var urls []string
 
 	if !nr.Options.NoHTTP {
-		urls = append(urls, fmt.Sprintf("http://%s:%d", target, port))
+		urls = append(urls, targetURL("http", target, port))
 	}
 
 	if !nr.Options.NoHTTPS {
-		urls = append(urls, fmt.Sprintf("https://%s:%d", target, port))
+		urls = append(urls, targetURL("https", target, port))
 	}
 
 	return urls
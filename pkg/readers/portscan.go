@@ -0,0 +1,91 @@
+package readers
+
+import (
+	"io"
+	"os"
+
+	"github.com/sensepost/gowitness/internal/islazy"
+)
+
+// openSource opens a reader's source file, or stdin for "-"
+func openSource(source string) (io.ReadCloser, error) {
+	if source == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+
+	return os.Open(source)
+}
+
+// readSource reads all of a reader's source file, or stdin for "-"
+func readSource(source string) ([]byte, error) {
+	file, err := openSource(source)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
+
+// portFilter is a port include and exclude list, as port scanner readers
+// take them
+type portFilter struct {
+	// Ports limits targets to these ports, if any are set
+	Ports []int
+	// ExcludePorts are ports to never make targets for
+	ExcludePorts []int
+}
+
+// allows checks if a port passes the filter
+func (f portFilter) allows(port int) bool {
+	if port < 1 || port > 65535 {
+		return false
+	}
+	if len(f.Ports) > 0 && !islazy.SliceHasInt(f.Ports, port) {
+		return false
+	}
+
+	return !islazy.SliceHasInt(f.ExcludePorts, port)
+}
+
+// targetEmitter sends http and https URLs for open ports to a channel,
+// once each, as port scanners often report the same port more than once
+type targetEmitter struct {
+	ch      chan<- *Target
+	noHTTP  bool
+	noHTTPS bool
+	seen    map[string]bool
+}
+
+func newTargetEmitter(ch chan<- *Target, noHTTP, noHTTPS bool) *targetEmitter {
+	return &targetEmitter{
+		ch:      ch,
+		noHTTP:  noHTTP,
+		noHTTPS: noHTTPS,
+		seen:    make(map[string]bool),
+	}
+}
+
+func (e *targetEmitter) emit(host string, port int) {
+	if host == "" {
+		return
+	}
+
+	var schemes []string
+	if !e.noHTTP {
+		schemes = append(schemes, "http")
+	}
+	if !e.noHTTPS {
+		schemes = append(schemes, "https")
+	}
+
+	for _, scheme := range schemes {
+		target := targetURL(scheme, host, port)
+		if e.seen[target] {
+			continue
+		}
+
+		e.seen[target] = true
+		e.ch <- &Target{URL: target}
+	}
+}
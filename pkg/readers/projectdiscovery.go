@@ -0,0 +1,222 @@
+package readers
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sensepost/gowitness/pkg/log"
+)
+
+// ProjectDiscoveryReader is a reader for the JSON lines output (-json or
+// -jsonl) of ProjectDiscovery's httpx, nuclei, subfinder and dnsx. The
+// tool is detected from each line's fields, so their output can be mixed.
+type ProjectDiscoveryReader struct {
+	Options *ProjectDiscoveryReaderOptions
+}
+
+// ProjectDiscoveryReaderOptions are options for the ProjectDiscovery reader
+type ProjectDiscoveryReaderOptions struct {
+	// Path to a JSON lines file
+	Source  string
+	NoHTTP  bool
+	NoHTTPS bool
+	// Tags carries httpx statuses and nuclei template ids and severities
+	// over to results as tags
+	Tags bool
+}
+
+// pdResult is a line of httpx, nuclei, subfinder or dnsx output. The fields
+// tools share, like host, mean different things to each of them.
+type pdResult struct {
+	// httpx: the probed url. nuclei: the url of the host
+	URL string `json:"url"`
+	// httpx: the ip. nuclei: the target. subfinder and dnsx: the hostname
+	Host string `json:"host"`
+	IP   string `json:"ip"`
+	Port pdInt  `json:"port"`
+
+	// httpx's http status. dnsx writes its dns status, like NOERROR, here
+	StatusCode json.RawMessage `json:"status_code"`
+
+	// nuclei's template id was templateID in older versions
+	TemplateID    string `json:"template-id"`
+	OldTemplateID string `json:"templateID"`
+	MatchedAt     string `json:"matched-at"`
+	Info          struct {
+		Severity string `json:"severity"`
+	} `json:"info"`
+}
+
+// pdInt is a number that may be written as a string, like httpx and nuclei
+// write ports
+type pdInt int
+
+func (i *pdInt) UnmarshalJSON(data []byte) error {
+	value, err := strconv.Atoi(strings.Trim(string(data), `"`))
+	if err != nil {
+		return nil // leave invalid or empty values as 0
+	}
+	*i = pdInt(value)
+
+	return nil
+}
+
+// NewProjectDiscoveryReader prepares a new ProjectDiscovery reader
+func NewProjectDiscoveryReader(opts *ProjectDiscoveryReaderOptions) *ProjectDiscoveryReader {
+	return &ProjectDiscoveryReader{
+		Options: opts,
+	}
+}
+
+// Read a ProjectDiscovery JSON lines file. Targets are only sent once the
+// whole file is read, as tools like nuclei report the same target on many
+// lines and its tags need to be gathered first.
+func (pr *ProjectDiscoveryReader) Read(ch chan<- *Target) error {
+	defer close(ch)
+
+	file, err := openSource(pr.Options.Source)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var targets []string
+	tags := make(map[string][]string)
+	add := func(target string, targetTags ...string) {
+		existing, seen := tags[target]
+		if !seen {
+			targets = append(targets, target)
+		}
+		for _, tag := range targetTags {
+			if tag != "" && !slices.Contains(existing, tag) {
+				existing = append(existing, tag)
+			}
+		}
+		tags[target] = existing
+	}
+
+	scanner := bufio.NewScanner(file)
+	// httpx and nuclei can include whole responses in their output
+	scanner.Buffer(make([]byte, 0, 64*1024), 32*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var result pdResult
+		if err := json.Unmarshal([]byte(line), &result); err != nil {
+			log.Debug("skipping a line that is not json", "line", line, "err", err)
+			continue
+		}
+
+		switch {
+		case result.TemplateID != "" || result.OldTemplateID != "":
+			pr.addNuclei(result, add)
+		case result.URL != "":
+			pr.addHttpx(result, add)
+		case result.Host != "":
+			// subfinder and dnsx
+			for _, target := range pr.candidates(result.Host, 0) {
+				add(target)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	for _, target := range targets {
+		t := &Target{URL: target}
+		if pr.Options.Tags {
+			t.Tags = tags[target]
+		}
+		ch <- t
+	}
+
+	return nil
+}
+
+// addHttpx adds the url httpx probed, tagged with its status
+func (pr *ProjectDiscoveryReader) addHttpx(result pdResult, add func(string, ...string)) {
+	var tag string
+	var status int
+	if err := json.Unmarshal(result.StatusCode, &status); err == nil && status > 0 {
+		tag = "status:" + strconv.Itoa(status)
+	}
+
+	if isHTTPURL(result.URL) {
+		add(result.URL, tag)
+	}
+}
+
+// addNuclei adds the url a template matched at, or candidates for the host
+// of non http templates, tagged with the template id and severity
+func (pr *ProjectDiscoveryReader) addNuclei(result pdResult, add func(string, ...string)) {
+	templateID := result.TemplateID
+	if templateID == "" {
+		templateID = result.OldTemplateID
+	}
+	tags := []string{"nuclei:" + templateID}
+	if result.Info.Severity != "" {
+		tags = append(tags, "severity:"+strings.ToLower(result.Info.Severity))
+	}
+
+	for _, candidate := range []string{result.MatchedAt, result.URL, result.Host} {
+		if isHTTPURL(candidate) {
+			add(candidate, tags...)
+			return
+		}
+	}
+
+	// network, dns and ssl templates match hosts or host:port pairs
+	host, port := result.Host, int(result.Port)
+	if h, p, err := net.SplitHostPort(result.MatchedAt); err == nil {
+		host = h
+		port, _ = strconv.Atoi(p)
+	} else if h, p, err := net.SplitHostPort(result.Host); err == nil {
+		host = h
+		port, _ = strconv.Atoi(p)
+	}
+	if host == "" {
+		host = result.IP
+	}
+
+	for _, target := range pr.candidates(host, port) {
+		add(target, tags...)
+	}
+}
+
+// candidates returns the urls for a host and port, where a port of 0 is
+// the scheme's default
+func (pr *ProjectDiscoveryReader) candidates(host string, port int) []string {
+	host = strings.TrimSpace(host)
+	if host == "" {
+		return nil
+	}
+
+	var targets []string
+	if !pr.Options.NoHTTP {
+		targets = append(targets, targetURL("http", host, port))
+	}
+	if !pr.Options.NoHTTPS {
+		targets = append(targets, targetURL("https", host, port))
+	}
+
+	return targets
+}
+
+// isHTTPURL checks if a string is an http or https url
+func isHTTPURL(candidate string) bool {
+	u, err := url.Parse(candidate)
+	if err != nil {
+		return false
+	}
+
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,102 @@
+package readers
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/sensepost/gowitness/internal/islazy"
+)
+
+// proxyRequest is a request from a proxy's history, like Burp's site map.
+// Status is 0 and MIMEType empty where the proxy did not record them.
+type proxyRequest struct {
+	URL      string
+	Method   string
+	Status   int
+	MIMEType string
+}
+
+// proxyFilter filters and deduplicates requests from a proxy's history,
+// sending the ones that pass as targets
+type proxyFilter struct {
+	// Hosts limits targets to these hosts and their subdomains
+	Hosts []string
+	// Statuses limits targets to requests with these response statuses
+	Statuses []int
+	// MIMETypes limits targets to responses with a MIME type containing
+	// one of these
+	MIMETypes []string
+	// UniquePaths sends only the first request for a path, ignoring the
+	// query string, instead of the first for each URL
+	UniquePaths bool
+
+	ch   chan<- *Target
+	seen map[string]bool
+}
+
+func newProxyFilter(ch chan<- *Target) *proxyFilter {
+	return &proxyFilter{
+		ch:   ch,
+		seen: make(map[string]bool),
+	}
+}
+
+// add sends a request's URL as a target if it passes the filter and has
+// not been sent before
+func (f *proxyFilter) add(request proxyRequest) {
+	u, err := url.Parse(strings.TrimSpace(request.URL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return
+	}
+
+	if len(f.Hosts) > 0 && !f.allowsHost(u.Hostname()) {
+		return
+	}
+	if len(f.Statuses) > 0 && !islazy.SliceHasInt(f.Statuses, request.Status) {
+		return
+	}
+	if len(f.MIMETypes) > 0 && !f.allowsMIMEType(request.MIMEType) {
+		return
+	}
+
+	u.Fragment = ""
+	target := u.String()
+
+	key := target
+	if f.UniquePaths {
+		key = u.Scheme + "://" + strings.ToLower(u.Host) + u.EscapedPath()
+	}
+	if f.seen[key] {
+		return
+	}
+	f.seen[key] = true
+
+	f.ch <- &Target{URL: target, Method: strings.ToUpper(request.Method)}
+}
+
+func (f *proxyFilter) allowsHost(host string) bool {
+	host = strings.ToLower(host)
+	for _, allowed := range f.Hosts {
+		allowed = strings.ToLower(strings.TrimPrefix(allowed, "."))
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *proxyFilter) allowsMIMEType(mimeType string) bool {
+	mimeType = strings.ToLower(mimeType)
+	if mimeType == "" {
+		return false
+	}
+
+	for _, allowed := range f.MIMETypes {
+		if strings.Contains(mimeType, strings.ToLower(allowed)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,11 +1,34 @@
 package readers
 
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
 // Reader defines a reader.
 // NOTE: The Reader needs to close the channel when done to stop the runner.
 // You would typically do this with a "defer close(ch)" at the start of your
 // Read() implementation.
 type Reader interface {
-	Read(chan<- string) error
+	Read(chan<- *Target) error
+}
+
+// Target is a URL for the runner to scan, along with what the reader
+// knows about it
+type Target struct {
+	URL string
+	// Method is the method the target was originally requested with
+	Method string
+	// Tags are added to the target's result, like the status another tool
+	// saw it respond with
+	Tags []string
+	// ResultID is the result a rescan of the target replaces
+	ResultID uint
+	// IP is the address to connect to for the target's host, instead of
+	// resolving it, like for a virtual host without DNS
+	IP string
 }
 
 // port collections that readers can refer to
@@ -14,3 +37,25 @@ var (
 	medium = append(small, []int{81, 90, 591, 3000, 3128, 8000, 8008, 8081, 8082, 8834, 8888, 7015, 8800, 8990, 10000}...)
 	large  = append(medium, []int{300, 2082, 2087, 2095, 4243, 4993, 5000, 7000, 7171, 7396, 7474, 8090, 8280, 8880, 9443}...)
 )
+
+// hostPort joins a host and port for use in a URL, bracketing IPv6
+// literals. A port of 0 leaves the port out.
+func hostPort(host string, port int) string {
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+
+	if port == 0 {
+		if strings.Contains(host, ":") {
+			return "[" + host + "]"
+		}
+		return host
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// targetURL returns a URL for a scheme, host and port
+func targetURL(scheme string, host string, port int) string {
+	u := url.URL{Scheme: scheme, Host: hostPort(host, port)}
+
+	return u.String()
+}
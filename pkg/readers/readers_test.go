@@ -0,0 +1,373 @@
+package readers
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// collect runs a reader, returning the URLs of the targets it sent
+func collect(t *testing.T, reader Reader) []string {
+	t.Helper()
+
+	var urls []string
+	for _, target := range collectTargets(t, reader) {
+		urls = append(urls, target.URL)
+	}
+
+	return urls
+}
+
+// collectTargets runs a reader, returning the targets it sent
+func collectTargets(t *testing.T, reader Reader) []*Target {
+	t.Helper()
+
+	ch := make(chan *Target)
+	errs := make(chan error, 1)
+	go func() { errs <- reader.Read(ch) }()
+
+	var targets []*Target
+	for target := range ch {
+		targets = append(targets, target)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	return targets
+}
+
+func TestCidrReaderIPv6(t *testing.T) {
+	reader := NewCidrReader(&CidrReaderOptions{
+		Cidrs:   []string{"2001:db8::/126", "2001:db8::ff", "10.0.0.1"},
+		Ports:   []int{8080},
+		NoHTTPS: true,
+	})
+
+	want := []string{
+		"http://[2001:db8::1]:8080",
+		"http://[2001:db8::2]:8080",
+		"http://[2001:db8::3]:8080",
+		"http://[2001:db8::ff]:8080",
+		"http://10.0.0.1:8080",
+	}
+	if got := collect(t, reader); !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() =\nhave: %v\nwant: %v", got, want)
+	}
+}
+
+func TestCidrReaderRandom(t *testing.T) {
+	opts := &CidrReaderOptions{Cidrs: []string{"10.0.0.0/24", "2001:db8::/120"}, Ports: []int{80, 8080}}
+
+	ordered := collect(t, NewCidrReader(opts))
+	opts.Random = true
+	shuffled := collect(t, NewCidrReader(opts))
+
+	if len(ordered) != (254+255)*2*2 {
+		t.Fatalf("Read() returned %d targets, want %d", len(ordered), (254+255)*2*2)
+	}
+	if slices.Equal(ordered, shuffled) {
+		t.Error("Read() with Random returned targets in order")
+	}
+
+	slices.Sort(ordered)
+	slices.Sort(shuffled)
+	if !slices.Equal(ordered, shuffled) {
+		t.Error("Read() with Random returned different targets")
+	}
+}
+
+func TestCidrReaderTooLarge(t *testing.T) {
+	reader := NewCidrReader(&CidrReaderOptions{Cidrs: []string{"2001:db8::/64"}, Ports: []int{80}})
+
+	ch := make(chan *Target)
+	go func() {
+		for range ch {
+		}
+	}()
+	if err := reader.Read(ch); err == nil {
+		t.Error("Read() error = nil, want an error for a /64")
+	}
+}
+
+func TestNmapReaderIPv6(t *testing.T) {
+	reader := NewNmapReader(&NmapReaderOptions{
+		Source:    "testdata/nmap.xml",
+		Services:  []string{"http", "https"},
+		Hostnames: true,
+	})
+
+	want := []string{
+		"http://web.example.com:80",
+		"https://web.example.com:80",
+		"http://192.168.1.10:80",
+		"https://192.168.1.10:80",
+		"http://[2001:db8::10]:8443",
+		"https://[2001:db8::10]:8443",
+	}
+	if got := collect(t, reader); !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() =\nhave: %v\nwant: %v", got, want)
+	}
+}
+
+func TestNessusReaderIPv6(t *testing.T) {
+	reader := NewNessusReader(&NessusReaderOptions{
+		Source:      "testdata/scan.nessus",
+		Hostnames:   true,
+		Services:    []string{"www"},
+		PluginNames: []string{"Service Detection"},
+		NoHTTP:      true,
+	})
+
+	// targets are collected in a map, so their order varies
+	got := collect(t, reader)
+	slices.Sort(got)
+
+	want := []string{
+		"https://192.168.1.20:8080",
+		"https://[2001:db8::20]:443",
+		"https://app.example.com:443",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() =\nhave: %v\nwant: %v", got, want)
+	}
+}
+
+func TestMasscanReader(t *testing.T) {
+	for _, source := range []string{"testdata/masscan.xml", "testdata/masscan.json", "testdata/masscan.txt"} {
+		t.Run(source, func(t *testing.T) {
+			reader := NewMasscanReader(&MasscanReaderOptions{
+				Source:       source,
+				NoHTTPS:      true,
+				ExcludePorts: []int{22},
+			})
+
+			got := collect(t, reader)
+			if len(got) != 2 || got[0] != "http://10.0.0.1:80" || !slices.Contains(
+				[]string{"http://10.0.0.2:8443", "http://[2001:db8::2]:8443"}, got[1]) {
+				t.Errorf("Read() = %v, want the open tcp ports that are not excluded, once each", got)
+			}
+		})
+	}
+}
+
+func TestNaabuReader(t *testing.T) {
+	reader := NewNaabuReader(&NaabuReaderOptions{
+		Source:       "testdata/naabu.jsonl",
+		NoHTTP:       true,
+		ExcludePorts: []int{22},
+		Hostnames:    true,
+	})
+
+	want := []string{
+		"https://app.example.com:80",
+		"https://10.0.0.1:80",
+		"https://[2001:db8::2]:8443",
+		"https://10.0.0.3:8080",
+	}
+	if got := collect(t, reader); !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() =\nhave: %v\nwant: %v", got, want)
+	}
+}
+
+func TestZmapReader(t *testing.T) {
+	csv := NewZmapReader(&ZmapReaderOptions{Source: "testdata/zmap.csv", NoHTTPS: true, Ports: []int{80}})
+	if got, want := collect(t, csv), []string{"http://10.0.0.1:80"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() = %v, want %v", got, want)
+	}
+
+	list := NewZmapReader(&ZmapReaderOptions{Source: "testdata/zmap.txt", NoHTTP: true, ScannedPorts: []int{443}})
+	if got, want := collect(t, list), []string{"https://10.0.0.1:443", "https://10.0.0.2:443"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() = %v, want %v", got, want)
+	}
+
+	ch := make(chan *Target)
+	if err := NewZmapReader(&ZmapReaderOptions{Source: "testdata/zmap.txt"}).Read(ch); err == nil {
+		t.Error("Read() error = nil, want an error without scanned ports")
+	}
+}
+
+func TestBurpReader(t *testing.T) {
+	reader := NewBurpReader(&BurpReaderOptions{
+		Source:   "testdata/burp.xml",
+		Hosts:    []string{"example.com"},
+		Statuses: []int{200, 302},
+	})
+
+	want := []string{
+		"https://app.example.com/login?next=%2F",
+		"https://app.example.com/login",
+		"https://app.example.com/static/app.js",
+	}
+	var got []string
+	methods := map[string]string{}
+	for _, target := range collectTargets(t, reader) {
+		got = append(got, target.URL)
+		methods[target.URL] = target.Method
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() =\nhave: %v\nwant: %v", got, want)
+	}
+	if methods["https://app.example.com/login"] != "POST" {
+		t.Errorf("Read() annotated methods %v, want POST for /login", methods)
+	}
+
+	paths := NewBurpReader(&BurpReaderOptions{
+		Source:      "testdata/burp.xml",
+		MIMETypes:   []string{"html"},
+		UniquePaths: true,
+	})
+	if got, want := collect(t, paths), []string{"https://app.example.com/login?next=%2F"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() =\nhave: %v\nwant: %v", got, want)
+	}
+}
+
+func TestZapReader(t *testing.T) {
+	for _, source := range []string{"testdata/zap.xml", "testdata/zap.json"} {
+		t.Run(source, func(t *testing.T) {
+			reader := NewZapReader(&ZapReaderOptions{Source: source, UniquePaths: true})
+			want := []string{
+				"https://app.example.com",
+				"https://app.example.com/login",
+			}
+			got := collect(t, reader)
+			if len(got) != 3 || !reflect.DeepEqual(got[:2], want) || !strings.HasPrefix(got[2], "https://app.example.com/api/users?page=") {
+				t.Errorf("Read() =\nhave: %v\nwant: %v and one /api/users", got, want)
+			}
+		})
+	}
+
+	// only the plus reports have response headers to filter on
+	reader := NewZapReader(&ZapReaderOptions{Source: "testdata/zap.json", MIMETypes: []string{"text/html"}})
+	if got, want := collect(t, reader), []string{"https://app.example.com/login"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() =\nhave: %v\nwant: %v", got, want)
+	}
+}
+
+func TestProjectDiscoveryReader(t *testing.T) {
+	reader := NewProjectDiscoveryReader(&ProjectDiscoveryReaderOptions{
+		Source: "testdata/projectdiscovery.jsonl",
+		NoHTTP: true,
+		Tags:   true,
+	})
+
+	want := []string{
+		"https://app.example.com",
+		"http://10.0.0.2:8080",
+		"https://app.example.com/admin/",
+		"https://mail.example.com:8443",
+		"https://dev.example.com",
+	}
+	var got []string
+	tags := map[string][]string{}
+	for _, target := range collectTargets(t, reader) {
+		got = append(got, target.URL)
+		if len(target.Tags) > 0 {
+			tags[target.URL] = target.Tags
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() =\nhave: %v\nwant: %v", got, want)
+	}
+
+	wantTags := map[string][]string{
+		"https://app.example.com":        {"status:200", "nuclei:tech-detect", "severity:info"},
+		"http://10.0.0.2:8080":           {"status:401"},
+		"https://app.example.com/admin/": {"nuclei:exposed-panel", "severity:medium"},
+		"https://mail.example.com:8443":  {"nuclei:ssl-dns-names", "severity:info"},
+	}
+	if !reflect.DeepEqual(tags, wantTags) {
+		t.Errorf("Read() tags =\nhave: %v\nwant: %v", tags, wantTags)
+	}
+}
+
+func TestResultsReader(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"is:failed", []string{"https://a.example.com"}},
+		{"code=401 OR tech:apache", []string{"https://b.example.com", "https://c.example.com"}},
+		{"", []string{"https://a.example.com", "https://b.example.com", "https://c.example.com"}},
+	}
+
+	for _, tt := range tests {
+		reader := NewResultsReader(&ResultsReaderOptions{JsonlFile: "testdata/results.jsonl", Query: tt.query})
+		if got := collect(t, reader); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Read() with query %q =\nhave: %v\nwant: %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestResultsReaderUpdate(t *testing.T) {
+	reader := NewResultsReader(&ResultsReaderOptions{JsonlFile: "testdata/results.jsonl", Update: true})
+
+	// a.example.com is in the file twice, so it rescans the later result
+	got := map[string]uint{}
+	for _, target := range collectTargets(t, reader) {
+		got[target.URL] = target.ResultID
+	}
+	want := map[string]uint{
+		"https://a.example.com": 4,
+		"https://b.example.com": 2,
+		"https://c.example.com": 3,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() result ids =\nhave: %v\nwant: %v", got, want)
+	}
+}
+
+func TestResultsReaderConcatenated(t *testing.T) {
+	// results written alongside different databases, so their related rows
+	// have the same ids
+	var concatenated []byte
+	for _, name := range []string{"testdata/results-db-a.jsonl", "testdata/results-db-b.jsonl"} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("could not read %s: %v", name, err)
+		}
+		concatenated = append(concatenated, data...)
+	}
+	path := filepath.Join(t.TempDir(), "results.jsonl")
+	if err := os.WriteFile(path, concatenated, 0o644); err != nil {
+		t.Fatalf("could not write the concatenated results: %v", err)
+	}
+
+	reader := NewResultsReader(&ResultsReaderOptions{JsonlFile: path, Query: "tech:nginx"})
+	want := []string{"https://app.example.com", "https://admin.example.com"}
+	if got := collect(t, reader); !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() =\nhave: %v\nwant: %v", got, want)
+	}
+}
+
+func TestVHostReader(t *testing.T) {
+	reader := NewVHostReader(&VHostReaderOptions{
+		IPs:       []string{"10.0.0.1", "2001:db8::1"},
+		Hostnames: []string{"app.internal", "Admin.Internal.", "app.internal"},
+		NoHTTP:    true,
+	})
+
+	want := []string{
+		"https://10.0.0.1",
+		"https://app.internal",
+		"https://admin.internal",
+		"https://[2001:db8::1]",
+		"https://app.internal",
+		"https://admin.internal",
+	}
+	var got []string
+	ips := map[string][]string{}
+	for _, target := range collectTargets(t, reader) {
+		got = append(got, target.URL)
+		ips[target.URL] = append(ips[target.URL], target.IP)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() =\nhave: %v\nwant: %v", got, want)
+	}
+	if got := ips["https://app.internal"]; !reflect.DeepEqual(got, []string{"10.0.0.1", "2001:db8::1"}) {
+		t.Errorf("Read() ips %v for https://app.internal", got)
+	}
+}
@@ -0,0 +1,228 @@
+package readers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sensepost/gowitness/pkg/database"
+	"github.com/sensepost/gowitness/pkg/log"
+	"github.com/sensepost/gowitness/pkg/models"
+	"github.com/sensepost/gowitness/pkg/search"
+	"gorm.io/gorm"
+)
+
+// ResultsReader is a reader for the results of earlier scans. It selects
+// the URLs of results in a gowitness database or JSON lines file that
+// match a search query, so that they can be scanned again.
+type ResultsReader struct {
+	Options *ResultsReaderOptions
+}
+
+// ResultsReaderOptions are options for the results reader
+type ResultsReaderOptions struct {
+	// DbURI is the database to read results from
+	DbURI string
+	// JsonlFile is a JSON lines file to read results from instead
+	JsonlFile string
+	// Query selects the results to scan again. Empty selects all of them
+	Query string
+	// Update sends each target with the result it rescans, so that a
+	// database writer replaces it instead of adding a new result
+	Update bool
+}
+
+// NewResultsReader prepares a new results reader
+func NewResultsReader(opts *ResultsReaderOptions) *ResultsReader {
+	return &ResultsReader{
+		Options: opts,
+	}
+}
+
+// Read the URLs of matching results. A URL with more than one matching
+// result is sent once, and rescans its latest result.
+func (rr *ResultsReader) Read(ch chan<- *Target) error {
+	defer close(ch)
+
+	query, err := search.Parse(rr.Options.Query)
+	if err != nil {
+		return err
+	}
+
+	conn, cleanup, err := rr.connect()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	var matches []struct {
+		ID  uint
+		URL string
+	}
+	if err := search.Apply(conn.Model(&models.Result{}), query).
+		Select("id", "url").Order("id").Find(&matches).Error; err != nil {
+		return err
+	}
+
+	var urls []string
+	latest := make(map[string]uint)
+	for _, match := range matches {
+		if _, ok := latest[match.URL]; !ok {
+			urls = append(urls, match.URL)
+		}
+		latest[match.URL] = match.ID
+	}
+
+	log.Info("selected results to scan again", "results", len(matches), "targets", len(urls))
+
+	for _, url := range urls {
+		target := &Target{URL: url}
+		if rr.Options.Update {
+			target.ResultID = latest[url]
+		}
+		ch <- target
+	}
+
+	return nil
+}
+
+// connect connects to the results database. JSON lines results are loaded
+// into a temporary SQLite database first, so that they can be searched.
+func (rr *ResultsReader) connect() (*gorm.DB, func(), error) {
+	if rr.Options.JsonlFile == "" {
+		conn, err := database.Connection(rr.Options.DbURI, true, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		return conn, func() { closeConnection(conn) }, nil
+	}
+
+	dir, err := os.MkdirTemp("", "gowitness-results-")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	conn, err := database.Connection(fmt.Sprintf("sqlite://%s", filepath.Join(dir, "results.sqlite3")), false, false)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	if err := loadJsonl(conn, rr.Options.JsonlFile); err != nil {
+		closeConnection(conn)
+		cleanup()
+		return nil, nil, err
+	}
+
+	return conn, func() {
+		closeConnection(conn)
+		cleanup()
+	}, nil
+}
+
+// loadJsonl inserts the results in a JSON lines file into a database
+func loadJsonl(conn *gorm.DB, path string) error {
+	file, err := openSource(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	// results include the page's html
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var result models.Result
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			log.Error("could not unmarshal JSON line", "err", err)
+			continue
+		}
+
+		// results written without a database all have ids of 0, and files
+		// written alongside different databases can be concatenated, so
+		// the ids of results and their related rows are reassigned
+		resetIDs(&result)
+		if err := conn.Create(&result).Error; err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// resetIDs clears the ids of a result and of its related rows, so that
+// they are assigned new ones when the result is created
+func resetIDs(result *models.Result) {
+	result.ID = 0
+
+	result.TLS.ID = 0
+	for i := range result.TLS.SanList {
+		result.TLS.SanList[i].ID = 0
+		result.TLS.SanList[i].TLSID = 0
+	}
+	result.Favicon.ID = 0
+	for i := range result.ImageHashes {
+		result.ImageHashes[i].ID = 0
+	}
+	for i := range result.Tags {
+		result.Tags[i].ID = 0
+	}
+	for i := range result.Categories {
+		result.Categories[i].ID = 0
+	}
+	for i := range result.DefaultCredentials {
+		result.DefaultCredentials[i].ID = 0
+	}
+	for i := range result.Technologies {
+		result.Technologies[i].ID = 0
+	}
+	for i := range result.Headers {
+		result.Headers[i].ID = 0
+	}
+	for i := range result.Network {
+		result.Network[i].ID = 0
+	}
+	for i := range result.Console {
+		result.Console[i].ID = 0
+	}
+	for i := range result.Cookies {
+		result.Cookies[i].ID = 0
+	}
+	for i := range result.Links {
+		result.Links[i].ID = 0
+	}
+	for i := range result.Forms {
+		result.Forms[i].ID = 0
+		for j := range result.Forms[i].Inputs {
+			result.Forms[i].Inputs[j].ID = 0
+			result.Forms[i].Inputs[j].FormID = 0
+		}
+	}
+	for i := range result.Scripts {
+		result.Scripts[i].ID = 0
+	}
+	for i := range result.MetaTags {
+		result.MetaTags[i].ID = 0
+	}
+	for i := range result.BlockedRequests {
+		result.BlockedRequests[i].ID = 0
+	}
+}
+
+func closeConnection(conn *gorm.DB) {
+	db, err := conn.DB()
+	if err != nil {
+		return
+	}
+
+	if err := db.Close(); err != nil {
+		log.Debug("could not close the results database", "err", err)
+	}
+}
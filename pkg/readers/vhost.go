@@ -0,0 +1,141 @@
+package readers
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+
+	"github.com/sensepost/gowitness/internal/islazy"
+)
+
+// VHostReader is a virtual host reader. It pairs IPs with hostnames, so
+// that each hostname is requested from each IP, no matter what DNS says.
+type VHostReader struct {
+	Options *VHostReaderOptions
+}
+
+// VHostReaderOptions are options for the virtual host reader
+type VHostReaderOptions struct {
+	// IPs (or CIDRs) to request the hostnames from
+	IPs []string
+	// IPsFile is a file with more IPs, one per line
+	IPsFile string
+	// Hostnames to request from each IP
+	Hostnames []string
+	// HostnamesFile is a file with more hostnames, one per line
+	HostnamesFile string
+	NoHTTP        bool
+	NoHTTPS       bool
+	// Ports to request hostnames on. Without any, the scheme's default
+	// port is used
+	Ports []int
+	// NoBaseline skips requesting each IP without a hostname, which is the
+	// response the virtual hosts are compared to
+	NoBaseline bool
+}
+
+// NewVHostReader prepares a new virtual host reader
+func NewVHostReader(opts *VHostReaderOptions) *VHostReader {
+	return &VHostReader{
+		Options: opts,
+	}
+}
+
+// Read the targets for every IP, port, scheme and hostname combination
+func (vr *VHostReader) Read(ch chan<- *Target) error {
+	defer close(ch)
+
+	ipEntries, err := withLines(vr.Options.IPs, vr.Options.IPsFile)
+	if err != nil {
+		return err
+	}
+	var cidrs []islazy.CIDR
+	for _, entry := range ipEntries {
+		cidr, err := islazy.ParseCIDR(entry)
+		if err != nil {
+			return err
+		}
+		cidrs = append(cidrs, cidr)
+	}
+
+	hostEntries, err := withLines(vr.Options.Hostnames, vr.Options.HostnamesFile)
+	if err != nil {
+		return err
+	}
+	var hostnames []string
+	seen := make(map[string]bool)
+	for _, entry := range hostEntries {
+		hostname := strings.TrimSuffix(strings.ToLower(entry), ".")
+		if hostname == "" || seen[hostname] {
+			continue
+		}
+		seen[hostname] = true
+		hostnames = append(hostnames, hostname)
+	}
+	if len(hostnames) == 0 {
+		return errors.New("no hostnames to request")
+	}
+
+	var schemes []string
+	if !vr.Options.NoHTTP {
+		schemes = append(schemes, "http")
+	}
+	if !vr.Options.NoHTTPS {
+		schemes = append(schemes, "https")
+	}
+
+	// port 0 leaves the port out of urls
+	ports := islazy.UniqueIntSlice(vr.Options.Ports)
+	if len(ports) == 0 {
+		ports = []int{0}
+	}
+
+	for _, cidr := range cidrs {
+		for addr := range cidr.All() {
+			ip := addr.String()
+			for _, port := range ports {
+				for _, scheme := range schemes {
+					if !vr.Options.NoBaseline {
+						ch <- &Target{URL: targetURL(scheme, ip, port)}
+					}
+
+					for _, hostname := range hostnames {
+						ch <- &Target{URL: targetURL(scheme, hostname, port), IP: ip}
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// withLines returns entries along with the lines in a file, if there is
+// one. Blank lines and # comments in the file are ignored.
+func withLines(entries []string, path string) ([]string, error) {
+	var lines []string
+	for _, entry := range entries {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			lines = append(lines, entry)
+		}
+	}
+
+	if path == "" {
+		return lines, nil
+	}
+
+	file, err := openSource(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines, scanner.Err()
+}
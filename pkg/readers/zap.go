@@ -0,0 +1,172 @@
+package readers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strconv"
+	"strings"
+)
+
+// ZapReader is an OWASP ZAP results reader. It reads ZAP's traditional XML
+// and JSON reports, and their "plus" variants that include the request and
+// response headers.
+type ZapReader struct {
+	Options *ZapReaderOptions
+}
+
+// ZapReaderOptions are options for the ZAP reader
+type ZapReaderOptions struct {
+	// Path to a ZAP report
+	Source string
+	// Hosts to limit targets to, along with their subdomains
+	Hosts []string
+	// Response statuses to limit targets to
+	Statuses []int
+	// MIME types to limit targets to, like html
+	MIMETypes []string
+	// UniquePaths deduplicates targets by path instead of by URL
+	UniquePaths bool
+}
+
+// structures for zap report parsing
+type zapXMLSite struct {
+	Name   string `xml:"name,attr"`
+	Alerts []struct {
+		Instances []struct {
+			URI            string `xml:"uri"`
+			Method         string `xml:"method"`
+			ResponseHeader string `xml:"responseheader"`
+		} `xml:"instances>instance"`
+	} `xml:"alerts>alertitem"`
+}
+
+type zapJSONSite struct {
+	Name   string `json:"@name"`
+	Alerts []struct {
+		Instances []struct {
+			URI            string `json:"uri"`
+			Method         string `json:"method"`
+			ResponseHeader string `json:"response-header"`
+		} `json:"instances"`
+	} `json:"alerts"`
+}
+
+// NewZapReader prepares a new ZAP reader
+func NewZapReader(opts *ZapReaderOptions) *ZapReader {
+	return &ZapReader{
+		Options: opts,
+	}
+}
+
+// Read a ZAP report, detecting its format
+func (zr *ZapReader) Read(ch chan<- *Target) error {
+	defer close(ch)
+
+	data, err := readSource(zr.Options.Source)
+	if err != nil {
+		return err
+	}
+
+	filter := newProxyFilter(ch)
+	filter.Hosts = zr.Options.Hosts
+	filter.Statuses = zr.Options.Statuses
+	filter.MIMETypes = zr.Options.MIMETypes
+	filter.UniquePaths = zr.Options.UniquePaths
+
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		return zr.readJSON(trimmed, filter)
+	}
+
+	return zr.readXML(trimmed, filter)
+}
+
+func (zr *ZapReader) readXML(data []byte, filter *proxyFilter) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil || token == nil {
+			break // EOF or error
+		}
+
+		element, ok := token.(xml.StartElement)
+		if !ok || element.Name.Local != "site" {
+			continue
+		}
+
+		var site zapXMLSite
+		if err := decoder.DecodeElement(&site, &element); err != nil {
+			return err
+		}
+
+		filter.add(proxyRequest{URL: site.Name})
+		for _, alert := range site.Alerts {
+			for _, instance := range alert.Instances {
+				filter.add(zapRequest(instance.URI, instance.Method, instance.ResponseHeader))
+			}
+		}
+	}
+
+	return nil
+}
+
+func (zr *ZapReader) readJSON(data []byte, filter *proxyFilter) error {
+	var report struct {
+		Site json.RawMessage `json:"site"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return err
+	}
+
+	// older versions of zap write a single site as an object
+	var sites []zapJSONSite
+	if bytes.HasPrefix(bytes.TrimSpace(report.Site), []byte("{")) {
+		var site zapJSONSite
+		if err := json.Unmarshal(report.Site, &site); err != nil {
+			return err
+		}
+		sites = append(sites, site)
+	} else if len(report.Site) > 0 {
+		if err := json.Unmarshal(report.Site, &sites); err != nil {
+			return err
+		}
+	}
+
+	for _, site := range sites {
+		filter.add(proxyRequest{URL: site.Name})
+		for _, alert := range site.Alerts {
+			for _, instance := range alert.Instances {
+				filter.add(zapRequest(instance.URI, instance.Method, instance.ResponseHeader))
+			}
+		}
+	}
+
+	return nil
+}
+
+// zapRequest makes a proxy request from an alert instance, taking the
+// status and MIME type from its response header where the report has it
+func zapRequest(uri string, method string, responseHeader string) proxyRequest {
+	request := proxyRequest{
+		URL:    strings.TrimSpace(uri),
+		Method: strings.TrimSpace(method),
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(responseHeader))
+	if scanner.Scan() {
+		// a status line, like HTTP/1.1 200 OK
+		if fields := strings.Fields(scanner.Text()); len(fields) > 1 {
+			request.Status, _ = strconv.Atoi(fields[1])
+		}
+	}
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(scanner.Text(), ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "content-type") {
+			request.MIMEType = strings.TrimSpace(value)
+		}
+	}
+
+	return request
+}
@@ -0,0 +1,117 @@
+package readers
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ZmapReader is a ZMap results reader. It reads ZMap's CSV output, with a
+// header naming the saddr (and optionally sport) fields, or its default
+// output of one address per line.
+type ZmapReader struct {
+	Options *ZmapReaderOptions
+}
+
+// ZmapReaderOptions are options for the ZMap reader
+type ZmapReaderOptions struct {
+	// Path to a ZMap output file
+	Source  string
+	NoHTTP  bool
+	NoHTTPS bool
+	// ScannedPorts are the ports ZMap scanned (-p), used when the output
+	// has no sport field
+	ScannedPorts []int
+	// Ports to limit scans to
+	Ports []int
+	// Ports to exclude, no matter what
+	ExcludePorts []int
+}
+
+// NewZmapReader prepares a new ZMap reader
+func NewZmapReader(opts *ZmapReaderOptions) *ZmapReader {
+	return &ZmapReader{
+		Options: opts,
+	}
+}
+
+// Read a ZMap file
+func (zr *ZmapReader) Read(ch chan<- *Target) error {
+	defer close(ch)
+
+	file, err := openSource(zr.Options.Source)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	emitter := newTargetEmitter(ch, zr.Options.NoHTTP, zr.Options.NoHTTPS)
+	filter := portFilter{Ports: zr.Options.Ports, ExcludePorts: zr.Options.ExcludePorts}
+
+	records := csv.NewReader(file)
+	records.FieldsPerRecord = -1
+	records.Comment = '#'
+
+	// without a header, the only field is the address
+	addrField, portField, successField := 0, -1, -1
+	first := true
+
+	for {
+		record, err := records.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if first {
+			first = false
+			if saddr := slices.Index(record, "saddr"); saddr != -1 {
+				addrField = saddr
+				portField = slices.Index(record, "sport")
+				successField = slices.Index(record, "success")
+				continue
+			}
+		}
+
+		if addrField >= len(record) {
+			continue
+		}
+		addr := strings.TrimSpace(record[addrField])
+		if net.ParseIP(addr) == nil {
+			continue
+		}
+
+		// zmap marks probes that got a response other than a syn-ack
+		// (like a rst) as unsuccessful
+		if successField != -1 && successField < len(record) &&
+			!slices.Contains([]string{"1", "true"}, strings.TrimSpace(record[successField])) {
+			continue
+		}
+
+		ports := zr.Options.ScannedPorts
+		if portField != -1 && portField < len(record) {
+			port, err := strconv.Atoi(strings.TrimSpace(record[portField]))
+			if err != nil {
+				continue
+			}
+			ports = []int{port}
+		}
+		if len(ports) == 0 {
+			return errors.New("zmap output has no sport field, so the scanned ports need to be given")
+		}
+
+		for _, port := range ports {
+			if filter.allows(port) {
+				emitter.emit(addr, port)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// errNoAddress is returned when a DNS server has no address for a host
+var errNoAddress = errors.New("no address for host")
+
+// doh is a DNS over HTTPS (RFC 8484) client
+type doh struct {
+	template string
+	endpoint string
+	client   *http.Client
+
+	mu    sync.Mutex
+	cache map[string]cachedIP
+}
+
+// cachedIP is a resolved address, kept until its record expires
+type cachedIP struct {
+	ip      string
+	expires time.Time
+}
+
+// newDoH returns a client for a DNS over HTTPS server URL template, like
+// https://dns.example/dns-query{?dns}
+func newDoH(template string, timeout time.Duration) (*doh, error) {
+	// queries are POSTed, so the template's dns variable is not needed
+	endpoint := strings.Replace(template, "{?dns}", "", 1)
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dns server: %w", err)
+	}
+	if u.Scheme != "https" || u.Host == "" {
+		return nil, errors.New("the dns server must be a DNS over HTTPS url, like https://dns.example/dns-query")
+	}
+
+	return &doh{
+		template: template,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: timeout},
+		cache:    make(map[string]cachedIP),
+	}, nil
+}
+
+// lookup resolves host to an IPv4 address, or an IPv6 address if it has
+// none
+func (d *doh) lookup(ctx context.Context, host string) (string, error) {
+	d.mu.Lock()
+	cached, ok := d.cache[host]
+	d.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.ip, nil
+	}
+
+	var err error
+	for _, qtype := range []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA} {
+		var ip net.IP
+		var ttl uint32
+		if ip, ttl, err = d.query(ctx, host, qtype); err == nil {
+			d.mu.Lock()
+			d.cache[host] = cachedIP{ip: ip.String(), expires: time.Now().Add(time.Duration(ttl) * time.Second)}
+			d.mu.Unlock()
+
+			return ip.String(), nil
+		}
+		if !errors.Is(err, errNoAddress) {
+			break
+		}
+	}
+
+	return "", fmt.Errorf("could not resolve %s: %w", host, err)
+}
+
+// query asks the server for a host's records of a type, returning the
+// first address in the answer
+func (d *doh) query(ctx context.Context, host string, qtype dnsmessage.Type) (net.IP, uint32, error) {
+	name, err := dnsmessage.NewName(host + ".")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// the id is 0, as the http request and response are already paired
+	query, err := (&dnsmessage.Message{
+		Header:    dnsmessage.Header{RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: name, Type: qtype, Class: dnsmessage.ClassINET}},
+	}).Pack()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.endpoint, bytes.NewReader(query))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("dns server returned %s", res.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 64*1024))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var answer dnsmessage.Message
+	if err := answer.Unpack(body); err != nil {
+		return nil, 0, err
+	}
+	if answer.RCode != dnsmessage.RCodeSuccess && answer.RCode != dnsmessage.RCodeNameError {
+		return nil, 0, fmt.Errorf("dns server returned %s", answer.RCode)
+	}
+
+	// cname records may come first, with the addresses of their target
+	// after them
+	for _, resource := range answer.Answers {
+		switch body := resource.Body.(type) {
+		case *dnsmessage.AResource:
+			return net.IP(body.A[:]), resource.Header.TTL, nil
+		case *dnsmessage.AAAAResource:
+			return net.IP(body.AAAA[:]), resource.Header.TTL, nil
+		}
+	}
+
+	return nil, 0, errNoAddress
+}
@@ -0,0 +1,53 @@
+package resolver
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+)
+
+// LoadHosts reads a hosts file
+func LoadHosts(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ParseHosts(file)
+}
+
+// ParseHosts reads hosts in the /etc/hosts format, an IP followed by the
+// names that resolve to it. The first IP given for a name is used.
+func ParseHosts(r io.Reader) (map[string]string, error) {
+	hosts := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		entry, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+
+		ip := net.ParseIP(fields[0])
+		if ip == nil {
+			return nil, fmt.Errorf("hosts line %d: %q is not an ip", line, fields[0])
+		}
+		if len(fields) == 1 {
+			return nil, fmt.Errorf("hosts line %d: no hostnames for %s", line, fields[0])
+		}
+
+		for _, name := range fields[1:] {
+			name = normalize(name)
+			if _, ok := hosts[name]; !ok {
+				hosts[name] = ip.String()
+			}
+		}
+	}
+
+	return hosts, scanner.Err()
+}
@@ -0,0 +1,183 @@
+// Package resolver makes connections to a host go to a chosen address
+// instead of the one DNS gives, like for a virtual host on an IP that has
+// no DNS records, or a name that resolves differently on the client's
+// network.
+package resolver
+
+import (
+	"context"
+	"maps"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Resolver resolves hosts the way a scan's browser does, using a hosts
+// file and a DNS over HTTPS server instead of the system resolver
+type Resolver struct {
+	hosts map[string]string
+	doh   *doh
+}
+
+// New returns a Resolver for a hosts file and DNS over HTTPS server URL
+// template. Either may be empty, and it returns nil if both are.
+func New(hostsFile string, dnsServer string, timeout time.Duration) (*Resolver, error) {
+	if hostsFile == "" && dnsServer == "" {
+		return nil, nil
+	}
+
+	r := &Resolver{}
+	if hostsFile != "" {
+		hosts, err := LoadHosts(hostsFile)
+		if err != nil {
+			return nil, err
+		}
+		r.hosts = hosts
+	}
+
+	if dnsServer != "" {
+		doh, err := newDoH(dnsServer, timeout)
+		if err != nil {
+			return nil, err
+		}
+		r.doh = doh
+	}
+
+	return r, nil
+}
+
+// Hosts returns the hosts file's mappings of hosts to IPs
+func (r *Resolver) Hosts() map[string]string {
+	if r == nil {
+		return nil
+	}
+
+	return r.hosts
+}
+
+// DNSServer returns the DNS over HTTPS server URL template, if any
+func (r *Resolver) DNSServer() string {
+	if r == nil || r.doh == nil {
+		return ""
+	}
+
+	return r.doh.template
+}
+
+// LookupIP returns the address of host, from the hosts file or the DNS
+// over HTTPS server. ok is false if neither resolves it, and the system
+// resolver should be used.
+func (r *Resolver) LookupIP(ctx context.Context, host string) (ip string, ok bool, err error) {
+	host = normalize(host)
+	if r == nil || net.ParseIP(host) != nil {
+		return "", false, nil
+	}
+
+	if ip, ok := r.hosts[host]; ok {
+		return ip, true, nil
+	}
+
+	if r.doh == nil {
+		return "", false, nil
+	}
+
+	ip, err = r.doh.lookup(ctx, host)
+	if err != nil {
+		return "", false, err
+	}
+
+	return ip, true, nil
+}
+
+type hostsKey struct{}
+
+// WithHost returns a context in which connections to host go to ip
+func WithHost(ctx context.Context, host string, ip string) context.Context {
+	hosts := make(map[string]string)
+	if parent, ok := ctx.Value(hostsKey{}).(map[string]string); ok {
+		maps.Copy(hosts, parent)
+	}
+	hosts[normalize(host)] = ip
+
+	return context.WithValue(ctx, hostsKey{}, hosts)
+}
+
+// HostIP returns the address a context sends connections to host to
+func HostIP(ctx context.Context, host string) (string, bool) {
+	hosts, ok := ctx.Value(hostsKey{}).(map[string]string)
+	if !ok {
+		return "", false
+	}
+
+	ip, ok := hosts[normalize(host)]
+	return ip, ok
+}
+
+// DialContext wraps a dial function, so that connections to the hosts a
+// context maps go to their address. Other hosts are resolved with r,
+// which may be nil to leave them to the system resolver.
+func DialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error), r *Resolver) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return dial(ctx, network, addr)
+		}
+
+		if ip, ok := HostIP(ctx, host); ok {
+			return dial(ctx, network, net.JoinHostPort(ip, port))
+		}
+
+		ip, ok, err := r.LookupIP(ctx, host)
+		if err != nil {
+			return nil, &net.OpError{Op: "dial", Net: network, Err: err}
+		}
+		if ok {
+			addr = net.JoinHostPort(ip, port)
+		}
+
+		return dial(ctx, network, addr)
+	}
+}
+
+// transport sends requests with contexts that map hosts over connections
+// that are not reused, as idle connections are pooled by host rather than
+// by the address they were made to
+type transport struct {
+	pooled   *http.Transport
+	unpooled *http.Transport
+}
+
+// NewTransport returns a round tripper that applies the host mappings of
+// request contexts to connections made by base, resolving other hosts
+// with r if it is not nil. Requests to a proxy are not affected, as the
+// proxy resolves their host.
+func NewTransport(base *http.Transport, r *Resolver) http.RoundTripper {
+	dial := base.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+
+	pooled := base.Clone()
+	pooled.DialContext = DialContext(dial, r)
+
+	unpooled := pooled.Clone()
+	unpooled.DisableKeepAlives = true
+
+	return &transport{pooled: pooled, unpooled: unpooled}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if _, ok := HostIP(req.Context(), req.URL.Hostname()); ok {
+		return t.unpooled.RoundTrip(req)
+	}
+
+	return t.pooled.RoundTrip(req)
+}
+
+// normalize lower cases a host, and strips the brackets of IPv6 literals
+// and the trailing dot of fully qualified names
+func normalize(host string) string {
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
@@ -0,0 +1,108 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Host)
+	}))
+	defer server.Close()
+
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	client := &http.Client{Transport: NewTransport(http.DefaultTransport.(*http.Transport), nil)}
+
+	ctx := WithHost(context.Background(), "App.Internal", "127.0.0.1")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://app.internal:"+port+"/", nil)
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	defer res.Body.Close()
+
+	body, _ := io.ReadAll(res.Body)
+	if want := "app.internal:" + port; string(body) != want {
+		t.Errorf("server saw host %q, want %q", body, want)
+	}
+
+	// hosts that are not mapped resolve as usual
+	if _, ok := HostIP(ctx, "other.internal"); ok {
+		t.Errorf("HostIP() mapped a host that was not given")
+	}
+}
+
+func TestResolver(t *testing.T) {
+	hosts, err := ParseHosts(strings.NewReader("# split horizon\n10.0.0.1 intranet.corp Intranet\n10.0.0.2 intranet.corp\n"))
+	if err != nil {
+		t.Fatalf("ParseHosts() error = %v", err)
+	}
+
+	// a dns over https server that only knows app.corp
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var query dnsmessage.Message
+		if err := query.Unpack(body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		answer := dnsmessage.Message{
+			Header:    dnsmessage.Header{Response: true, RCode: dnsmessage.RCodeNameError},
+			Questions: query.Questions,
+		}
+		if q := query.Questions[0]; q.Name.String() == "app.corp." && q.Type == dnsmessage.TypeA {
+			answer.RCode = dnsmessage.RCodeSuccess
+			answer.Answers = []dnsmessage.Resource{{
+				Header: dnsmessage.ResourceHeader{Name: q.Name, Type: q.Type, Class: q.Class, TTL: 60},
+				Body:   &dnsmessage.AResource{A: [4]byte{10, 0, 0, 3}},
+			}}
+		}
+
+		packed, _ := answer.Pack()
+		w.Header().Set("Content-Type", "application/dns-message")
+		w.Write(packed)
+	}))
+	defer server.Close()
+
+	doh, err := newDoH(server.URL+"/dns-query{?dns}", 0)
+	if err != nil {
+		t.Fatalf("newDoH() error = %v", err)
+	}
+	doh.client = server.Client()
+	r := &Resolver{hosts: hosts, doh: doh}
+
+	tests := []struct {
+		host   string
+		want   string
+		wantOk bool
+	}{
+		{"intranet.corp", "10.0.0.1", true},
+		{"INTRANET.", "10.0.0.1", true},
+		{"app.corp", "10.0.0.3", true},
+		{"10.9.9.9", "", false},
+	}
+	for _, tt := range tests {
+		ip, ok, err := r.LookupIP(context.Background(), tt.host)
+		if err != nil || ip != tt.want || ok != tt.wantOk {
+			t.Errorf("LookupIP(%q) = %q, %v, %v, want %q, %v", tt.host, ip, ok, err, tt.want, tt.wantOk)
+		}
+	}
+
+	if _, _, err := r.LookupIP(context.Background(), "missing.corp"); err == nil {
+		t.Errorf("LookupIP() resolved a host the server does not know")
+	}
+
+	if _, err := newDoH("10.0.0.53", 0); err == nil {
+		t.Errorf("newDoH() accepted a plain dns server")
+	}
+}
@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"sync"
+
+	"github.com/sensepost/gowitness/pkg/discovery"
+	"github.com/sensepost/gowitness/pkg/models"
+	"github.com/sensepost/gowitness/pkg/scope"
+)
+
+// discoverer tracks the hosts seen during recursive discovery
+type discoverer struct {
+	scope *scope.Scope
+	// depth is the most levels of discovery to follow
+	depth int
+
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+func newDiscoverer(inScope *scope.Scope, depth int) *discoverer {
+	return &discoverer{
+		scope: inScope,
+		depth: depth,
+		seen:  make(map[string]bool),
+	}
+}
+
+// markSeen records the host of a target URL, returning false if it had
+// already been seen
+func (d *discoverer) markSeen(url string) bool {
+	root, ok := discovery.Root(url)
+	if !ok {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.seen[root] {
+		return false
+	}
+	d.seen[root] = true
+
+	return true
+}
+
+// discover queues the unseen, in-scope hosts a result refers to. Targets
+// are queued in the background, as workers would otherwise block each
+// other.
+func (run *Runner) discover(result *models.Result, depth int) {
+	for _, ref := range discovery.References(result) {
+		if !run.discovery.markSeen(ref.URL) || !run.discovery.scope.AllowsHost(ref.Host) {
+			continue
+		}
+		if run.Scope != nil {
+			if err := run.Scope.Check(ref.URL); err != nil {
+				run.log.Debug("discovered target is out of scope", "target", ref.URL, "reason", err)
+				continue
+			}
+		}
+
+		run.log.Info("discovered target", "target", ref.URL, "via", ref.Via, "from", result.URL, "depth", depth)
+
+		t := &Target{
+			URL:     ref.URL,
+			fromID:  result.ID,
+			fromURL: result.URL,
+			via:     ref.Via,
+			depth:   depth,
+		}
+
+		run.pending.Add(1)
+		go func() {
+			select {
+			case <-run.ctx.Done():
+				run.pending.Done()
+			case run.queue <- t:
+			}
+		}()
+	}
+}
@@ -3,11 +3,15 @@ package driver
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"image"
+	"io"
 	"log/slog"
+	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -16,17 +20,25 @@ import (
 	"time"
 
 	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/fetch"
 	"github.com/chromedp/cdproto/network"
 	"github.com/chromedp/cdproto/page"
 	"github.com/chromedp/cdproto/runtime"
 	"github.com/chromedp/cdproto/storage"
+	"github.com/chromedp/cdproto/target"
 	"github.com/chromedp/chromedp"
 	"github.com/sensepost/gowitness/internal/islazy"
+	"github.com/sensepost/gowitness/pkg/favicon"
 	"github.com/sensepost/gowitness/pkg/imagehash"
 	"github.com/sensepost/gowitness/pkg/models"
+	"github.com/sensepost/gowitness/pkg/resolver"
 	"github.com/sensepost/gowitness/pkg/runner"
 )
 
+// chromedpFeatures are the features chromedp's default allocator options
+// enable, which the browser is launched with
+var chromedpFeatures = []string{"NetworkService", "NetworkServiceInProcess"}
+
 // Chromedp is a driver that probes web targets using chromedp
 // Implementation ref: https://github.com/chromedp/examples/blob/master/multi/main.go
 type Chromedp struct {
@@ -46,6 +58,18 @@ type Chromedp struct {
 
 	// pre-parsed custom request headers to avoid per-target parse overhead
 	headers network.Headers
+
+	// favicons fetches site favicons. nil if they are skipped
+	favicons *favicon.Fetcher
+
+	// vhosts makes the requests the browser sends to virtual hosts
+	vhosts *http.Client
+
+	// proxyContexts are the browser contexts of the proxies targets were
+	// scanned through, created as they are first needed
+	proxyMu       sync.Mutex
+	proxyContexts map[string]context.Context
+	proxyCancels  []context.CancelFunc
 }
 
 // browserInstance is an instance used by one run of Witness
@@ -67,7 +91,7 @@ func (b *browserInstance) Close() {
 }
 
 // getChromedpAllocator is a helper function to get a chrome allocation context.
-func getChromedpAllocator(opts runner.Options) (*browserInstance, error) {
+func getChromedpAllocator(opts runner.Options, res *resolver.Resolver) (*browserInstance, error) {
 	var (
 		allocCtx    context.Context
 		allocCancel context.CancelFunc
@@ -88,6 +112,7 @@ func getChromedpAllocator(opts runner.Options) (*browserInstance, error) {
 			chromedp.DisableGPU,
 			chromedp.IgnoreCertErrors,
 			chromedp.UserAgent(opts.Chrome.UserAgent),
+			chromedp.Flag("enable-features", strings.Join(chromedpFeatures, ",")),
 			chromedp.Flag("disable-features", "MediaRouter,HttpsUpgrades,OptimizationHints,AutofillServerCommunication"),
 			chromedp.Flag("mute-audio", true),
 			chromedp.Flag("hide-scrollbars", true),
@@ -117,6 +142,11 @@ func getChromedpAllocator(opts runner.Options) (*browserInstance, error) {
 			allocOpts = append(allocOpts, chromedp.ProxyServer(opts.Chrome.Proxy))
 		}
 
+		// Resolve hosts with the hosts file and dns server
+		for flag, value := range resolverFlags(res, chromedpFeatures) {
+			allocOpts = append(allocOpts, chromedp.Flag(flag, value))
+		}
+
 		// Use specific Chrome binary if provided
 		if opts.Chrome.Path != "" {
 			allocOpts = append(allocOpts, chromedp.ExecPath(opts.Chrome.Path))
@@ -137,8 +167,23 @@ func getChromedpAllocator(opts runner.Options) (*browserInstance, error) {
 
 // NewChromedp returns a new Chromedp instance
 func NewChromedp(logger *slog.Logger, opts runner.Options) (*Chromedp, error) {
-	allocator, err := getChromedpAllocator(opts)
+	res, err := resolver.New(opts.Chrome.HostsFile, opts.Chrome.DNSServer,
+		time.Duration(opts.Scan.Timeout)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if res != nil && opts.Chrome.WSS != "" {
+		logger.Warn("a remote chrome instance resolves hosts itself, ignoring the hosts file and dns server for the browser")
+	}
+
+	allocator, err := getChromedpAllocator(opts, res)
+	if err != nil {
+		return nil, err
+	}
+
+	favicons, err := newFaviconFetcher(opts, res)
 	if err != nil {
+		allocator.Close()
 		return nil, err
 	}
 
@@ -157,6 +202,8 @@ func NewChromedp(logger *slog.Logger, opts runner.Options) (*Chromedp, error) {
 		allocator:     allocator,
 		browserCtx:    browserCtx,
 		browserCancel: browserCancel,
+		favicons:      favicons,
+		vhosts:        newVHostClient(opts),
 	}
 
 	// pre-parse extra headers once at driver startup
@@ -180,14 +227,53 @@ func NewChromedp(logger *slog.Logger, opts runner.Options) (*Chromedp, error) {
 	return driver, nil
 }
 
+// proxyContext returns the browser context that tabs going through proxy
+// are opened in. An empty proxy uses the browser's own settings.
+func (run *Chromedp) proxyContext(proxy string) (context.Context, error) {
+	if proxy == "" {
+		return run.browserCtx, nil
+	}
+
+	run.proxyMu.Lock()
+	defer run.proxyMu.Unlock()
+
+	if ctx, ok := run.proxyContexts[proxy]; ok {
+		return ctx, nil
+	}
+
+	ctx, cancel := chromedp.NewContext(run.browserCtx, chromedp.WithNewBrowserContext(
+		func(p *target.CreateBrowserContextParams) *target.CreateBrowserContextParams {
+			return p.WithProxyServer(proxy)
+		}))
+	// running the context creates it, with a blank tab that tabs inherit
+	// the browser context of
+	if err := chromedp.Run(ctx); err != nil {
+		cancel()
+		return nil, fmt.Errorf("could not create a browser context for proxy %s: %w", proxy, err)
+	}
+
+	if run.proxyContexts == nil {
+		run.proxyContexts = make(map[string]context.Context)
+	}
+	run.proxyContexts[proxy] = ctx
+	run.proxyCancels = append(run.proxyCancels, cancel)
+
+	return ctx, nil
+}
+
 // witness does the work of probing a url.
 // This is where everything comes together as far as the runner is concerned.
-func (run *Chromedp) Witness(target string, thisRunner *runner.Runner) (*models.Result, error) {
+func (run *Chromedp) Witness(t *runner.Target, thisRunner *runner.Runner) (*models.Result, error) {
+	target := t.URL
 	logger := run.log.With("target", target)
 	logger.Debug("witnessing 👀")
 
-	// get a tab
-	tabCtx, tabCancel := chromedp.NewContext(run.browserCtx)
+	// get a tab, in the browser context of the target's proxy
+	browserCtx, err := run.proxyContext(t.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	tabCtx, tabCancel := chromedp.NewContext(browserCtx)
 
 	defer func() {
 		tabCancel()
@@ -238,6 +324,56 @@ func (run *Chromedp) Witness(target string, thisRunner *runner.Runner) (*models.
 			})
 			resultMutex.Unlock()
 
+		// requests paused by the fetch domain, which is only enabled to
+		// enforce the scope and to request virtual hosts from their IP
+		case *fetch.EventRequestPaused:
+			go func() {
+				c := chromedp.FromContext(navigationCtx)
+				ctx := cdp.WithExecutor(navigationCtx, c.Target)
+
+				if err := thisRunner.CheckScope(t, e.Request.URL); err != nil {
+					logger.Debug("blocked an out of scope request", "url", e.Request.URL, "reason", err)
+
+					resultMutex.Lock()
+					result.BlockedRequests = append(result.BlockedRequests, models.BlockedRequest{
+						URL:    e.Request.URL,
+						Reason: err.Error(),
+					})
+					resultMutex.Unlock()
+
+					if err := fetch.FailRequest(e.RequestID, network.ErrorReasonBlockedByClient).Do(ctx); err != nil && run.options.Logging.LogScanErrors {
+						logger.Error("could not block an out of scope request", "url", e.Request.URL, "err", err)
+					}
+					return
+				}
+
+				if u, err := url.Parse(e.Request.URL); err == nil && t.MapsHost(u.Hostname()) {
+					state, err := run.fulfillVHost(ctx, t, e)
+					if err != nil {
+						logger.Debug("could not request a virtual host", "url", e.Request.URL, "ip", t.IP, "err", err)
+						if err := fetch.FailRequest(e.RequestID, network.ErrorReasonConnectionFailed).Do(ctx); err != nil && run.options.Logging.LogScanErrors {
+							logger.Error("could not fail a virtual host request", "url", e.Request.URL, "err", err)
+						}
+						return
+					}
+
+					// the browser has no security details for fulfilled
+					// responses, so take them from the page's connection
+					if state != nil && e.ResourceType == network.ResourceTypeDocument {
+						resultMutex.Lock()
+						if result.TLS.Protocol == "" {
+							result.TLS = tlsDetails(state)
+						}
+						resultMutex.Unlock()
+					}
+					return
+				}
+
+				if err := fetch.ContinueRequest(e.RequestID).Do(ctx); err != nil && run.options.Logging.LogScanErrors {
+					logger.Error("could not continue a paused request", "url", e.Request.URL, "err", err)
+				}
+			}()
+
 		// network related events
 		// write a request to the network request map
 		case *network.EventRequestWillBeSent:
@@ -254,6 +390,7 @@ func (run *Chromedp) Witness(target string, thisRunner *runner.Runner) (*models.
 				if first != nil && first.RequestID == e.RequestID {
 					resultMutex.Lock()
 					result.FinalURL = e.Response.URL
+					result.ResolvedIP = e.Response.RemoteIPAddress
 					result.ResponseCode = int(e.Response.Status)
 					result.ResponseReason = e.Response.StatusText
 					result.Protocol = e.Response.Protocol
@@ -372,6 +509,12 @@ func (run *Chromedp) Witness(target string, thisRunner *runner.Runner) (*models.
 	// get cookies
 	var cookies []*network.Cookie
 
+	// the url of the site's favicon, resolved by the page
+	var iconURL string
+
+	// the document's location, to spot browser error pages
+	var location string
+
 	// grab a screenshot
 	var (
 		img           []byte
@@ -386,6 +529,12 @@ func (run *Chromedp) Witness(target string, thisRunner *runner.Runner) (*models.
 		tasks = append(tasks, network.SetExtraHTTPHeaders(run.headers))
 	}
 
+	// pause every request to check that it is in scope, and so that
+	// requests to a virtual host can be made from its IP
+	if thisRunner.Scope != nil || t.IP != "" {
+		tasks = append(tasks, fetch.Enable())
+	}
+
 	// accumulate tasks to execute in the tab context.
 	tasks = append(tasks, chromedp.ActionFunc(func(ctx context.Context) error {
 		if err := network.ClearBrowserCookies().Do(ctx); err != nil && run.options.Logging.LogScanErrors {
@@ -429,12 +578,23 @@ func (run *Chromedp) Witness(target string, thisRunner *runner.Runner) (*models.
 			logger.Error("could not get page title", "err", err)
 		}
 
+		if err := chromedp.Location(&location).Do(ctx); err != nil && run.options.Logging.LogScanErrors {
+			logger.Error("could not get page location", "err", err)
+		}
+		result.ErrorPage = isErrorPage(location)
+
 		if !run.options.Scan.SkipHTML {
 			if err := chromedp.OuterHTML(":root", &result.HTML, chromedp.ByQueryAll).Do(ctx); err != nil && run.options.Logging.LogScanErrors {
 				logger.Error("could not get page html", "err", err)
 			}
 		}
 
+		if run.favicons != nil {
+			if err := chromedp.Evaluate("("+favicon.Script+")()", &iconURL).Do(ctx); err != nil && run.options.Logging.LogScanErrors {
+				logger.Error("could not resolve favicon url", "err", err)
+			}
+		}
+
 		return nil
 	}))
 
@@ -494,6 +654,10 @@ func (run *Chromedp) Witness(target string, thisRunner *runner.Runner) (*models.
 		return nil, fmt.Errorf("http response code was %d which is filtered", result.ResponseCode)
 	}
 
+	if icon := fetchFavicon(run.favicons, thisRunner, t, iconURL, logger); icon != nil {
+		result.Favicon = *icon
+	}
+
 	// fingerprint technologies in the first response
 	if fingerprints := thisRunner.Wappalyzer.Fingerprint(result.HeaderMap(), []byte(result.HTML)); fingerprints != nil {
 		for tech := range fingerprints {
@@ -543,14 +707,87 @@ func (run *Chromedp) Witness(target string, thisRunner *runner.Runner) (*models.
 			return nil, fmt.Errorf("failed to calculate image perception hash: %w", err)
 		}
 		result.PerceptionHash = hash
+		result.Blank = imagehash.Blank(decoded)
+
+		// and the other hashes used to find similar screenshots
+		hashes, err := imagehash.Hashes(decoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate image hashes: %w", err)
+		}
+		for _, algorithm := range imagehash.Algorithms() {
+			if algorithm.Name == imagehash.PHash {
+				continue
+			}
+			result.ImageHashes = append(result.ImageHashes, models.ImageHash{
+				Algorithm: algorithm.Name,
+				Value:     hashes[algorithm.Name],
+			})
+		}
 	}
 
 	return result, nil
 }
 
+// fulfillVHost makes a paused request to a virtual host from the target's
+// IP, answering the browser with the response. It returns the state of
+// the TLS connection the request was made over, if any.
+func (run *Chromedp) fulfillVHost(ctx context.Context, t *runner.Target, e *fetch.EventRequestPaused) (*tls.ConnectionState, error) {
+	var body []byte
+	for _, entry := range e.Request.PostDataEntries {
+		data, err := base64.StdEncoding.DecodeString(entry.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, data...)
+	}
+
+	req, err := http.NewRequestWithContext(t.Context(ctx), e.Request.Method, e.Request.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range e.Request.Headers {
+		if v, ok := value.(string); ok {
+			req.Header.Set(name, v)
+		}
+	}
+
+	res, err := run.vhosts.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	content, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var headers []*fetch.HeaderEntry
+	for name, values := range res.Header {
+		for _, value := range values {
+			headers = append(headers, &fetch.HeaderEntry{Name: name, Value: value})
+		}
+	}
+
+	if err := fetch.FulfillRequest(e.RequestID, int64(res.StatusCode)).
+		WithResponseHeaders(headers).
+		WithResponsePhrase(http.StatusText(res.StatusCode)).
+		WithBody(base64.StdEncoding.EncodeToString(content)).Do(ctx); err != nil {
+		return nil, err
+	}
+
+	return res.TLS, nil
+}
+
 func (run *Chromedp) Close() {
 	run.log.Debug("closing browser allocation context")
 
+	run.proxyMu.Lock()
+	for _, cancel := range run.proxyCancels {
+		cancel()
+	}
+	run.proxyMu.Unlock()
+
 	if run.browserCancel != nil {
 		run.browserCancel()
 	}
@@ -0,0 +1,52 @@
+package driver
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/sensepost/gowitness/pkg/favicon"
+	"github.com/sensepost/gowitness/pkg/httpclient"
+	"github.com/sensepost/gowitness/pkg/models"
+	"github.com/sensepost/gowitness/pkg/resolver"
+	"github.com/sensepost/gowitness/pkg/runner"
+)
+
+// newFaviconFetcher returns a favicon fetcher that matches the browser's
+// configuration, or nil if favicons should not be fetched
+func newFaviconFetcher(opts runner.Options, res *resolver.Resolver) (*favicon.Fetcher, error) {
+	if opts.Scan.SkipFavicon {
+		return nil, nil
+	}
+
+	return favicon.NewFetcher(opts.Chrome.Proxy, opts.Chrome.UserAgent, opts.Chrome.Headers,
+		time.Duration(opts.Scan.Timeout)*time.Second, res)
+}
+
+// fetchFavicon downloads and hashes the favicon at iconURL. Plenty of
+// sites don't have one, so failures are only logged at debug level. Icons
+// that are out of scope are not fetched, and icons on a virtual host are
+// fetched from the target's IP.
+func fetchFavicon(fetcher *favicon.Fetcher, thisRunner *runner.Runner, t *runner.Target, iconURL string, logger *slog.Logger) *models.Favicon {
+	if fetcher == nil || iconURL == "" {
+		return nil
+	}
+
+	if err := thisRunner.CheckScope(t, iconURL); err != nil {
+		logger.Debug("not fetching an out of scope favicon", "url", iconURL, "reason", err)
+		return nil
+	}
+
+	// redirects have to stay in scope too
+	ctx := httpclient.WithURLCheck(t.Context(context.Background()), func(target string) error {
+		return thisRunner.CheckScope(t, target)
+	})
+
+	icon, err := fetcher.Fetch(ctx, iconURL)
+	if err != nil {
+		logger.Debug("could not fetch favicon", "url", iconURL, "err", err)
+		return nil
+	}
+
+	return icon
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"image"
 	"log/slog"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -14,11 +15,14 @@ import (
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/launcher/flags"
 	"github.com/go-rod/rod/lib/proto"
 	"github.com/sensepost/gowitness/internal/islazy"
+	"github.com/sensepost/gowitness/pkg/favicon"
 	"github.com/sensepost/gowitness/pkg/imagehash"
 	"github.com/sensepost/gowitness/pkg/log"
 	"github.com/sensepost/gowitness/pkg/models"
+	"github.com/sensepost/gowitness/pkg/resolver"
 	"github.com/sensepost/gowitness/pkg/runner"
 	"github.com/ysmood/gson"
 )
@@ -33,6 +37,15 @@ type Gorod struct {
 	options runner.Options
 	// logger
 	log *slog.Logger
+	// favicons fetches site favicons. nil if they are skipped
+	favicons *favicon.Fetcher
+	// vhosts makes the requests the browser sends to virtual hosts
+	vhosts *http.Client
+
+	// proxyBrowsers are the browser contexts of the proxies targets were
+	// scanned through, created as they are first needed
+	proxyMu       sync.Mutex
+	proxyBrowsers map[string]*rod.Browser
 }
 
 // New gets a new Runner ready for probing.
@@ -44,6 +57,17 @@ func NewGorod(logger *slog.Logger, opts runner.Options) (*Gorod, error) {
 		err      error
 	)
 
+	res, err := resolver.New(opts.Chrome.HostsFile, opts.Chrome.DNSServer,
+		time.Duration(opts.Scan.Timeout)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	favicons, err := newFaviconFetcher(opts, res)
+	if err != nil {
+		return nil, err
+	}
+
 	if opts.Chrome.WSS == "" {
 		userData, err = os.MkdirTemp("", "gowitness-v3-gorod-*")
 		if err != nil {
@@ -95,6 +119,12 @@ func NewGorod(logger *slog.Logger, opts runner.Options) (*Gorod, error) {
 			chrmLauncher.Proxy(opts.Chrome.Proxy)
 		}
 
+		// resolve hosts with the hosts file and dns server
+		features, _ := chrmLauncher.GetFlags("enable-features")
+		for flag, value := range resolverFlags(res, features) {
+			chrmLauncher.Set(flags.Flag(flag), value)
+		}
+
 		url, err = chrmLauncher.Launch()
 		if err != nil {
 			return nil, err
@@ -103,6 +133,9 @@ func NewGorod(logger *slog.Logger, opts runner.Options) (*Gorod, error) {
 	} else {
 		url = opts.Chrome.WSS
 		logger.Debug("using a user specified WSS url", "control-url", url)
+		if res != nil {
+			logger.Warn("a remote chrome instance resolves hosts itself, ignoring the hosts file and dns server for the browser")
+		}
 	}
 
 	// connect to the control-url
@@ -121,16 +154,56 @@ func NewGorod(logger *slog.Logger, opts runner.Options) (*Gorod, error) {
 		userData: userData,
 		options:  opts,
 		log:      logger,
+		favicons: favicons,
+		vhosts:   newVHostClient(opts),
 	}, nil
 }
 
+// proxyBrowser returns the browser whose pages go through proxy, in a
+// browser context of its own. An empty proxy uses the browser's own
+// settings.
+func (run *Gorod) proxyBrowser(proxy string) (*rod.Browser, error) {
+	if proxy == "" {
+		return run.browser, nil
+	}
+
+	run.proxyMu.Lock()
+	defer run.proxyMu.Unlock()
+
+	if browser, ok := run.proxyBrowsers[proxy]; ok {
+		return browser, nil
+	}
+
+	// like Browser.Incognito, with a proxy
+	res, err := proto.TargetCreateBrowserContext{ProxyServer: proxy}.Call(run.browser)
+	if err != nil {
+		return nil, fmt.Errorf("could not create a browser context for proxy %s: %w", proxy, err)
+	}
+	browser := *run.browser
+	browser.BrowserContextID = res.BrowserContextID
+
+	if run.proxyBrowsers == nil {
+		run.proxyBrowsers = make(map[string]*rod.Browser)
+	}
+	run.proxyBrowsers[proxy] = &browser
+
+	return &browser, nil
+}
+
 // witness does the work of probing a url.
 // This is where everything comes together as far as the runner is concerned.
-func (run *Gorod) Witness(target string, runner *runner.Runner) (*models.Result, error) {
+func (run *Gorod) Witness(t *runner.Target, runner *runner.Runner) (*models.Result, error) {
+	target := t.URL
 	logger := run.log.With("target", target)
 	logger.Debug("witnessing 👀")
 
-	page, err := run.browser.Page(proto.TargetCreateTarget{})
+	// open the page in the browser context of the target's proxy
+	browser, err := run.proxyBrowser(t.Proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := browser.Page(proto.TargetCreateTarget{})
 	if err != nil {
 		return nil, fmt.Errorf("could not get a page: %w", err)
 	}
@@ -189,6 +262,54 @@ func (run *Gorod) Witness(target string, runner *runner.Runner) (*models.Result,
 		dismissEvents = false // set to true to stop EachEvent callbacks
 	)
 
+	// hijack every request to check that it is in scope, and so that
+	// requests to a virtual host can be made from its IP
+	if runner.Scope != nil || t.IP != "" {
+		router := page.HijackRequests()
+		if err := router.Add("*", "", func(h *rod.Hijack) {
+			requestURL := h.Request.URL().String()
+			if err := runner.CheckScope(t, requestURL); err != nil {
+				logger.Debug("blocked an out of scope request", "url", requestURL, "reason", err)
+
+				resultMutex.Lock()
+				result.BlockedRequests = append(result.BlockedRequests, models.BlockedRequest{
+					URL:    requestURL,
+					Reason: err.Error(),
+				})
+				resultMutex.Unlock()
+
+				h.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
+				return
+			}
+
+			if t.MapsHost(h.Request.URL().Hostname()) {
+				h.Request.SetContext(t.Context(h.Request.Req().Context()))
+				if err := h.LoadResponse(run.vhosts, true); err != nil {
+					logger.Debug("could not request a virtual host", "url", requestURL, "ip", t.IP, "err", err)
+					h.Response.Fail(proto.NetworkErrorReasonConnectionFailed)
+					return
+				}
+
+				// the browser has no security details for fulfilled
+				// responses, so take them from the page's connection
+				if state := h.Response.RawResponse.TLS; state != nil && h.Request.Type() == proto.NetworkResourceTypeDocument {
+					resultMutex.Lock()
+					if result.TLS.Protocol == "" {
+						result.TLS = tlsDetails(state)
+					}
+					resultMutex.Unlock()
+				}
+				return
+			}
+
+			h.ContinueRequest(&proto.FetchContinueRequest{})
+		}); err != nil {
+			return nil, fmt.Errorf("could not intercept requests: %w", err)
+		}
+		go router.Run()
+		defer router.Stop()
+	}
+
 	go page.EachEvent(
 		// dismiss any javascript dialogs
 		func(e *proto.PageJavascriptDialogOpening) bool {
@@ -246,6 +367,7 @@ func (run *Gorod) Witness(target string, runner *runner.Runner) (*models.Result,
 				if first != nil && first.RequestID == e.RequestID {
 					resultMutex.Lock()
 					result.FinalURL = e.Response.URL
+					result.ResolvedIP = e.Response.RemoteIPAddress
 					result.ResponseCode = e.Response.Status
 					result.ResponseReason = e.Response.StatusText
 					result.Protocol = e.Response.Protocol
@@ -409,6 +531,7 @@ func (run *Gorod) Witness(target string, runner *runner.Runner) (*models.Result,
 		}
 	} else {
 		result.Title = info.Title
+		result.ErrorPage = isErrorPage(info.URL)
 	}
 
 	if !run.options.Scan.SkipHTML {
@@ -422,6 +545,17 @@ func (run *Gorod) Witness(target string, runner *runner.Runner) (*models.Result,
 		}
 	}
 
+	if run.favicons != nil {
+		iconURL, err := page.Eval(favicon.Script)
+		if err != nil {
+			if run.options.Logging.LogScanErrors {
+				logger.Error("could not resolve favicon url", "err", err)
+			}
+		} else if icon := fetchFavicon(run.favicons, runner, t, iconURL.Value.Str(), logger); icon != nil {
+			result.Favicon = *icon
+		}
+	}
+
 	// stop the event handlers
 	dismissEvents = true
 
@@ -485,6 +619,22 @@ func (run *Gorod) Witness(target string, runner *runner.Runner) (*models.Result,
 			return nil, fmt.Errorf("failed to calculate image perception hash: %w", err)
 		}
 		result.PerceptionHash = hash
+		result.Blank = imagehash.Blank(decoded)
+
+		// and the other hashes used to find similar screenshots
+		hashes, err := imagehash.Hashes(decoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate image hashes: %w", err)
+		}
+		for _, algorithm := range imagehash.Algorithms() {
+			if algorithm.Name == imagehash.PHash {
+				continue
+			}
+			result.ImageHashes = append(result.ImageHashes, models.ImageHash{
+				Algorithm: algorithm.Name,
+				Value:     hashes[algorithm.Name],
+			})
+		}
 	}
 
 	return result, nil
@@ -495,6 +645,15 @@ func (run *Gorod) Witness(target string, runner *runner.Runner) (*models.Result,
 func (run *Gorod) Close() {
 	run.log.Debug("closing the browser instance")
 
+	// a remote browser outlives the scan, so its contexts are disposed of
+	run.proxyMu.Lock()
+	for proxy, browser := range run.proxyBrowsers {
+		if err := browser.Close(); err != nil {
+			run.log.Debug("could not dispose of a proxy's browser context", "proxy", proxy, "err", err)
+		}
+	}
+	run.proxyMu.Unlock()
+
 	if err := run.browser.Close(); err != nil {
 		log.Error("could not close the browser", "err", err)
 		return
@@ -0,0 +1,9 @@
+package driver
+
+import "strings"
+
+// isErrorPage checks if a location is one of the browser's own error
+// pages, shown for things like an empty response or a connection reset
+func isErrorPage(location string) bool {
+	return strings.HasPrefix(location, "chrome-error://")
+}
@@ -0,0 +1,39 @@
+package driver
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/sensepost/gowitness/pkg/resolver"
+)
+
+// resolverFlags returns the Chrome flags that make it resolve hosts with
+// res, as flag names and values. features are the features the browser
+// is already launched with, as enable-features replaces them.
+func resolverFlags(res *resolver.Resolver, features []string) map[string]string {
+	flags := make(map[string]string)
+
+	if hosts := res.Hosts(); len(hosts) > 0 {
+		var rules []string
+		for host, ip := range hosts {
+			// ipv6 addresses are bracketed, so that they are not read
+			// as a host and port
+			if strings.Contains(ip, ":") {
+				ip = "[" + ip + "]"
+			}
+			rules = append(rules, "MAP "+host+" "+ip)
+		}
+		slices.Sort(rules)
+		flags["host-resolver-rules"] = strings.Join(rules, ",")
+	}
+
+	// chrome only takes a custom dns server as a dns over https template,
+	// in secure mode so that it never falls back to the system resolver
+	if server := res.DNSServer(); server != "" {
+		features = append(slices.Clone(features), "DnsOverHttps:Fallback/false/Templates/"+url.QueryEscape(server))
+		flags["enable-features"] = strings.Join(features, ",")
+	}
+
+	return flags
+}
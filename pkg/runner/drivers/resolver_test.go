@@ -0,0 +1,25 @@
+package driver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sensepost/gowitness/pkg/resolver"
+)
+
+func TestResolverFlagsFeatures(t *testing.T) {
+	res, err := resolver.New("", "https://dns.example/dns-query", time.Second)
+	if err != nil {
+		t.Fatalf("resolver.New() error = %v", err)
+	}
+
+	features := []string{"NetworkService", "NetworkServiceInProcess"}
+	got := resolverFlags(res, features)["enable-features"]
+	want := "NetworkService,NetworkServiceInProcess,DnsOverHttps:Fallback/false/Templates/https%3A%2F%2Fdns.example%2Fdns-query"
+	if got != want {
+		t.Errorf("resolverFlags() enable-features = %q, want %q", got, want)
+	}
+	if len(features) != 2 {
+		t.Errorf("resolverFlags() modified the features it was given: %v", features)
+	}
+}
@@ -0,0 +1,95 @@
+package driver
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/sensepost/gowitness/pkg/models"
+	"github.com/sensepost/gowitness/pkg/resolver"
+	"github.com/sensepost/gowitness/pkg/runner"
+)
+
+// maxVHostBodySize is the largest virtual host response body the drivers
+// read into memory to hand to the browser
+const maxVHostBodySize = 32 << 20
+
+// newVHostClient returns the client that makes the requests the browser
+// sends to virtual hosts, as only the driver knows which IP a tab's
+// virtual host is on. Redirects are left for the browser to follow.
+func newVHostClient(opts runner.Options) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	// the proxy would resolve the virtual host itself
+	transport.Proxy = nil
+
+	return &http.Client{
+		Transport: limitTransport{resolver.NewTransport(transport, nil)},
+		Timeout:   time.Duration(opts.Scan.Timeout) * time.Second,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// limitTransport fails reads of response bodies that are larger than
+// maxVHostBodySize, as both drivers buffer the whole body
+type limitTransport struct {
+	http.RoundTripper
+}
+
+func (l limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := l.RoundTripper.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	res.Body = &limitedBody{
+		Reader: io.LimitReader(res.Body, maxVHostBodySize+1),
+		Closer: res.Body,
+	}
+	return res, nil
+}
+
+// limitedBody reads a response body, failing once more than
+// maxVHostBodySize bytes were read
+type limitedBody struct {
+	io.Reader
+	io.Closer
+	read int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.Reader.Read(p)
+	b.read += int64(n)
+	if b.read > maxVHostBodySize {
+		return 0, fmt.Errorf("virtual host response is larger than %d bytes", maxVHostBodySize)
+	}
+	return n, err
+}
+
+// tlsDetails describes the TLS connection a virtual host was requested
+// over, as the browser has no security details for responses a driver
+// fulfilled
+func tlsDetails(state *tls.ConnectionState) models.TLS {
+	details := models.TLS{
+		Protocol:             tls.VersionName(state.Version),
+		Cipher:               tls.CipherSuiteName(state.CipherSuite),
+		EncryptedClientHello: state.ECHAccepted,
+	}
+
+	if len(state.PeerCertificates) > 0 {
+		cert := state.PeerCertificates[0]
+		details.SubjectName = cert.Subject.CommonName
+		details.Issuer = cert.Issuer.CommonName
+		details.ValidFrom = cert.NotBefore
+		details.ValidTo = cert.NotAfter
+		for _, name := range cert.DNSNames {
+			details.SanList = append(details.SanList, models.TLSSanList{Value: name})
+		}
+	}
+
+	return details
+}
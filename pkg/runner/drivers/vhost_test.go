@@ -0,0 +1,47 @@
+package driver
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sensepost/gowitness/pkg/runner"
+)
+
+func TestVHostClientLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		size := maxVHostBodySize
+		if r.URL.Path == "/large" {
+			size++
+		}
+		io.WriteString(w, strings.Repeat("a", size))
+	}))
+	defer server.Close()
+
+	client := newVHostClient(runner.Options{Scan: runner.Scan{Timeout: 10}})
+
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{"/", false},
+		{"/large", true},
+	}
+	for _, tt := range tests {
+		res, err := client.Get(server.URL + tt.path)
+		if err != nil {
+			t.Fatalf("Get(%s) error = %v", tt.path, err)
+		}
+		content, err := io.ReadAll(res.Body)
+		res.Body.Close()
+
+		if (err != nil) != tt.wantErr {
+			t.Errorf("reading %s error = %v, wantErr %v", tt.path, err, tt.wantErr)
+		}
+		if !tt.wantErr && len(content) != maxVHostBodySize {
+			t.Errorf("reading %s returned %d bytes, want %d", tt.path, len(content), maxVHostBodySize)
+		}
+	}
+}
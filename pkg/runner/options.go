@@ -33,6 +33,21 @@ type Chrome struct {
 	WSS string
 	// Proxy server to use
 	Proxy string
+	// Proxies are a pool of proxy servers targets are scanned through in
+	// turn, each in a browser context of its own
+	Proxies []string
+	// ProxyRules route hosts to a proxy (or "direct") as hosts=proxy,
+	// before the pool is used
+	ProxyRules []string
+	// ProxyHealthCheck is how often, in seconds, proxies are checked to
+	// be up. Zero only checks them when the scan starts
+	ProxyHealthCheck int
+	// HostsFile is a hosts file (in the /etc/hosts format) with addresses
+	// to use instead of what DNS resolves
+	HostsFile string
+	// DNSServer is a DNS over HTTPS server URL template to resolve hosts
+	// with, instead of the system resolver
+	DNSServer string
 	// UserAgent is the user-agent string to set for Chrome
 	UserAgent string
 	// Headers to add to every request
@@ -44,15 +59,20 @@ type Chrome struct {
 
 // Writer options
 type Writer struct {
-	Db        bool
-	DbURI     string
-	DbDebug   bool // enables verbose database logs
-	Csv       bool
-	CsvFile   string
-	Jsonl     bool
-	JsonlFile string
-	Stdout    bool
-	None      bool
+	Db       bool
+	DbURI    string
+	DbDebug  bool // enables verbose database logs
+	DbUpdate bool // replaces the results rescans are of
+	// AlertsDir and AlertsWebhookHosts limit where the alert rules of
+	// saved searches may send notifications
+	AlertsDir          string
+	AlertsWebhookHosts []string
+	Csv                bool
+	CsvFile            string
+	Jsonl              bool
+	JsonlFile          string
+	Stdout             bool
+	None               bool
 }
 
 // Scan is scanning related options
@@ -73,6 +93,13 @@ type Scan struct {
 	SkipHTML bool
 	// SkipNetworkLogs stops recording individual request/response entries
 	SkipNetworkLogs bool
+	// SkipFavicon stops fetching and hashing the site's favicon
+	SkipFavicon bool
+	// Soft404 probes a random path on each host to find soft 404s
+	Soft404 bool
+	// SkipElements stops extracting links, forms, scripts and meta tags
+	// from the page's DOM
+	SkipElements bool
 	// ScreenshotPath is the path where screenshot images will be stored.
 	// An empty value means drivers will not write screenshots to disk. In
 	// that case, you'd need to specify writer saves.
@@ -96,6 +123,30 @@ type Scan struct {
 	// HttpCodeFilter are http response codes to screenshot. this is a filter.
 	// by default all codes are screenshotted
 	HttpCodeFilter []int
+	// Signatures are extra page signature files (or directories of them)
+	// to categorize results with, on top of the defaults
+	Signatures []string
+	// DefaultCredentials are extra default credential files (or
+	// directories of them) used to annotate results with login hints
+	DefaultCredentials []string
+	// ScopeFile is a json file with allowed and denied hosts and ports.
+	// The Scope* lists below are added to it
+	ScopeFile string
+	// ScopeAllow and ScopeDeny are domains, wildcards, IP addresses and
+	// CIDRs that targets and browser requests may or may not go to
+	ScopeAllow []string
+	ScopeDeny  []string
+	// ScopeAllowPorts and ScopeDenyPorts are ports targets and browser
+	// requests may or may not go to
+	ScopeAllowPorts []int
+	ScopeDenyPorts  []int
+	// DiscoverDepth is how many levels of hosts referenced by results are
+	// scanned. 0 disables discovery
+	DiscoverDepth int
+	// DiscoverScope are the domains, IP addresses and CIDRs discovered
+	// hosts need to be in to be scanned. Defaults to the allowed hosts of
+	// the scan's scope
+	DiscoverScope []string
 }
 
 // NewDefaultOptions returns Options with some default values
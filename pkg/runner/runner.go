@@ -3,14 +3,28 @@ package runner
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"net"
 	"net/url"
 	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	wappalyzer "github.com/projectdiscovery/wappalyzergo"
 	"github.com/sensepost/gowitness/internal/islazy"
+	"github.com/sensepost/gowitness/pkg/credentials"
+	"github.com/sensepost/gowitness/pkg/extract"
+	"github.com/sensepost/gowitness/pkg/httpclient"
 	"github.com/sensepost/gowitness/pkg/models"
+	"github.com/sensepost/gowitness/pkg/proxies"
+	"github.com/sensepost/gowitness/pkg/readers"
+	"github.com/sensepost/gowitness/pkg/resolver"
+	"github.com/sensepost/gowitness/pkg/scope"
+	"github.com/sensepost/gowitness/pkg/signatures"
+	"github.com/sensepost/gowitness/pkg/soft404"
 	"github.com/sensepost/gowitness/pkg/writers"
 )
 
@@ -18,6 +32,19 @@ import (
 type Runner struct {
 	Driver     Driver
 	Wappalyzer *wappalyzer.Wappalyze
+	Signatures *signatures.Engine
+	// Credentials are default credential hints, they are never tried
+	Credentials *credentials.Database
+	// Soft404 detects soft 404s. nil unless they are probed for
+	Soft404 *soft404.Detector
+	// Scope is enforced on targets and, by drivers, on browser requests.
+	// nil if everything is in scope
+	Scope *scope.Scope
+	// Proxies selects the proxy each target is scanned through. nil if
+	// every target uses the browser's proxy settings
+	Proxies *proxies.Pool
+	// discovery finds new targets in results. nil if it is disabled
+	discovery *discoverer
 
 	// options for the Runner to consider
 	options Options
@@ -28,7 +55,15 @@ type Runner struct {
 
 	// Targets to scan.
 	// This would typically be fed from a gowitness/pkg/reader.
-	Targets chan string
+	Targets chan *readers.Target
+
+	// queue feeds workers with Targets and discovered targets. pending
+	// counts the targets that are queued or being witnessed, as each of
+	// them could still discover more.
+	queue   chan *Target
+	pending sync.WaitGroup
+	// processed is the number of targets workers are done with
+	processed atomic.Int64
 
 	// in case we need to bail
 	ctx    context.Context
@@ -71,20 +106,118 @@ func NewRunner(logger *slog.Logger, driver Driver, opts Options, writers []write
 		return nil, err
 	}
 
+	// page signatures to categorize results with
+	sigs, err := signatures.New(opts.Scan.Signatures...)
+	if err != nil {
+		return nil, err
+	}
+
+	// default credentials to annotate identified products with
+	creds, err := credentials.New(opts.Scan.DefaultCredentials...)
+	if err != nil {
+		return nil, err
+	}
+
+	// hosts resolve through the same hosts file and dns server as chrome
+	res, err := resolver.New(opts.Chrome.HostsFile, opts.Chrome.DNSServer,
+		time.Duration(opts.Scan.Timeout)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	// soft 404s are found by probing each host with the browser's settings
+	var detector *soft404.Detector
+	if opts.Scan.Soft404 {
+		if detector, err = soft404.NewDetector(opts.Chrome.Proxy, opts.Chrome.UserAgent, opts.Chrome.Headers,
+			time.Duration(opts.Scan.Timeout)*time.Second, res); err != nil {
+			return nil, err
+		}
+	}
+
+	// the scope targets and browser requests need to be in
+	scanScope, err := newScope(opts.Scan, res)
+	if err != nil {
+		return nil, err
+	}
+
+	// proxies targets are spread across, or routed to by their host
+	pool, err := proxies.New(proxies.Options{
+		Proxies:     opts.Chrome.Proxies,
+		Rules:       opts.Chrome.ProxyRules,
+		HealthCheck: time.Duration(opts.Chrome.ProxyHealthCheck) * time.Second,
+		Timeout:     time.Duration(opts.Scan.Timeout) * time.Second,
+		Resolver:    res,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// recursive discovery of hosts referenced by results
+	var disc *discoverer
+	if opts.Scan.DiscoverDepth > 0 {
+		discoverScope, err := scope.New(scope.Options{Allow: opts.Scan.DiscoverScope, Resolver: res})
+		if err != nil {
+			return nil, err
+		}
+		if !discoverScope.HasAllowed() {
+			if scanScope == nil || !scanScope.HasAllowed() {
+				return nil, errors.New("discovery needs a scope of domains or networks to stay in")
+			}
+			discoverScope = scanScope
+		}
+		disc = newDiscoverer(discoverScope, opts.Scan.DiscoverDepth)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &Runner{
-		Driver:     driver,
-		Wappalyzer: wap,
-		options:    opts,
-		writers:    writers,
-		Targets:    make(chan string),
-		log:        logger,
-		ctx:        ctx,
-		cancel:     cancel,
+		Driver:      driver,
+		Wappalyzer:  wap,
+		Signatures:  sigs,
+		Credentials: creds,
+		Soft404:     detector,
+		Scope:       scanScope,
+		Proxies:     pool,
+		discovery:   disc,
+		options:     opts,
+		writers:     writers,
+		Targets:     make(chan *readers.Target),
+		queue:       make(chan *Target),
+		log:         logger,
+		ctx:         ctx,
+		cancel:      cancel,
 	}, nil
 }
 
+// newScope builds the scan's scope from its scope file and flags. It
+// returns nil if everything is in scope.
+func newScope(opts Scan, res *resolver.Resolver) (*scope.Scope, error) {
+	scopeOpts := scope.Options{}
+	if opts.ScopeFile != "" {
+		loaded, err := scope.Load(opts.ScopeFile)
+		if err != nil {
+			return nil, err
+		}
+		scopeOpts = *loaded
+	}
+
+	scopeOpts.Allow = append(scopeOpts.Allow, opts.ScopeAllow...)
+	scopeOpts.Deny = append(scopeOpts.Deny, opts.ScopeDeny...)
+	scopeOpts.AllowPorts = append(scopeOpts.AllowPorts, opts.ScopeAllowPorts...)
+	scopeOpts.DenyPorts = append(scopeOpts.DenyPorts, opts.ScopeDenyPorts...)
+	scopeOpts.Resolver = res
+
+	s, err := scope.New(scopeOpts)
+	if err != nil {
+		return nil, err
+	}
+	if s.Empty() {
+		return nil, nil
+	}
+
+	return s, nil
+}
+
 // runWriters takes a result and passes it to writers
 func (run *Runner) runWriters(result *models.Result) error {
 	for _, writer := range run.writers {
@@ -111,10 +244,48 @@ func (run *Runner) checkUrl(target string) error {
 }
 
 // Run executes the runner, processing targets as they arrive
-// in the Targets channel
+// in the Targets channel, along with any targets discovered in results
 func (run *Runner) Run() {
 	wg := sync.WaitGroup{}
 
+	// feed Targets to the queue, which is closed once Targets is and all
+	// queued targets are done with
+	run.pending.Add(1)
+	go func() {
+		defer run.pending.Done()
+		for target := range run.Targets {
+			t := &Target{
+				URL:      target.URL,
+				IP:       target.IP,
+				method:   target.Method,
+				tags:     target.Tags,
+				resultID: target.ResultID,
+			}
+
+			if err := run.CheckScope(t, t.URL); err != nil {
+				run.log.Warn("target is out of scope", "target", t.URL, "reason", err)
+				run.processed.Add(1)
+				continue
+			}
+
+			if run.discovery != nil {
+				run.discovery.markSeen(t.URL)
+			}
+
+			run.pending.Add(1)
+			select {
+			case <-run.ctx.Done():
+				run.pending.Done()
+				return
+			case run.queue <- t:
+			}
+		}
+	}()
+	go func() {
+		run.pending.Wait()
+		close(run.queue)
+	}()
+
 	// will spawn Scan.Theads number of "workers" as goroutines
 	for w := 0; w < run.options.Scan.Threads; w++ {
 		wg.Add(1)
@@ -126,61 +297,187 @@ func (run *Runner) Run() {
 				select {
 				case <-run.ctx.Done():
 					return
-				case target, ok := <-run.Targets:
+				case target, ok := <-run.queue:
 					if !ok {
 						return
 					}
 
-					// validate the target
-					if err := run.checkUrl(target); err != nil {
-						if run.options.Logging.LogScanErrors {
-							run.log.Error("invalid target to scan", "target", target, "err", err)
-						}
-						continue
+					proceed := run.witness(target)
+					run.pending.Done()
+					if !proceed {
+						return
 					}
+				}
+			}
 
-					result, err := run.Driver.Witness(target, run)
-					if err != nil {
-						// is this a chrome not found error?
-						var chromeErr *ChromeNotFoundError
-						if errors.As(err, &chromeErr) {
-							run.log.Error("no valid chrome installation found", "err", err)
-							run.cancel()
-							return
-						}
-
-						if run.options.Logging.LogScanErrors {
-							run.log.Error("failed to witness target", "target", target, "err", err)
-						}
-						continue
-					}
+		}()
+	}
 
-					// assume that status code 0 means there was no information, so
-					// don't send anything to writers.
-					if result.ResponseCode == 0 {
-						if run.options.Logging.LogScanErrors {
-							run.log.Error("failed to witness target, status code was 0", "target", target)
-						}
-						continue
-					}
+	wg.Wait()
+}
 
-					if err := run.runWriters(result); err != nil {
-						run.log.Error("failed to write result for target", "target", target, "err", err)
-					}
+// witness probes a single target, passing the result to writers. It returns
+// false if the runner should stop processing targets altogether.
+func (run *Runner) witness(t *Target) bool {
+	defer run.processed.Add(1)
+	target := t.URL
 
-					run.log.Info("result 🤖", "target", target, "status-code", result.ResponseCode,
-						"title", result.Title, "have-screenshot", !result.Failed)
+	// validate the target
+	if err := run.checkUrl(target); err != nil {
+		if run.options.Logging.LogScanErrors {
+			run.log.Error("invalid target to scan", "target", target, "err", err)
+		}
+		return true
+	}
 
-				}
-			}
+	// the proxy the target's browser context goes through
+	proxy, err := run.Proxies.Select(target)
+	if err != nil {
+		if run.options.Logging.LogScanErrors {
+			run.log.Error("no proxy to witness target through", "target", target, "err", err)
+		}
+		return true
+	}
+	t.Proxy = proxy
 
-		}()
+	result, err := run.Driver.Witness(t, run)
+	if err != nil {
+		// is this a chrome not found error?
+		var chromeErr *ChromeNotFoundError
+		if errors.As(err, &chromeErr) {
+			run.log.Error("no valid chrome installation found", "err", err)
+			run.cancel()
+			return false
+		}
+
+		if run.options.Logging.LogScanErrors {
+			run.log.Error("failed to witness target", "target", target, "err", err)
+		}
+		return true
 	}
 
-	wg.Wait()
+	// assume that status code 0 means there was no information, so
+	// don't send anything to writers.
+	if result.ResponseCode == 0 {
+		if run.options.Logging.LogScanErrors {
+			run.log.Error("failed to witness target, status code was 0", "target", target)
+		}
+		return true
+	}
+
+	result.DiscoveredFromID = t.fromID
+	result.DiscoveredFromURL = t.fromURL
+	result.DiscoveredVia = t.via
+	result.DiscoveryDepth = t.depth
+	result.OriginalMethod = t.method
+	result.Proxy = t.Proxy
+	if vhost := t.VHost(); vhost != "" {
+		result.VHost = vhost
+		result.VHostIP = t.IP
+		// the driver fulfilled the response, so the browser saw no address
+		if result.ResolvedIP == "" {
+			result.ResolvedIP = t.IP
+		}
+	}
+	// a rescan keeps the id of the result it replaces
+	result.ID = t.resultID
+	for _, tag := range t.tags {
+		result.Tags = append(result.Tags, models.Tag{Value: tag})
+	}
+
+	// the html the drivers capture is the serialized DOM, so this includes
+	// elements added by scripts
+	if !run.options.Scan.SkipElements && result.HTML != "" {
+		run.extractElements(result)
+	}
+
+	if run.Soft404 != nil {
+		// the probe goes to a random path on the host the target ended up
+		// on, which has to be in scope
+		ctx := httpclient.WithURLCheck(t.Context(run.ctx), func(target string) error {
+			return run.CheckScope(t, target)
+		})
+		soft, err := run.Soft404.Check(ctx, result)
+		if err != nil {
+			run.log.Debug("could not check for a soft 404", "target", target, "err", err)
+		}
+		result.Soft404 = soft
+	}
+
+	matched := run.Signatures.Match(result)
+	result.Categories = signatures.Categories(matched)
+	result.DefaultCredentials = run.Credentials.Lookup(result, matched)
+
+	if err := run.runWriters(result); err != nil {
+		run.log.Error("failed to write result for target", "target", target, "err", err)
+	}
+
+	run.log.Info("result 🤖", "target", target, "status-code", result.ResponseCode,
+		"title", result.Title, "have-screenshot", !result.Failed)
+
+	// discovered targets are queued after writers ran, so that the result
+	// they were discovered from has its ID
+	if run.discovery != nil && t.depth < run.discovery.depth {
+		run.discover(result, t.depth+1)
+	}
+
+	return true
+}
+
+// extractElements sets the links, forms, scripts and meta tags of a result
+// from its html
+func (run *Runner) extractElements(result *models.Result) {
+	pageURL := result.FinalURL
+	if pageURL == "" {
+		pageURL = result.URL
+	}
+
+	elements, err := extract.Parse(pageURL, result.HTML)
+	if err != nil {
+		run.log.Debug("could not extract page elements", "target", result.URL, "err", err)
+		return
+	}
+
+	result.Links = elements.Links
+	result.Forms = elements.Forms
+	result.Scripts = elements.Scripts
+	result.MetaTags = elements.MetaTags
+}
+
+// CheckScope checks if a URL requested for a target is in scope. URLs for
+// a virtual host are checked with the IP the target connects to.
+func (run *Runner) CheckScope(t *Target, rawURL string) error {
+	if run.Scope == nil {
+		return nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if t != nil && t.MapsHost(u.Hostname()) {
+		if u.Port() != "" {
+			u.Host = net.JoinHostPort(t.IP, u.Port())
+		} else if strings.Contains(t.IP, ":") {
+			u.Host = "[" + t.IP + "]"
+		} else {
+			u.Host = t.IP
+		}
+	}
+
+	return run.Scope.Check(u.String())
+}
+
+// Processed returns the number of targets that have been processed
+func (run *Runner) Processed() int64 {
+	return run.processed.Load()
 }
 
 func (run *Runner) Close() {
 	// close the driver
 	run.Driver.Close()
+	// stop checking proxies
+	run.Proxies.Close()
+	// let writers finish their background work
+	writers.Close(run.writers)
 }
@@ -0,0 +1,66 @@
+package runner
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/sensepost/gowitness/pkg/proxies"
+	"github.com/sensepost/gowitness/pkg/resolver"
+)
+
+// Target is a URL for a driver to witness, along with what its reader
+// knew about it and the result it was discovered from, if any
+type Target struct {
+	URL string
+	// IP is the address to connect to for the URL's host instead of
+	// resolving it, to scan a virtual host on an IP without DNS
+	IP string
+	// Proxy is the proxy the target is scanned through, as selected from
+	// the runner's pool. Empty uses the browser's proxy settings
+	Proxy string
+
+	method string
+	tags   []string
+	// resultID is the result a rescan replaces
+	resultID uint
+
+	fromID  uint
+	fromURL string
+	via     string
+	depth   int
+}
+
+// VHost returns the host a target's IP is scanned as, or "" if the
+// target's host is resolved as usual
+func (t *Target) VHost() string {
+	if t.IP == "" {
+		return ""
+	}
+
+	u, err := url.Parse(t.URL)
+	if err != nil {
+		return ""
+	}
+
+	return u.Hostname()
+}
+
+// Context returns a context in which connections to a virtual host go to
+// the target's IP, and requests go through the target's proxy
+func (t *Target) Context(ctx context.Context) context.Context {
+	if t.Proxy != "" {
+		ctx = proxies.WithProxy(ctx, t.Proxy)
+	}
+	if vhost := t.VHost(); vhost != "" {
+		return resolver.WithHost(ctx, vhost, t.IP)
+	}
+
+	return ctx
+}
+
+// MapsHost checks if connections to host go to the target's IP
+func (t *Target) MapsHost(host string) bool {
+	vhost := t.VHost()
+	return vhost != "" && strings.EqualFold(strings.Trim(host, "[]"), vhost)
+}
@@ -0,0 +1,282 @@
+package scope
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sensepost/gowitness/pkg/resolver"
+)
+
+// resolveTimeout is how long a hostname lookup may take when checking it
+// against networks
+const resolveTimeout = 5 * time.Second
+
+// Options are the hosts and ports that are allowed and denied. Hosts are
+// domains like example.com, which include their subdomains, wildcards like
+// *.example.com or dev-*.example.com, IP addresses and CIDR networks like
+// 10.0.0.0/8 or 2001:db8::/32. A scope file is this as json.
+type Options struct {
+	Allow      []string `json:"allow"`
+	Deny       []string `json:"deny"`
+	AllowPorts []int    `json:"allow_ports"`
+	DenyPorts  []int    `json:"deny_ports"`
+
+	// Resolver resolves hostnames the way the browser does. nil uses the
+	// system resolver
+	Resolver *resolver.Resolver `json:"-"`
+}
+
+// Load reads scope options from a json file
+func Load(file string) (*Options, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts Options
+	if err := json.Unmarshal(data, &opts); err != nil {
+		return nil, fmt.Errorf("could not parse scope file %s: %w", file, err)
+	}
+
+	return &opts, nil
+}
+
+// Scope decides which hosts and ports may be scanned. Denied hosts and
+// ports always win. When there are allowed hosts or ports, everything
+// else is out of scope.
+type Scope struct {
+	allow      *hosts
+	deny       *hosts
+	allowPorts []int
+	denyPorts  []int
+	resolver   *resolver.Resolver
+
+	// resolved caches hostname lookups, for checking against networks
+	mu       sync.Mutex
+	resolved map[string][]net.IP
+}
+
+// hosts is a list of host rules
+type hosts struct {
+	domains   []string
+	wildcards []string
+	networks  []*net.IPNet
+}
+
+// New parses scope options
+func New(opts Options) (*Scope, error) {
+	allow, err := parseHosts(opts.Allow)
+	if err != nil {
+		return nil, err
+	}
+	deny, err := parseHosts(opts.Deny)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, port := range slices.Concat(opts.AllowPorts, opts.DenyPorts) {
+		if port < 1 || port > 65535 {
+			return nil, fmt.Errorf("invalid scope port %d", port)
+		}
+	}
+
+	return &Scope{
+		allow:      allow,
+		deny:       deny,
+		allowPorts: opts.AllowPorts,
+		denyPorts:  opts.DenyPorts,
+		resolver:   opts.Resolver,
+		resolved:   make(map[string][]net.IP),
+	}, nil
+}
+
+func parseHosts(entries []string) (*hosts, error) {
+	h := &hosts{}
+
+	for _, entry := range entries {
+		entry = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(entry)), ".")
+		if entry == "" {
+			continue
+		}
+
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			h.networks = append(h.networks, network)
+			continue
+		}
+
+		if ip := net.ParseIP(strings.Trim(entry, "[]")); ip != nil {
+			bits := 8 * len(ip.To4())
+			if bits == 0 {
+				bits = 8 * net.IPv6len
+			}
+			h.networks = append(h.networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		if strings.ContainsAny(entry, "/: ") {
+			return nil, fmt.Errorf("invalid scope entry %q", entry)
+		}
+
+		if strings.Contains(entry, "*") {
+			if _, err := path.Match(entry, ""); err != nil {
+				return nil, fmt.Errorf("invalid scope wildcard %q: %w", entry, err)
+			}
+			h.wildcards = append(h.wildcards, entry)
+			continue
+		}
+
+		h.domains = append(h.domains, strings.TrimPrefix(entry, "."))
+	}
+
+	return h, nil
+}
+
+func (h *hosts) empty() bool {
+	return len(h.domains) == 0 && len(h.wildcards) == 0 && len(h.networks) == 0
+}
+
+// Empty reports whether the scope has no rules, allowing everything
+func (s *Scope) Empty() bool {
+	return s.allow.empty() && s.deny.empty() && len(s.allowPorts) == 0 && len(s.denyPorts) == 0
+}
+
+// HasAllowed reports whether the scope lists allowed hosts
+func (s *Scope) HasAllowed() bool {
+	return !s.allow.empty()
+}
+
+// AllowsHost reports whether a host (without a port) is in scope.
+// Hostnames match networks when they resolve into one.
+func (s *Scope) AllowsHost(host string) bool {
+	return s.checkHost(host) == nil
+}
+
+// Check returns an error saying why a URL is out of scope, or nil if it
+// is in scope. Only URLs that reach the network are checked, so things
+// like data: URLs are always in scope.
+func (s *Scope) Check(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+
+	var port int
+	switch u.Scheme {
+	case "http", "ws":
+		port = 80
+	case "https", "wss":
+		port = 443
+	case "ftp":
+		port = 21
+	default:
+		return nil
+	}
+	if u.Port() != "" {
+		if port, err = strconv.Atoi(u.Port()); err != nil {
+			return fmt.Errorf("invalid port %q", u.Port())
+		}
+	}
+
+	if slices.Contains(s.denyPorts, port) {
+		return fmt.Errorf("port %d is denied", port)
+	}
+	if len(s.allowPorts) > 0 && !slices.Contains(s.allowPorts, port) {
+		return fmt.Errorf("port %d is not allowed", port)
+	}
+
+	return s.checkHost(u.Hostname())
+}
+
+func (s *Scope) checkHost(host string) error {
+	host = strings.TrimSuffix(strings.ToLower(strings.Trim(host, "[]")), ".")
+	if host == "" {
+		return fmt.Errorf("no host")
+	}
+
+	if s.matches(s.deny, host) {
+		return fmt.Errorf("host %s is denied", host)
+	}
+	if !s.allow.empty() && !s.matches(s.allow, host) {
+		return fmt.Errorf("host %s is not allowed", host)
+	}
+
+	return nil
+}
+
+// matches checks if a lower cased host matches any of a list's rules
+func (s *Scope) matches(h *hosts, host string) bool {
+	if ip := net.ParseIP(host); ip != nil {
+		return containsIP(h.networks, ip)
+	}
+
+	for _, domain := range h.domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	for _, wildcard := range h.wildcards {
+		if ok, _ := path.Match(wildcard, host); ok {
+			return true
+		}
+	}
+
+	if len(h.networks) == 0 {
+		return false
+	}
+	for _, ip := range s.resolve(host) {
+		if containsIP(h.networks, ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolve looks up a hostname's addresses, once. Failed lookups resolve
+// to nothing.
+func (s *Scope) resolve(host string) []net.IP {
+	s.mu.Lock()
+	ips, ok := s.resolved[host]
+	s.mu.Unlock()
+	if ok {
+		return ips
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+
+	if ip, ok, err := s.resolver.LookupIP(ctx, host); ok {
+		ips = append(ips, net.ParseIP(ip))
+	} else if err == nil {
+		addrs, _ := net.DefaultResolver.LookupIPAddr(ctx, host)
+		for _, addr := range addrs {
+			ips = append(ips, addr.IP)
+		}
+	}
+
+	s.mu.Lock()
+	s.resolved[host] = ips
+	s.mu.Unlock()
+
+	return ips
+}
+
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
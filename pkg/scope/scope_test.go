@@ -0,0 +1,79 @@
+package scope
+
+import "testing"
+
+func TestCheck(t *testing.T) {
+	s, err := New(Options{
+		Allow:      []string{"example.com", "*.corp.example.org", "dev-*.example.net", "10.0.0.0/8", "192.168.1.10", "2001:db8::/32"},
+		Deny:       []string{"vpn.example.com", "10.0.0.1"},
+		AllowPorts: []int{80, 443, 8443},
+		DenyPorts:  []int{8443},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://example.com/", true},
+		{"http://API.example.com./login", true},
+		{"https://notexample.com/", false},
+		{"https://corp.example.org/", false},
+		{"https://vpn.corp.example.org/", true},
+		{"https://dev-api.example.net/", true},
+		{"https://api.example.net/", false},
+		{"http://10.20.30.40/", true},
+		{"http://10.0.0.1/", false},
+		{"http://192.168.1.10/", true},
+		{"http://192.168.1.11/", false},
+		{"http://[2001:db8::1]/", true},
+		{"https://vpn.example.com/", false},
+		{"https://www.vpn.example.com/", false},
+		{"https://example.com:8443/", false},
+		{"https://example.com:8080/", false},
+		{"wss://example.com/socket", true},
+		{"data:text/plain,hello", true},
+	}
+
+	for _, tt := range tests {
+		if err := s.Check(tt.url); (err == nil) != tt.want {
+			t.Errorf("Check(%q) = %v, want in scope %v", tt.url, err, tt.want)
+		}
+	}
+}
+
+func TestDenyOnly(t *testing.T) {
+	s, err := New(Options{Deny: []string{"*.internal"}, DenyPorts: []int{22}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if s.HasAllowed() {
+		t.Error("HasAllowed() = true, want false")
+	}
+	if err := s.Check("https://anything.example.com/"); err != nil {
+		t.Errorf("Check() = %v, want hosts that are not denied in scope", err)
+	}
+	if err := s.Check("https://db.internal/"); err == nil {
+		t.Error("Check() = nil, want denied wildcard host out of scope")
+	}
+	if err := s.Check("http://example.com:22/"); err == nil {
+		t.Error("Check() = nil, want denied port out of scope")
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	if _, err := New(Options{Allow: []string{"https://example.com/"}}); err == nil {
+		t.Error("New() error = nil, want an error for a URL")
+	}
+	if _, err := New(Options{DenyPorts: []int{70000}}); err == nil {
+		t.Error("New() error = nil, want an error for an invalid port")
+	}
+
+	s, err := New(Options{Allow: []string{" ", ""}})
+	if err != nil || !s.Empty() {
+		t.Errorf("New() = %v, %v, want an empty scope", s, err)
+	}
+}
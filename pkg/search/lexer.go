@@ -0,0 +1,112 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenLParen
+	tokenRParen
+	tokenEOF
+)
+
+// token is a single lexical element of a query
+type token struct {
+	kind tokenKind
+	text string
+	// quoted is set when a word started with a quote, meaning it is a
+	// phrase and should never be interpreted as a field term
+	quoted bool
+	pos    int
+}
+
+// lex splits a query into tokens. Words are separated by whitespace and
+// parentheses. Double quotes group text (including whitespace) into a
+// single word, and may appear after a field operator, e.g. title:"a b".
+func lex(query string) ([]token, error) {
+	var tokens []token
+	runes := []rune(query)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+			continue
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+			continue
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && runes[i+1] != ')':
+			// a leading dash negates the term that follows it
+			tokens = append(tokens, token{kind: tokenNot, text: "-", pos: i})
+			i++
+			continue
+		}
+
+		start := i
+		quoted := r == '"'
+		var word strings.Builder
+
+		for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' {
+			if runes[i] != '"' {
+				word.WriteRune(runes[i])
+				i++
+				continue
+			}
+
+			// consume a quoted section up to the closing quote
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\\' && i+1 < len(runes) && runes[i+1] == '"' {
+					word.WriteRune('"')
+					i += 2
+					continue
+				}
+				if runes[i] == '"' {
+					closed = true
+					i++
+					break
+				}
+				word.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated quote at position %d", start)
+			}
+		}
+
+		text := word.String()
+		kind := tokenWord
+		if !quoted {
+			switch text {
+			case "AND", "&&":
+				kind = tokenAnd
+			case "OR", "||":
+				kind = tokenOr
+			case "NOT":
+				kind = tokenNot
+			}
+		}
+
+		tokens = append(tokens, token{kind: kind, text: text, quoted: quoted, pos: start})
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(runes)})
+
+	return tokens, nil
+}
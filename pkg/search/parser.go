@@ -0,0 +1,489 @@
+package search
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sensepost/gowitness/pkg/imagehash"
+)
+
+// Node is a node in a parsed query
+type Node interface {
+	String() string
+}
+
+// And matches when both sides match
+type And struct {
+	Left, Right Node
+}
+
+// Or matches when either side matches
+type Or struct {
+	Left, Right Node
+}
+
+// Not matches when the wrapped node does not
+type Not struct {
+	Node Node
+}
+
+// Term is a single search term. Terms without a field are free text.
+type Term struct {
+	Field string
+	Op    string
+	Value string
+
+	// typed values, populated for numeric and date fields
+	number int64
+	date   time.Time
+	// day is set when a date was given without a time component
+	day bool
+	// similar is the parsed value of a similar: term
+	similar *similarity
+}
+
+// similarity is the target of a similar: term. Results match when their
+// hash is within distance bits of the target's.
+type similarity struct {
+	algorithm *imagehash.Algorithm
+	// id is the result to compare against, when hash is not given
+	id       uint
+	hash     []byte
+	distance int
+
+	// ids are the matching results, found when the query is applied
+	ids []uint
+}
+
+func (n *And) String() string { return fmt.Sprintf("(%s AND %s)", n.Left, n.Right) }
+func (n *Or) String() string  { return fmt.Sprintf("(%s OR %s)", n.Left, n.Right) }
+func (n *Not) String() string { return fmt.Sprintf("NOT %s", n.Node) }
+func (t *Term) String() string {
+	if t.Field == "" {
+		return strconv.Quote(t.Value)
+	}
+	return fmt.Sprintf("%s%s%s", t.Field, t.Op, strconv.Quote(t.Value))
+}
+
+// Term operators
+const (
+	OpContains     = ":"
+	OpEqual        = "="
+	OpNotEqual     = "!="
+	OpGreater      = ">"
+	OpGreaterEqual = ">="
+	OpLess         = "<"
+	OpLessEqual    = "<="
+)
+
+// operators are ordered so that two character operators are matched first
+var operators = []string{OpGreaterEqual, OpLessEqual, OpNotEqual, OpContains, OpEqual, OpGreater, OpLess}
+
+type fieldKind int
+
+const (
+	textField fieldKind = iota
+	numberField
+	dateField
+	flagField
+	similarField
+	faviconField
+)
+
+// fields maps the field names (and aliases) a query may use to their
+// canonical name
+var fields = map[string]string{
+	"title":      "title",
+	"body":       "body",
+	"html":       "body",
+	"content":    "content",
+	"url":        "url",
+	"final_url":  "final_url",
+	"protocol":   "protocol",
+	"reason":     "reason",
+	"tech":       "tech",
+	"technology": "tech",
+	"header":     "header",
+	"tag":        "tag",
+	"category":   "category",
+	"cookie":     "cookie",
+	"link":       "link",
+	"form":       "form",
+	"input":      "input",
+	"script":     "script",
+	"meta":       "meta",
+	"blocked":    "blocked",
+	"console":    "console",
+	"cert":       "cert",
+	"tls":        "cert",
+	"ip":         "ip",
+	"vhost":      "vhost",
+	"p":          "p",
+	"code":       "code",
+	"status":     "code",
+	"size":       "size",
+	"length":     "size",
+	"id":         "id",
+	"probed_at":  "probed_at",
+	"date":       "probed_at",
+	"is":         "is",
+	"similar":    "similar",
+	"favicon":    "favicon",
+}
+
+var fieldKinds = map[string]fieldKind{
+	"code":      numberField,
+	"size":      numberField,
+	"id":        numberField,
+	"probed_at": dateField,
+	"is":        flagField,
+	"similar":   similarField,
+	"favicon":   faviconField,
+}
+
+// flags are the values the is: field accepts
+var flags = []string{"failed", "reviewed", "blank", "error_page", "soft404"}
+
+// dateLayouts are the accepted formats for date values
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// Parse parses a search query. An empty query returns a nil Node.
+//
+// Terms are either free text or field:value pairs, where the operator
+// may also be one of =, !=, >, >=, < or <=. Terms next to each other
+// are AND'd. AND, OR, NOT (or a leading -) and parentheses may be used
+// to combine them. For example:
+//
+//	tech:nginx (code>=400 OR title:"index of") -tag:reviewed
+func Parse(query string) (Node, error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, nil
+	}
+
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+	}
+
+	return node, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) parseOr() (Node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Or{Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch p.peek().kind {
+		case tokenAnd:
+			p.next()
+		case tokenWord, tokenNot, tokenLParen:
+			// implicit AND
+		default:
+			return left, nil
+		}
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &And{Left: left, Right: right}
+	}
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	if p.peek().kind == tokenNot {
+		p.next()
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Node: node}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, fmt.Errorf("missing closing parenthesis for position %d", t.pos)
+		}
+		return node, nil
+	case tokenWord:
+		return p.parseTerm(t)
+	case tokenEOF:
+		return nil, fmt.Errorf("unexpected end of query")
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+	}
+}
+
+// parseTerm turns a word into a Term, splitting out a field and operator
+// if the word starts with a known field name.
+func (p *parser) parseTerm(t token) (Node, error) {
+	if t.quoted {
+		return &Term{Value: t.text}, nil
+	}
+
+	name, op, value, ok := splitField(t.text)
+	if !ok {
+		return &Term{Value: t.text}, nil
+	}
+
+	// support a value that follows after whitespace, e.g. title: "foo"
+	if value == "" && p.peek().kind == tokenWord {
+		value = p.next().text
+	}
+	if value == "" {
+		return nil, fmt.Errorf("missing value for %s%s at position %d", name, op, t.pos)
+	}
+
+	// x!=y is sugar for NOT x=y
+	negate := false
+	if op == OpNotEqual {
+		negate = true
+		op = OpEqual
+	}
+
+	term := &Term{Field: fields[name], Op: op, Value: value}
+	if err := term.validate(); err != nil {
+		return nil, fmt.Errorf("%s at position %d", err, t.pos)
+	}
+
+	if negate {
+		return &Not{Node: term}, nil
+	}
+
+	return term, nil
+}
+
+// splitField splits a word like code>=400 into its field, operator and
+// value. ok is false if the word does not start with a known field.
+func splitField(word string) (name string, op string, value string, ok bool) {
+	end := strings.IndexFunc(word, func(r rune) bool {
+		return !(r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'))
+	})
+	if end <= 0 {
+		return "", "", "", false
+	}
+
+	name = strings.ToLower(word[:end])
+	if _, known := fields[name]; !known {
+		return "", "", "", false
+	}
+
+	for _, candidate := range operators {
+		if strings.HasPrefix(word[end:], candidate) {
+			return name, candidate, word[end+len(candidate):], true
+		}
+	}
+
+	return "", "", "", false
+}
+
+// validate checks that the operator and value make sense for the field,
+// populating the typed value where needed.
+func (t *Term) validate() error {
+	switch fieldKinds[t.Field] {
+	case numberField:
+		n, err := strconv.ParseInt(t.Value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s expects a number, got %q", t.Field, t.Value)
+		}
+		t.number = n
+	case dateField:
+		for _, layout := range dateLayouts {
+			if d, err := time.ParseInLocation(layout, t.Value, time.Local); err == nil {
+				t.date = d
+				t.day = layout == "2006-01-02"
+				return nil
+			}
+		}
+		return fmt.Errorf("%s expects a date like 2006-01-02, got %q", t.Field, t.Value)
+	case flagField:
+		if t.Op != OpContains && t.Op != OpEqual {
+			return fmt.Errorf("%s does not support the %s operator", t.Field, t.Op)
+		}
+		t.Value = strings.ToLower(t.Value)
+		for _, flag := range flags {
+			if t.Value == flag {
+				return nil
+			}
+		}
+		return fmt.Errorf("unknown value for %s: %q (expected one of %s)", t.Field, t.Value, strings.Join(flags, ", "))
+	case similarField:
+		if t.Op != OpContains && t.Op != OpEqual {
+			return fmt.Errorf("%s does not support the %s operator", t.Field, t.Op)
+		}
+		similar, err := parseSimilarity(t.Value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", t.Field, err)
+		}
+		t.similar = similar
+	case faviconField:
+		if t.Op != OpContains && t.Op != OpEqual {
+			return fmt.Errorf("%s does not support the %s operator", t.Field, t.Op)
+		}
+		// either an md5, or a (signed) mmh3 hash
+		if _, err := hex.DecodeString(t.Value); err == nil && len(t.Value) == 32 {
+			t.Value = strings.ToLower(t.Value)
+			return nil
+		}
+		n, err := strconv.ParseInt(t.Value, 10, 32)
+		if err != nil {
+			return fmt.Errorf("%s expects an mmh3 or md5 hash, got %q", t.Field, t.Value)
+		}
+		t.number = n
+	default:
+		if t.Op != OpContains && t.Op != OpEqual {
+			return fmt.Errorf("%s does not support the %s operator", t.Field, t.Op)
+		}
+	}
+
+	return nil
+}
+
+// parseSimilarity parses the value of a similar: term, which takes the
+// form [algorithm:]target[~distance]. The target is either a result id or
+// a hex encoded hash. The algorithm defaults to phash, and the distance to
+// the algorithm's threshold.
+func parseSimilarity(value string) (*similarity, error) {
+	similar := &similarity{distance: -1}
+
+	if target, distance, ok := strings.Cut(value, "~"); ok {
+		d, err := strconv.Atoi(distance)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid distance %q", distance)
+		}
+		value, similar.distance = target, d
+	}
+
+	name := imagehash.PHash
+	if algorithm, target, ok := strings.Cut(value, ":"); ok {
+		name, value = algorithm, target
+	}
+
+	algorithm, ok := imagehash.Lookup(name)
+	if !ok {
+		var names []string
+		for _, a := range imagehash.Algorithms() {
+			names = append(names, a.Name)
+		}
+		return nil, fmt.Errorf("unknown algorithm %q (expected one of %s)", name, strings.Join(names, ", "))
+	}
+	similar.algorithm = algorithm
+	if similar.distance < 0 {
+		similar.distance = algorithm.Threshold()
+	}
+
+	// hashes are at least 16 hex characters, anything shorter is an id
+	if len(value) >= 16 {
+		hash, err := hex.DecodeString(value)
+		if err != nil || len(hash)*8 != algorithm.Bits {
+			return nil, fmt.Errorf("%q is not a %d bit %s hash", value, algorithm.Bits, algorithm.Name)
+		}
+		similar.hash = hash
+		return similar, nil
+	}
+
+	id, err := strconv.ParseUint(value, 10, 0)
+	if err != nil {
+		return nil, fmt.Errorf("expected a result id or a hash, got %q", value)
+	}
+	similar.id = uint(id)
+
+	return similar, nil
+}
+
+// Terms returns the terms in a query that must match for a result to be
+// returned, i.e. those that are not negated.
+func Terms(node Node) []*Term {
+	var terms []*Term
+
+	var walk func(n Node, negated bool)
+	walk = func(n Node, negated bool) {
+		switch n := n.(type) {
+		case *And:
+			walk(n.Left, negated)
+			walk(n.Right, negated)
+		case *Or:
+			walk(n.Left, negated)
+			walk(n.Right, negated)
+		case *Not:
+			walk(n.Node, !negated)
+		case *Term:
+			if !negated {
+				terms = append(terms, n)
+			}
+		}
+	}
+
+	if node != nil {
+		walk(node, false)
+	}
+
+	return terms
+}
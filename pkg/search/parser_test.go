@@ -0,0 +1,195 @@
+package search
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "Test empty query",
+			query: "   ",
+			want:  "<nil>",
+		},
+		{
+			name:  "Test free text",
+			query: "login",
+			want:  `"login"`,
+		},
+		{
+			name:  "Test free text is AND'd",
+			query: "admin login",
+			want:  `("admin" AND "login")`,
+		},
+		{
+			name:  "Test quoted phrase",
+			query: `"admin login"`,
+			want:  `"admin login"`,
+		},
+		{
+			name:  "Test quoted phrase is never a field",
+			query: `"title:foo"`,
+			want:  `"title:foo"`,
+		},
+		{
+			name:  "Test field with quoted value",
+			query: `title:"index of"`,
+			want:  `title:"index of"`,
+		},
+		{
+			name:  "Test field with value after whitespace",
+			query: `title: "index of"`,
+			want:  `title:"index of"`,
+		},
+		{
+			name:  "Test field alias",
+			query: "status=200",
+			want:  `code="200"`,
+		},
+		{
+			name:  "Test comparison operators",
+			query: "code>=400 code<500 size>1024",
+			want:  `((code>="400" AND code<"500") AND size>"1024")`,
+		},
+		{
+			name:  "Test not equal",
+			query: "code!=404",
+			want:  `NOT code="404"`,
+		},
+		{
+			name:  "Test boolean operators",
+			query: "tech:nginx OR tech:apache AND NOT tag:reviewed",
+			want:  `(tech:"nginx" OR (tech:"apache" AND NOT tag:"reviewed"))`,
+		},
+		{
+			name:  "Test parentheses",
+			query: "(tech:nginx OR tech:apache) -is:failed",
+			want:  `((tech:"nginx" OR tech:"apache") AND NOT is:"failed")`,
+		},
+		{
+			name:  "Test url is free text",
+			query: "https://example.com/login",
+			want:  `"https://example.com/login"`,
+		},
+		{
+			name:  "Test field with url value",
+			query: "final_url:https://example.com",
+			want:  `final_url:"https://example.com"`,
+		},
+		{
+			name:  "Test full-text field",
+			query: `content:"admin panel"`,
+			want:  `content:"admin panel"`,
+		},
+		{
+			name:  "Test date",
+			query: "probed_at>2026-01-01",
+			want:  `probed_at>"2026-01-01"`,
+		},
+		{
+			name:  "Test perception hash",
+			query: "p:e0e0c0c0f0f0f0f0",
+			want:  `p:"e0e0c0c0f0f0f0f0"`,
+		},
+		{
+			name:  "Test similar to a result",
+			query: "similar:42",
+			want:  `similar:"42"`,
+		},
+		{
+			name:  "Test similar to a hash with a distance",
+			query: "similar:dhash:ff00ff00ff00ff00~8",
+			want:  `similar:"dhash:ff00ff00ff00ff00~8"`,
+		},
+		{
+			name:  "Test favicon by mmh3 or md5",
+			query: "favicon:-1137154205 OR favicon=D41D8CD98F00B204E9800998ECF8427E",
+			want:  `(favicon:"-1137154205" OR favicon="d41d8cd98f00b204e9800998ecf8427e")`,
+		},
+		{
+			name:  "Test page element fields",
+			query: `input=password script:"jquery-1." meta:generator`,
+			want:  `((input="password" AND script:"jquery-1.") AND meta:"generator")`,
+		},
+		{
+			name:  "Test lowercase keywords are free text",
+			query: "this or that",
+			want:  `(("this" AND "or") AND "that")`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned an error: %v", tt.query, err)
+			}
+
+			var gotString = "<nil>"
+			if got != nil {
+				gotString = got.String()
+			}
+
+			if gotString != tt.want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.query, gotString, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "Test unterminated quote", query: `title:"index of`},
+		{name: "Test unbalanced parenthesis", query: "(tech:nginx OR tech:apache"},
+		{name: "Test stray closing parenthesis", query: "tech:nginx)"},
+		{name: "Test dangling operator", query: "tech:nginx OR"},
+		{name: "Test missing value", query: "title:"},
+		{name: "Test number field with text", query: "code:ok"},
+		{name: "Test comparison on text field", query: "title>foo"},
+		{name: "Test invalid date", query: "probed_at>yesterday"},
+		{name: "Test unknown flag", query: "is:interesting"},
+		{name: "Test similar with an unknown algorithm", query: "similar:nope:42"},
+		{name: "Test similar with a short hash", query: "similar:dhash:ff00ff00ff00ff00ff"},
+		{name: "Test similar with an invalid distance", query: "similar:42~far"},
+		{name: "Test similar with a comparison", query: "similar>42"},
+		{name: "Test favicon with an invalid hash", query: "favicon:nginx"},
+		{name: "Test favicon with an out of range mmh3", query: "favicon:4294967295"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := Parse(tt.query); err == nil {
+				t.Errorf("Parse(%q) = %s, want an error", tt.query, got)
+			}
+		})
+	}
+}
+
+func TestTerms(t *testing.T) {
+	node, err := Parse("tech:nginx (title:admin OR -tag:reviewed) NOT (code=404 OR -is:failed)")
+	if err != nil {
+		t.Fatalf("Parse returned an error: %v", err)
+	}
+
+	var got []string
+	for _, term := range Terms(node) {
+		got = append(got, term.String())
+	}
+
+	want := []string{`tech:"nginx"`, `title:"admin"`, `is:"failed"`}
+	if len(got) != len(want) {
+		t.Fatalf("Terms() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Terms()[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,402 @@
+package search
+
+import (
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sensepost/gowitness/internal/islazy"
+	"github.com/sensepost/gowitness/pkg/database"
+	"github.com/sensepost/gowitness/pkg/imagehash"
+	"github.com/sensepost/gowitness/pkg/models"
+	"gorm.io/gorm"
+)
+
+// Apply adds the conditions for a parsed query to a query on results. A
+// nil node leaves the query as is.
+func Apply(db *gorm.DB, node Node) *gorm.DB {
+	if node == nil {
+		return db
+	}
+
+	session := db.Session(&gorm.Session{NewDB: true})
+
+	// hash distances can't be calculated in SQL, so similar: terms are
+	// matched up front
+	if err := findSimilar(session, node); err != nil {
+		query := db.Where("1 = 0")
+		query.AddError(err)
+		return query
+	}
+
+	sql, vars := compile(session, node)
+	return db.Where(sql, vars...)
+}
+
+// matchBatchSize is the number of results MatchedTerms checks at a time
+const matchBatchSize = 500
+
+// MatchedTerms returns which of the terms in a query (see Terms) each of
+// the result ids match. Every term is checked in the same query, one per
+// batch of ids. The query must have been applied (see Apply) first, so
+// that its similar: terms are resolved.
+func MatchedTerms(db *gorm.DB, node Node, ids []uint) (map[uint][]*Term, error) {
+	matched := make(map[uint][]*Term)
+
+	terms := Terms(node)
+	if len(terms) == 0 || len(ids) == 0 {
+		return matched, nil
+	}
+
+	session := db.Session(&gorm.Session{NewDB: true})
+
+	// a 0/1 column per term
+	columns := []string{"id"}
+	var vars []interface{}
+	for _, term := range terms {
+		sql, termVars := compileTerm(session, term)
+		columns = append(columns, fmt.Sprintf("CASE WHEN (%s) THEN 1 ELSE 0 END", sql))
+		vars = append(vars, termVars...)
+	}
+
+	for chunk := range slices.Chunk(ids, matchBatchSize) {
+		rows, err := session.Model(&models.Result{}).Select(strings.Join(columns, ", "), vars...).
+			Where("id in ?", chunk).Rows()
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			var id uint
+			flags := make([]int, len(terms))
+			dest := []interface{}{&id}
+			for i := range flags {
+				dest = append(dest, &flags[i])
+			}
+
+			if err := rows.Scan(dest...); err != nil {
+				rows.Close()
+				return nil, err
+			}
+
+			for i, flag := range flags {
+				if flag == 1 {
+					matched[id] = append(matched[id], terms[i])
+				}
+			}
+		}
+
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return matched, nil
+}
+
+// compile builds the SQL condition (and its bind variables) for a node.
+// db is used to build subqueries.
+func compile(db *gorm.DB, node Node) (string, []interface{}) {
+	switch n := node.(type) {
+	case *And:
+		return combine(db, "AND", n.Left, n.Right)
+	case *Or:
+		return combine(db, "OR", n.Left, n.Right)
+	case *Not:
+		sql, vars := compile(db, n.Node)
+		return fmt.Sprintf("NOT (%s)", sql), vars
+	case *Term:
+		return compileTerm(db, n)
+	}
+
+	panic(fmt.Sprintf("search: unknown node type %T", node))
+}
+
+func combine(db *gorm.DB, operator string, left, right Node) (string, []interface{}) {
+	leftSQL, leftVars := compile(db, left)
+	rightSQL, rightVars := compile(db, right)
+
+	return fmt.Sprintf("(%s) %s (%s)", leftSQL, operator, rightSQL), append(leftVars, rightVars...)
+}
+
+// compileTerm builds the condition for a single term
+func compileTerm(db *gorm.DB, t *Term) (string, []interface{}) {
+	switch t.Field {
+	case "":
+		return textMatch(OpContains, t.Value, "url", "final_url", "title")
+	case "title":
+		return textMatch(t.Op, t.Value, "title")
+	case "body":
+		if index := fullTextIndex(db, t); index != nil {
+			return index.Match(t.Value, true)
+		}
+		return textMatch(t.Op, t.Value, "html")
+	case "content":
+		if index := fullTextIndex(db, t); index != nil {
+			return index.Match(t.Value, false)
+		}
+		sql, vars := textMatch(t.Op, t.Value, "title", "html")
+		headerSQL, headerVars := resultsIn(db, &models.Header{}, t, "key", "value")
+		consoleSQL, consoleVars := resultsIn(db, &models.ConsoleLog{}, t, "value")
+
+		return fmt.Sprintf("%s OR %s OR %s", sql, headerSQL, consoleSQL),
+			append(append(vars, headerVars...), consoleVars...)
+	case "url":
+		return textMatch(t.Op, t.Value, "url")
+	case "final_url":
+		return textMatch(t.Op, t.Value, "final_url")
+	case "protocol":
+		return textMatch(t.Op, t.Value, "protocol")
+	case "reason":
+		return textMatch(t.Op, t.Value, "response_reason")
+	case "tech":
+		return resultsIn(db, &models.Technology{}, t, "value")
+	case "header":
+		return resultsIn(db, &models.Header{}, t, "key", "value")
+	case "tag":
+		return resultsIn(db, &models.Tag{}, t, "value")
+	case "category":
+		return resultsIn(db, &models.Category{}, t, "value")
+	case "cookie":
+		return resultsIn(db, &models.Cookie{}, t, "name", "value", "domain")
+	case "console":
+		return resultsIn(db, &models.ConsoleLog{}, t, "value")
+	case "link":
+		return resultsIn(db, &models.Link{}, t, "url")
+	case "form":
+		return resultsIn(db, &models.Form{}, t, "action")
+	case "input":
+		sql, vars := textMatch(t.Op, t.Value, "name", "type")
+		inputs := db.Model(&models.FormInput{}).Select("form_id").Where(sql, vars...)
+
+		return "id in (?)", []interface{}{
+			db.Model(&models.Form{}).Select("result_id").Distinct("result_id").Where("id in (?)", inputs),
+		}
+	case "script":
+		return resultsIn(db, &models.Script{}, t, "src")
+	case "meta":
+		return resultsIn(db, &models.MetaTag{}, t, "name", "content")
+	case "blocked":
+		return resultsIn(db, &models.BlockedRequest{}, t, "url", "reason")
+	case "ip":
+		// the target's own address is on the result, as network logs may
+		// be skipped. virtual hosts are requested by the driver, so the
+		// browser has no remote ip for them
+		sql, vars := textMatch(t.Op, t.Value, "resolved_ip", "vhost_ip")
+		logSQL, logVars := resultsIn(db, &models.NetworkLog{}, t, "remote_ip")
+
+		return fmt.Sprintf("%s OR %s", sql, logSQL), append(vars, logVars...)
+	case "vhost":
+		return textMatch(t.Op, t.Value, "vhost")
+	case "cert":
+		sql, vars := textMatch(t.Op, t.Value, "subject_name", "issuer")
+		sanSQL, sanVars := textMatch(t.Op, t.Value, "value")
+		sans := db.Model(&models.TLSSanList{}).Select("tls_id").Where(sanSQL, sanVars...)
+
+		return "id in (?)", []interface{}{
+			db.Model(&models.TLS{}).Select("result_id").Distinct("result_id").
+				Where(fmt.Sprintf("%s OR id in (?)", sql), append(vars, sans)...),
+		}
+	case "p":
+		return "perception_hash_group_id in (?)", []interface{}{
+			db.Model(&models.Result{}).
+				Select("perception_hash_group_id").Distinct("perception_hash_group_id").
+				// perception hashes are stored with their p: prefix
+				Where("perception_hash = ?", fmt.Sprintf("p:%s", strings.TrimPrefix(t.Value, "p:"))),
+		}
+	case "code":
+		return compare(t, "response_code", t.number)
+	case "size":
+		return compare(t, "content_length", t.number)
+	case "id":
+		return compare(t, "id", t.number)
+	case "probed_at":
+		return compareDate(t, "probed_at")
+	case "is":
+		return fmt.Sprintf("%s = ?", t.Value), []interface{}{true}
+	case "similar":
+		return "id in ?", []interface{}{t.similar.ids}
+	case "favicon":
+		favicons := db.Model(&models.Favicon{}).Select("result_id")
+		if len(t.Value) == 32 {
+			return "id in (?)", []interface{}{favicons.Where("md5 = ?", t.Value)}
+		}
+		return "id in (?)", []interface{}{favicons.Where("mmh3 = ?", t.number)}
+	}
+
+	panic(fmt.Sprintf("search: unknown field %q", t.Field))
+}
+
+// fullTextIndex returns the full-text index to use for a term, if any
+func fullTextIndex(db *gorm.DB, t *Term) database.FullTextIndex {
+	if t.Op != OpContains {
+		return nil
+	}
+
+	return database.FullTextReady(db)
+}
+
+// Rank ranks results by how well they match the full-text terms in a
+// query, returning a highlighted snippet for each. It returns nil if the
+// query has no full-text terms, or if there is no complete full-text index.
+func Rank(db *gorm.DB, node Node, ids []uint) (map[uint]database.FullTextHit, error) {
+	index := database.FullTextReady(db)
+	if index == nil {
+		return nil, nil
+	}
+
+	var values []string
+	for _, term := range Terms(node) {
+		if (term.Field == "body" || term.Field == "content") && term.Op == OpContains {
+			values = append(values, term.Value)
+		}
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	return index.Rank(db, values, ids)
+}
+
+// findSimilar finds the results that match each similar: term in a query
+func findSimilar(db *gorm.DB, node Node) error {
+	switch n := node.(type) {
+	case *And:
+		if err := findSimilar(db, n.Left); err != nil {
+			return err
+		}
+		return findSimilar(db, n.Right)
+	case *Or:
+		if err := findSimilar(db, n.Left); err != nil {
+			return err
+		}
+		return findSimilar(db, n.Right)
+	case *Not:
+		return findSimilar(db, n.Node)
+	case *Term:
+		if n.similar == nil {
+			return nil
+		}
+
+		ids, err := similarResults(db, n.similar)
+		if err != nil {
+			return err
+		}
+		n.similar.ids = ids
+	}
+
+	return nil
+}
+
+// similarResults returns the ids of results with a hash within the
+// distance of a similar: term's target
+func similarResults(db *gorm.DB, similar *similarity) ([]uint, error) {
+	// perception hashes live on results, the rest in their own table
+	var hashes []struct {
+		ID   uint
+		Hash string
+	}
+	query := db.Model(&models.ImageHash{}).Select("result_id as id, value as hash").
+		Where("algorithm = ?", similar.algorithm.Name)
+	if similar.algorithm.Name == imagehash.PHash {
+		query = db.Model(&models.Result{}).Select("id, perception_hash as hash").
+			Where("perception_hash != ?", "")
+	}
+	if err := query.Scan(&hashes).Error; err != nil {
+		return nil, err
+	}
+
+	target := similar.hash
+	if target == nil {
+		for _, hash := range hashes {
+			if hash.ID == similar.id {
+				target, _ = hex.DecodeString(strings.TrimPrefix(hash.Hash, "p:"))
+				break
+			}
+		}
+		if target == nil {
+			return nil, fmt.Errorf("result %d has no %s hash", similar.id, similar.algorithm.Name)
+		}
+	}
+
+	ids := []uint{}
+	for _, hash := range hashes {
+		value, err := hex.DecodeString(strings.TrimPrefix(hash.Hash, "p:"))
+		if err != nil {
+			continue
+		}
+
+		distance, err := islazy.HammingDistance(target, value)
+		if err != nil || distance > similar.distance {
+			continue
+		}
+		ids = append(ids, hash.ID)
+	}
+
+	return ids, nil
+}
+
+// textMatch matches a value against any of the columns, case
+// insensitively. The : operator matches substrings, = the full value.
+func textMatch(op string, value string, columns ...string) (string, []interface{}) {
+	comparison, arg := "LIKE", "%"+strings.ToLower(value)+"%"
+	if op == OpEqual {
+		comparison, arg = "=", strings.ToLower(value)
+	}
+
+	var conditions []string
+	var vars []interface{}
+	for _, column := range columns {
+		conditions = append(conditions, fmt.Sprintf("LOWER(%s) %s ?", column, comparison))
+		vars = append(vars, arg)
+	}
+
+	return strings.Join(conditions, " OR "), vars
+}
+
+// resultsIn matches results that have a related row in model where any of
+// the columns match the term
+func resultsIn(db *gorm.DB, model interface{}, t *Term, columns ...string) (string, []interface{}) {
+	sql, vars := textMatch(t.Op, t.Value, columns...)
+
+	return "id in (?)", []interface{}{
+		db.Model(model).Select("result_id").Distinct("result_id").Where(sql, vars...),
+	}
+}
+
+// compare compares a column to a value. The : operator is treated as =.
+func compare(t *Term, column string, value interface{}) (string, []interface{}) {
+	op := t.Op
+	if op == OpContains {
+		op = OpEqual
+	}
+
+	return fmt.Sprintf("%s %s ?", column, op), []interface{}{value}
+}
+
+// compareDate compares a column to a date. Values without a time component
+// refer to the whole day, so probed_at:2026-01-01 matches anything on that
+// day and probed_at>2026-01-01 anything from the next day onwards.
+func compareDate(t *Term, column string) (string, []interface{}) {
+	if !t.day {
+		return compare(t, column, t.date)
+	}
+
+	start, end := t.date, t.date.AddDate(0, 0, 1)
+
+	switch t.Op {
+	case OpGreater:
+		return fmt.Sprintf("%s >= ?", column), []interface{}{end}
+	case OpGreaterEqual:
+		return fmt.Sprintf("%s >= ?", column), []interface{}{start}
+	case OpLess:
+		return fmt.Sprintf("%s < ?", column), []interface{}{start}
+	case OpLessEqual:
+		return fmt.Sprintf("%s < ?", column), []interface{}{end}
+	}
+
+	return fmt.Sprintf("%s >= ? AND %s < ?", column, column), []interface{}{start, end}
+}
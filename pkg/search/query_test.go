@@ -0,0 +1,61 @@
+package search
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/sensepost/gowitness/pkg/database"
+	"github.com/sensepost/gowitness/pkg/models"
+)
+
+func TestApply(t *testing.T) {
+	conn, err := database.Connection("sqlite://"+filepath.Join(t.TempDir(), "gowitness.sqlite3"), false, false)
+	if err != nil {
+		t.Fatalf("could not open a test database: %v", err)
+	}
+	t.Cleanup(func() {
+		if db, err := conn.DB(); err == nil {
+			db.Close()
+		}
+	})
+
+	results := []*models.Result{
+		{URL: "https://10.0.0.1", ResolvedIP: "10.0.0.1"},
+		{URL: "https://app.internal", VHost: "app.internal", VHostIP: "10.0.0.2"},
+		{URL: "https://intranet.internal", VHost: "intranet.internal", VHostIP: "10.0.0.2"},
+		{URL: "https://example.com", Network: []models.NetworkLog{{URL: "https://example.com", RemoteIP: "10.0.0.3"}}},
+	}
+	for _, result := range results {
+		if err := conn.Create(result).Error; err != nil {
+			t.Fatalf("could not create a test result: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"ip:10.0.0.1", []string{"https://10.0.0.1"}},
+		{"ip=10.0.0.2", []string{"https://app.internal", "https://intranet.internal"}},
+		{"ip:10.0.0.3", []string{"https://example.com"}},
+		{"vhost:intranet", []string{"https://intranet.internal"}},
+		{"vhost=app.internal", []string{"https://app.internal"}},
+		{"vhost:internal -ip:10.0.0.1", []string{"https://app.internal", "https://intranet.internal"}},
+	}
+	for _, tt := range tests {
+		query, err := Parse(tt.query)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.query, err)
+		}
+
+		var got []string
+		if err := Apply(conn.Model(&models.Result{}), query).Order("id").
+			Pluck("url", &got).Error; err != nil {
+			t.Fatalf("Apply(%q) error = %v", tt.query, err)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Apply(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package signatures
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sensepost/gowitness/pkg/models"
+)
+
+//go:embed default.json
+var defaultSignatures []byte
+
+// Signature assigns a category to the results that match its rules
+type Signature struct {
+	// Name identifies the signature, and is stored with the category
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	// Match is either any (the default) or all, for whether any or all
+	// of the rules need to match
+	Match string  `json:"match,omitempty"`
+	Rules []*Rule `json:"rules"`
+}
+
+// Rule matches one part of a result
+type Rule struct {
+	// Field is one of title, body, url, code, header, cookie or tech
+	Field string `json:"field"`
+	// Key limits header and cookie rules to a single header or cookie
+	// name. A rule with a key and no matcher checks that it exists.
+	Key string `json:"key,omitempty"`
+
+	// Contains and Equals are case insensitive. Only one matcher may be
+	// set on a rule.
+	Contains string `json:"contains,omitempty"`
+	Equals   string `json:"equals,omitempty"`
+	Regex    string `json:"regex,omitempty"`
+
+	regex *regexp.Regexp
+}
+
+// Engine categorizes results using signatures
+type Engine struct {
+	signatures []*Signature
+}
+
+// New returns an engine with the default signatures, as well as any in
+// paths. A path may be a signature file, or a directory of .json files.
+func New(paths ...string) (*Engine, error) {
+	engine := &Engine{}
+	if err := engine.add("default signatures", defaultSignatures); err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		files := []string{path}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			if files, err = filepath.Glob(filepath.Join(path, "*.json")); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			if err := engine.add(file, data); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return engine, nil
+}
+
+// add parses and validates a signature file
+func (e *Engine) add(source string, data []byte) error {
+	var signatures []*Signature
+	if err := json.Unmarshal(data, &signatures); err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+
+	for _, signature := range signatures {
+		if err := signature.compile(); err != nil {
+			return fmt.Errorf("%s: signature %q: %w", source, signature.Name, err)
+		}
+	}
+
+	e.signatures = append(e.signatures, signatures...)
+	return nil
+}
+
+// Signatures returns the signatures the engine uses
+func (e *Engine) Signatures() []*Signature {
+	return e.signatures
+}
+
+// Match returns every signature that matches a result, in signature order
+func (e *Engine) Match(result *models.Result) []*Signature {
+	var matched []*Signature
+	for _, signature := range e.signatures {
+		if signature.matches(result) {
+			matched = append(matched, signature)
+		}
+	}
+
+	return matched
+}
+
+// Categorize returns the categories a result belongs to, in signature
+// order. A category is only returned once, for the first signature that
+// assigns it.
+func (e *Engine) Categorize(result *models.Result) []models.Category {
+	return Categories(e.Match(result))
+}
+
+// Categories returns the unique categories assigned by matched signatures
+func Categories(matched []*Signature) []models.Category {
+	var categories []models.Category
+	seen := make(map[string]bool)
+
+	for _, signature := range matched {
+		if seen[signature.Category] {
+			continue
+		}
+
+		seen[signature.Category] = true
+		categories = append(categories, models.Category{
+			Value:     signature.Category,
+			Signature: signature.Name,
+		})
+	}
+
+	return categories
+}
+
+func (s *Signature) compile() error {
+	if s.Name == "" {
+		return errors.New("a name is required")
+	}
+	if s.Category == "" {
+		return errors.New("a category is required")
+	}
+	if s.Match == "" {
+		s.Match = "any"
+	}
+	if s.Match != "any" && s.Match != "all" {
+		return fmt.Errorf("unknown match %q (expected any or all)", s.Match)
+	}
+	if len(s.Rules) == 0 {
+		return errors.New("at least one rule is required")
+	}
+
+	for _, rule := range s.Rules {
+		if err := rule.compile(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Signature) matches(result *models.Result) bool {
+	for _, rule := range s.Rules {
+		matched := rule.matches(result)
+		if s.Match == "any" && matched {
+			return true
+		}
+		if s.Match == "all" && !matched {
+			return false
+		}
+	}
+
+	return s.Match == "all"
+}
+
+func (r *Rule) compile() error {
+	switch r.Field {
+	case "title", "body", "url", "code", "tech":
+		if r.Key != "" {
+			return fmt.Errorf("%s rules do not take a key", r.Field)
+		}
+	case "header", "cookie":
+	default:
+		return fmt.Errorf("unknown field %q", r.Field)
+	}
+
+	matchers := 0
+	for _, matcher := range []string{r.Contains, r.Equals, r.Regex} {
+		if matcher != "" {
+			matchers++
+		}
+	}
+	if matchers > 1 {
+		return fmt.Errorf("%s rule has more than one matcher", r.Field)
+	}
+	if matchers == 0 && r.Key == "" {
+		return fmt.Errorf("%s rule needs one of contains, equals or regex", r.Field)
+	}
+
+	if r.Regex != "" {
+		regex, err := regexp.Compile(r.Regex)
+		if err != nil {
+			return fmt.Errorf("%s rule: %w", r.Field, err)
+		}
+		r.regex = regex
+	}
+
+	return nil
+}
+
+func (r *Rule) matches(result *models.Result) bool {
+	for _, value := range r.values(result) {
+		if r.match(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// values returns the parts of a result the rule applies to
+func (r *Rule) values(result *models.Result) []string {
+	switch r.Field {
+	case "title":
+		return []string{result.Title}
+	case "body":
+		return []string{result.HTML}
+	case "url":
+		return []string{result.URL, result.FinalURL}
+	case "code":
+		return []string{strconv.Itoa(result.ResponseCode)}
+	case "tech":
+		var values []string
+		for _, tech := range result.Technologies {
+			values = append(values, tech.Value)
+		}
+		return values
+	case "header":
+		var values []string
+		for _, header := range result.Headers {
+			if r.Key == "" || strings.EqualFold(header.Key, r.Key) {
+				values = append(values, header.Value)
+			}
+		}
+		return values
+	case "cookie":
+		var values []string
+		for _, cookie := range result.Cookies {
+			if r.Key == "" || strings.EqualFold(cookie.Name, r.Key) {
+				values = append(values, cookie.Value)
+			}
+		}
+		return values
+	}
+
+	return nil
+}
+
+func (r *Rule) match(value string) bool {
+	switch {
+	case r.Contains != "":
+		return strings.Contains(strings.ToLower(value), strings.ToLower(r.Contains))
+	case r.Equals != "":
+		return strings.EqualFold(value, r.Equals)
+	case r.regex != nil:
+		return r.regex.MatchString(value)
+	}
+
+	// a key with no matcher only checks that the header or cookie exists
+	return true
+}
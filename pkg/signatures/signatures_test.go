@@ -0,0 +1,116 @@
+package signatures
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/sensepost/gowitness/pkg/models"
+)
+
+func TestCategorize(t *testing.T) {
+	engine, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		result *models.Result
+		want   []string
+	}{
+		{
+			name:   "login form",
+			result: &models.Result{ResponseCode: 200, HTML: `<form><input name="p" type="password"></form>`},
+			want:   []string{"login form"},
+		},
+		{
+			name: "basic auth",
+			result: &models.Result{ResponseCode: 401, Headers: []models.Header{
+				{Key: "www-authenticate", Value: `Basic realm="router"`},
+			}},
+			want: []string{"login form"},
+		},
+		{
+			name:   "default page",
+			result: &models.Result{ResponseCode: 200, Title: "Apache2 Ubuntu Default Page: It works"},
+			want:   []string{"default web server page"},
+		},
+		{
+			name:   "directory listing",
+			result: &models.Result{ResponseCode: 200, Title: "Index of /backups"},
+			want:   []string{"directory listing"},
+		},
+		{
+			name: "admin login on a network device",
+			result: &models.Result{ResponseCode: 200, Title: "FortiGate - Admin Login", Headers: []models.Header{
+				{Key: "Server", Value: "xxxxxxxx-xxxxx"},
+			}},
+			want: []string{"login form", "admin panel", "network device"},
+		},
+		{
+			name:   "not found",
+			result: &models.Result{ResponseCode: 404, Title: "Not Found"},
+			want:   []string{"parked/404"},
+		},
+		{
+			name:   "nothing",
+			result: &models.Result{ResponseCode: 200, Title: "Welcome to our shop"},
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, category := range engine.Categorize(tt.result) {
+				got = append(got, category.Value)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Categorize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewWithFiles(t *testing.T) {
+	dir := t.TempDir()
+	custom := `[{"name": "grafana", "category": "monitoring", "match": "all",
+		"rules": [{"field": "title", "contains": "grafana"}, {"field": "cookie", "key": "grafana_session"}]}]`
+	if err := os.WriteFile(filepath.Join(dir, "custom.json"), []byte(custom), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	engine, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	result := &models.Result{Title: "Grafana", Cookies: []models.Cookie{{Name: "grafana_session", Value: "x"}}}
+	if got := engine.Categorize(result); len(got) != 1 || got[0].Value != "monitoring" || got[0].Signature != "grafana" {
+		t.Errorf("Categorize() = %+v, want the monitoring category", got)
+	}
+
+	result.Cookies = nil
+	if got := engine.Categorize(result); len(got) != 0 {
+		t.Errorf("Categorize() = %+v, want no categories when only some rules match", got)
+	}
+
+	invalid := []string{
+		`[{"name": "x", "category": "y", "rules": []}]`,
+		`[{"name": "x", "category": "y", "rules": [{"field": "nope", "contains": "a"}]}]`,
+		`[{"name": "x", "category": "y", "rules": [{"field": "title", "contains": "a", "regex": "b"}]}]`,
+		`[{"name": "x", "category": "y", "rules": [{"field": "body", "regex": "("}]}]`,
+		`[{"name": "x", "category": "y", "match": "some", "rules": [{"field": "title", "contains": "a"}]}]`,
+	}
+	for _, signatures := range invalid {
+		path := filepath.Join(t.TempDir(), "invalid.json")
+		if err := os.WriteFile(path, []byte(signatures), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := New(path); err == nil {
+			t.Errorf("New() with %s should fail", signatures)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package soft404
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sensepost/gowitness/pkg/httpclient"
+	"github.com/sensepost/gowitness/pkg/models"
+	"github.com/sensepost/gowitness/pkg/proxies"
+	"github.com/sensepost/gowitness/pkg/resolver"
+)
+
+const (
+	// maxBodySize is the most of a probe's response body that is read
+	maxBodySize = 2 << 20
+	// minSimilarity is the share of words a page needs to have in common
+	// with a host's probe to be the same page
+	minSimilarity = 0.8
+)
+
+var (
+	titleRegex    = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	scriptRegex   = regexp.MustCompile(`(?is)<(script|style|noscript)[^>]*>.*?</(script|style|noscript)>`)
+	tagRegex      = regexp.MustCompile(`(?s)<[^>]*>`)
+	wordRegex     = regexp.MustCompile(`[\p{L}\p{N}]+`)
+	notFoundRegex = regexp.MustCompile(`(?i)\b(404|not found|page (cannot|could not|can't) be found|does not exist|no longer exists?)\b`)
+)
+
+// Detector finds soft 404s: pages that respond with a success code, but
+// are really the catch-all page a host returns for any path. Each host is
+// probed once with a random path, and results are compared to that probe.
+type Detector struct {
+	client *httpclient.Client
+
+	mu     sync.Mutex
+	probes map[string]*probe
+}
+
+// probe is the response to a random path on a host
+type probe struct {
+	// done is closed once the probe was sent
+	done chan struct{}
+	// cancelled is set if the probe failed because the context it was
+	// sent with ended, rather than because of the host
+	cancelled bool
+
+	err   error
+	code  int
+	title string
+	words map[string]bool
+}
+
+// NewDetector returns a Detector that probes hosts the way the browser
+// would (see httpclient.New)
+func NewDetector(proxy string, userAgent string, headers []string, timeout time.Duration, res *resolver.Resolver) (*Detector, error) {
+	client, err := httpclient.New(proxy, userAgent, headers, timeout, res)
+	if err != nil {
+		return nil, err
+	}
+	// the probe's own status is what matters, not where it redirects to
+	client.HTTP.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	return &Detector{
+		client: client,
+		probes: make(map[string]*probe),
+	}, nil
+}
+
+// Check reports whether a result is a soft 404. Only successful results
+// can be one, and only when a random path on the same host also succeeds
+// with the same page. As plenty of sites serve their home page for any
+// path, a result for the root of a host also needs to read like a not
+// found page. The probe is only sent if the URL check on ctx (see
+// httpclient.WithURLCheck) allows it.
+func (d *Detector) Check(ctx context.Context, result *models.Result) (bool, error) {
+	if result.Failed || result.ResponseCode < 200 || result.ResponseCode >= 300 {
+		return false, nil
+	}
+
+	target := result.FinalURL
+	if target == "" {
+		target = result.URL
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return false, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false, nil
+	}
+
+	p := d.probe(ctx, u.Scheme+"://"+u.Host, u.Hostname())
+	if p.err != nil {
+		return false, p.err
+	}
+	if p.code < 200 || p.code >= 300 {
+		return false, nil
+	}
+
+	title := strings.TrimSpace(result.Title)
+	words := words(result.HTML)
+	if !strings.EqualFold(title, p.title) || similarity(words, p.words) < minSimilarity {
+		return false, nil
+	}
+
+	if u.Path == "" || u.Path == "/" {
+		return notFoundRegex.MatchString(title) || notFoundRegex.MatchString(text(result.HTML)), nil
+	}
+
+	return true, nil
+}
+
+// probe requests a random path on a host, once. A virtual host is probed
+// once for each address it is scanned on, and a host is probed once for
+// each proxy it is reached through. A probe that failed because its
+// context ended is sent again for the next result on the host.
+func (d *Detector) probe(ctx context.Context, host string, hostname string) *probe {
+	key := host
+	if ip, ok := resolver.HostIP(ctx, hostname); ok {
+		key += "@" + ip
+	}
+	if proxy := proxies.ContextProxy(ctx); proxy != "" {
+		key += " via " + proxy
+	}
+
+	for {
+		d.mu.Lock()
+		p, ok := d.probes[key]
+		if !ok {
+			p = &probe{done: make(chan struct{})}
+			d.probes[key] = p
+		}
+		d.mu.Unlock()
+
+		if !ok {
+			p.err = p.fetch(ctx, d, host+"/"+randomPath())
+			if p.err != nil && ctx.Err() != nil {
+				p.cancelled = true
+
+				d.mu.Lock()
+				delete(d.probes, key)
+				d.mu.Unlock()
+			}
+			close(p.done)
+
+			return p
+		}
+
+		select {
+		case <-p.done:
+		case <-ctx.Done():
+			return &probe{err: ctx.Err()}
+		}
+
+		// probe again if the other result's context ended, and ours didn't
+		if !p.cancelled {
+			return p
+		}
+	}
+}
+
+func (p *probe) fetch(ctx context.Context, d *Detector, target string) error {
+	res, err := d.client.Get(ctx, target)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxBodySize))
+	if err != nil {
+		return err
+	}
+
+	p.code = res.StatusCode
+	if match := titleRegex.FindSubmatch(body); match != nil {
+		p.title = strings.TrimSpace(html.UnescapeString(string(match[1])))
+	}
+	p.words = words(string(body))
+
+	return nil
+}
+
+// randomPath returns a path that is very unlikely to exist
+func randomPath() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// text returns the visible text of an html document
+func text(document string) string {
+	document = scriptRegex.ReplaceAllString(document, " ")
+	document = tagRegex.ReplaceAllString(document, " ")
+
+	return html.UnescapeString(document)
+}
+
+// words returns the unique, lower cased words in an html document's text
+func words(document string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range wordRegex.FindAllString(strings.ToLower(text(document)), -1) {
+		words[word] = true
+	}
+
+	return words
+}
+
+// similarity is the Jaccard index of two sets of words. Two empty pages
+// are the same.
+func similarity(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+
+	shared := 0
+	for word := range a {
+		if b[word] {
+			shared++
+		}
+	}
+
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
@@ -0,0 +1,143 @@
+package soft404
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sensepost/gowitness/pkg/models"
+	"github.com/sensepost/gowitness/pkg/proxies"
+)
+
+const catchAll = `<html><head><title>Oops</title></head><body>
+<h1>Sorry, the page you requested was not found</h1><p>Try the home page instead.</p></body></html>`
+
+func TestCheck(t *testing.T) {
+	// a host that returns the same page for any path
+	soft := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(catchAll))
+	}))
+	defer soft.Close()
+
+	// a host with real 404s
+	hard := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer hard.Close()
+
+	detector, err := NewDetector("", "gowitness", nil, 5*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewDetector() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		result *models.Result
+		want   bool
+	}{
+		{
+			name:   "catch-all page",
+			result: &models.Result{URL: soft.URL + "/admin", ResponseCode: 200, Title: "Oops", HTML: catchAll},
+			want:   true,
+		},
+		{
+			name:   "catch-all page on the root",
+			result: &models.Result{URL: soft.URL + "/", ResponseCode: 200, Title: "Oops", HTML: catchAll},
+			want:   true,
+		},
+		{
+			name:   "different page",
+			result: &models.Result{URL: soft.URL + "/admin", ResponseCode: 200, Title: "Admin", HTML: "<h1>Admin console</h1>"},
+			want:   false,
+		},
+		{
+			name:   "host with real 404s",
+			result: &models.Result{URL: hard.URL + "/admin", ResponseCode: 200, Title: "Oops", HTML: catchAll},
+			want:   false,
+		},
+		{
+			name:   "error response",
+			result: &models.Result{URL: soft.URL + "/admin", ResponseCode: 500, Title: "Oops", HTML: catchAll},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := detector.Check(context.Background(), tt.result)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckRootHomePage(t *testing.T) {
+	// sites that serve their home page for any path are not soft 404s on
+	// the root
+	home := `<html><head><title>Shop</title></head><body><h1>Welcome to our shop</h1></body></html>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(home))
+	}))
+	defer server.Close()
+
+	detector, err := NewDetector("", "gowitness", nil, 5*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewDetector() error = %v", err)
+	}
+
+	got, err := detector.Check(context.Background(), &models.Result{URL: server.URL, ResponseCode: 200, Title: "Shop", HTML: home})
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if got {
+		t.Error("Check() = true, want the home page not to be a soft 404")
+	}
+}
+
+func TestCheckProbeRetries(t *testing.T) {
+	var probes atomic.Int32
+	soft := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probes.Add(1)
+		w.Write([]byte(catchAll))
+	}))
+	defer soft.Close()
+
+	detector, err := NewDetector("", "gowitness", nil, 5*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewDetector() error = %v", err)
+	}
+	result := &models.Result{URL: soft.URL + "/admin", ResponseCode: 200, Title: "Oops", HTML: catchAll}
+
+	// a probe whose context ended is not kept for the host
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := detector.Check(cancelled, result); err == nil {
+		t.Fatal("Check() with a cancelled context did not fail")
+	}
+
+	got, err := detector.Check(context.Background(), result)
+	if err != nil {
+		t.Fatalf("Check() after a cancelled probe error = %v", err)
+	}
+	if !got {
+		t.Error("Check() after a cancelled probe = false, want true")
+	}
+
+	// the host is probed again through another proxy, and only once
+	proxied := proxies.WithProxy(context.Background(), proxies.Direct)
+	for range 2 {
+		if _, err := detector.Check(proxied, result); err != nil {
+			t.Fatalf("Check() through a proxy error = %v", err)
+		}
+	}
+	if n := probes.Load(); n != 2 {
+		t.Errorf("host was probed %d times, want once without and once with the proxy", n)
+	}
+}
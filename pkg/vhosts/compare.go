@@ -0,0 +1,119 @@
+// Package vhosts compares the responses of virtual hosts on the same IP
+package vhosts
+
+import (
+	"cmp"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sensepost/gowitness/pkg/models"
+)
+
+// Comparison is the virtual hosts requested from an IP, scheme and port,
+// along with the response of the IP without a hostname
+type Comparison struct {
+	IP     string
+	Scheme string
+	Port   int
+
+	// Baseline is the IP's response without a hostname. nil if it was not
+	// requested
+	Baseline *models.Result
+	// Hosts are the virtual host responses, by host
+	Hosts []*models.Result
+}
+
+// Differs checks if a virtual host's response is unlike the baseline's,
+// meaning the host is likely configured on the IP. Without a baseline,
+// every response differs.
+func (c *Comparison) Differs(result *models.Result) bool {
+	b := c.Baseline
+	if b == nil {
+		return true
+	}
+
+	if result.Failed != b.Failed || result.ResponseCode != b.ResponseCode {
+		return true
+	}
+	if result.PerceptionHashGroupId != 0 && b.PerceptionHashGroupId != 0 &&
+		result.PerceptionHashGroupId != b.PerceptionHashGroupId {
+		return true
+	}
+
+	return !strings.EqualFold(strings.TrimSpace(result.Title), strings.TrimSpace(b.Title))
+}
+
+// Compare groups virtual host results with the baselines of the IP they
+// were requested from. Results that are neither are ignored. The latest
+// result for a URL is used when there is more than one.
+func Compare(results []*models.Result) []*Comparison {
+	comparisons := make(map[string]*Comparison)
+	latest := make(map[string]*models.Result)
+
+	get := func(ip, scheme string, port int) *Comparison {
+		key := scheme + "://" + net.JoinHostPort(ip, strconv.Itoa(port))
+		if c, ok := comparisons[key]; ok {
+			return c
+		}
+		c := &Comparison{IP: ip, Scheme: scheme, Port: port}
+		comparisons[key] = c
+		return c
+	}
+
+	for _, result := range results {
+		u, err := url.Parse(result.URL)
+		if err != nil {
+			continue
+		}
+		scheme, port := u.Scheme, port(u)
+
+		switch {
+		case result.VHostIP != "":
+			key := result.VHostIP + " " + result.URL
+			if previous, ok := latest[key]; ok && previous.ProbedAt.After(result.ProbedAt) {
+				continue
+			}
+			latest[key] = result
+
+			c := get(result.VHostIP, scheme, port)
+			c.Hosts = slices.DeleteFunc(c.Hosts, func(r *models.Result) bool { return r.URL == result.URL })
+			c.Hosts = append(c.Hosts, result)
+		case net.ParseIP(u.Hostname()) != nil:
+			c := get(u.Hostname(), scheme, port)
+			if c.Baseline == nil || result.ProbedAt.After(c.Baseline.ProbedAt) {
+				c.Baseline = result
+			}
+		}
+	}
+
+	var sorted []*Comparison
+	for _, c := range comparisons {
+		// baselines for ips without virtual hosts are not comparisons
+		if len(c.Hosts) == 0 {
+			continue
+		}
+
+		slices.SortFunc(c.Hosts, func(a, b *models.Result) int { return cmp.Compare(a.VHost, b.VHost) })
+		sorted = append(sorted, c)
+	}
+	slices.SortFunc(sorted, func(a, b *Comparison) int {
+		return cmp.Or(cmp.Compare(a.IP, b.IP), cmp.Compare(a.Port, b.Port), cmp.Compare(a.Scheme, b.Scheme))
+	})
+
+	return sorted
+}
+
+// port returns a URL's port, or its scheme's default
+func port(u *url.URL) int {
+	if p, err := strconv.Atoi(u.Port()); err == nil {
+		return p
+	}
+	if u.Scheme == "https" {
+		return 443
+	}
+
+	return 80
+}
@@ -0,0 +1,40 @@
+package vhosts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sensepost/gowitness/pkg/models"
+)
+
+func TestCompare(t *testing.T) {
+	now := time.Now()
+	results := []*models.Result{
+		{URL: "https://10.0.0.1", ResponseCode: 404, Title: "Not Found", ProbedAt: now},
+		{URL: "https://app.internal", VHost: "app.internal", VHostIP: "10.0.0.1", ResponseCode: 200, Title: "App", ProbedAt: now},
+		{URL: "https://old.internal", VHost: "old.internal", VHostIP: "10.0.0.1", ResponseCode: 404, Title: "not found", ProbedAt: now},
+		{URL: "https://app.internal", VHost: "app.internal", VHostIP: "10.0.0.2", ResponseCode: 200, Title: "App", ProbedAt: now},
+		{URL: "http://10.0.0.3", ResponseCode: 200, ProbedAt: now},
+	}
+
+	comparisons := Compare(results)
+	if len(comparisons) != 2 {
+		t.Fatalf("Compare() returned %d comparisons, want 2", len(comparisons))
+	}
+
+	first := comparisons[0]
+	if first.IP != "10.0.0.1" || first.Port != 443 || first.Baseline != results[0] || len(first.Hosts) != 2 {
+		t.Fatalf("Compare()[0] = %+v", first)
+	}
+	if !first.Differs(first.Hosts[0]) {
+		t.Errorf("Differs(%s) = false, want true", first.Hosts[0].VHost)
+	}
+	if first.Differs(first.Hosts[1]) {
+		t.Errorf("Differs(%s) = true, want false", first.Hosts[1].VHost)
+	}
+
+	// without a baseline, every host differs
+	if second := comparisons[1]; second.Baseline != nil || !second.Differs(second.Hosts[0]) {
+		t.Errorf("Compare()[1] = %+v, want no baseline", second)
+	}
+}
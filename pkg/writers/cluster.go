@@ -0,0 +1,131 @@
+package writers
+
+import (
+	"slices"
+	"strconv"
+
+	"github.com/sensepost/gowitness/internal/islazy"
+	"github.com/sensepost/gowitness/pkg/log"
+	"github.com/sensepost/gowitness/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// reclusterBatchSize is the number of results read, or updated, at a time
+// when reclustering
+const reclusterBatchSize = 500
+
+// hammingThresholdSetting is the setting Recluster stores its threshold in
+const hammingThresholdSetting = "hamming_threshold"
+
+// HammingThreshold returns the threshold a database's perception hash
+// groups were last reclustered with, or DefaultHammingThreshold if they
+// never were.
+func HammingThreshold(db *gorm.DB) (int, error) {
+	var settings []*models.Setting
+	if err := db.Where("name = ?", hammingThresholdSetting).Limit(1).Find(&settings).Error; err != nil {
+		return 0, err
+	}
+	if len(settings) == 0 {
+		return DefaultHammingThreshold, nil
+	}
+	setting := settings[0]
+
+	threshold, err := strconv.Atoi(setting.Value)
+	if err != nil {
+		log.Warn("ignoring an invalid stored perception hash threshold", "value", setting.Value)
+		return DefaultHammingThreshold, nil
+	}
+
+	return threshold, nil
+}
+
+// LoadHammingGroups loads the perception hash groups in a database. Each
+// group is seeded with the hash of its first result, as it would have been
+// when the group was created.
+func LoadHammingGroups(db *gorm.DB, threshold int) (*islazy.HammingGroups, error) {
+	var seeds []*models.Result
+	if err := db.Model(&models.Result{}).
+		Select("perception_hash_group_id", "perception_hash").
+		Where("id in (?)", db.Model(&models.Result{}).Select("MIN(id)").
+			Where("perception_hash_group_id > 0").Group("perception_hash_group_id")).
+		Order("perception_hash_group_id").
+		Find(&seeds).Error; err != nil {
+		return nil, err
+	}
+
+	groups := islazy.NewHammingGroups(threshold)
+	for _, seed := range seeds {
+		hash, err := islazy.ParsePerceptionHash(seed.PerceptionHash)
+		if err != nil {
+			log.Debug("skipping perception hash group with an invalid hash",
+				"group", seed.PerceptionHashGroupId, "err", err)
+			continue
+		}
+		groups.Add(seed.PerceptionHashGroupId, hash)
+	}
+
+	return groups, nil
+}
+
+// Recluster recomputes the perception hash group of every result in a
+// database using threshold. Results are grouped in the order they were
+// written, the same way DbWriter groups them. The threshold is stored in
+// the database, for later writers to group with. It returns the number of
+// groups.
+func Recluster(db *gorm.DB, threshold int) (int, error) {
+	groups := islazy.NewHammingGroups(threshold)
+	members := make(map[uint][]uint)
+
+	var batch []*models.Result
+	if err := db.Model(&models.Result{}).Select("id", "perception_hash").
+		Where("perception_hash != ?", "").Order("id").
+		FindInBatches(&batch, reclusterBatchSize, func(tx *gorm.DB, _ int) error {
+			for _, result := range batch {
+				hash, err := islazy.ParsePerceptionHash(result.PerceptionHash)
+				if err != nil {
+					continue
+				}
+
+				groupID, ok, err := groups.Find(hash)
+				if err != nil {
+					log.Debug("could not compare perception hash", "id", result.ID, "err", err)
+					continue
+				}
+				if !ok {
+					groupID = uint(groups.Len() + 1)
+					groups.Add(groupID, hash)
+				}
+
+				members[groupID] = append(members[groupID], result.ID)
+			}
+			return nil
+		}).Error; err != nil {
+		return 0, err
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Result{}).Where("perception_hash_group_id != ?", 0).
+			Update("perception_hash_group_id", 0).Error; err != nil {
+			return err
+		}
+
+		for groupID, ids := range members {
+			for chunk := range slices.Chunk(ids, reclusterBatchSize) {
+				if err := tx.Model(&models.Result{}).Where("id in ?", chunk).
+					Update("perception_hash_group_id", groupID).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&models.Setting{
+			Name:  hammingThresholdSetting,
+			Value: strconv.Itoa(threshold),
+		}).Error
+	}); err != nil {
+		return 0, err
+	}
+
+	return groups.Len(), nil
+}
@@ -0,0 +1,144 @@
+package writers
+
+import (
+	"testing"
+
+	"github.com/sensepost/gowitness/internal/islazy"
+	"github.com/sensepost/gowitness/internal/testdb"
+	"github.com/sensepost/gowitness/pkg/database"
+	"github.com/sensepost/gowitness/pkg/models"
+	"gorm.io/gorm"
+)
+
+// perception hashes, and how many bits they differ from hashA by
+const (
+	hashA = "p:0000000000000000"
+	hashB = "p:0000000000000003" // 2 bits
+	hashD = "p:00000000000000ff" // 8 bits
+	hashC = "p:ffffffffffffffff" // 64 bits
+)
+
+func newTestDB(t *testing.T, results ...*models.Result) (string, *gorm.DB) {
+	t.Helper()
+
+	uri, conn := testdb.Open(t, database.Connection)
+	for _, result := range results {
+		if err := conn.Create(result).Error; err != nil {
+			t.Fatalf("could not create a test result: %v", err)
+		}
+	}
+
+	return uri, conn
+}
+
+func mustParseHash(t *testing.T, hash string) []byte {
+	t.Helper()
+
+	parsed, err := islazy.ParsePerceptionHash(hash)
+	if err != nil {
+		t.Fatalf("ParsePerceptionHash(%q) error = %v", hash, err)
+	}
+
+	return parsed
+}
+
+func TestLoadHammingGroups(t *testing.T) {
+	_, conn := newTestDB(t,
+		&models.Result{URL: "https://a", PerceptionHash: hashA, PerceptionHashGroupId: 1},
+		&models.Result{URL: "https://c", PerceptionHash: hashC, PerceptionHashGroupId: 2},
+		&models.Result{URL: "https://b", PerceptionHash: hashB, PerceptionHashGroupId: 1},
+		&models.Result{URL: "https://bogus", PerceptionHash: "bogus", PerceptionHashGroupId: 3},
+		&models.Result{URL: "https://none"},
+	)
+
+	groups, err := LoadHammingGroups(conn, 4)
+	if err != nil {
+		t.Fatalf("LoadHammingGroups() error = %v", err)
+	}
+
+	// groups with an invalid seed hash are skipped
+	if groups.Len() != 2 {
+		t.Errorf("LoadHammingGroups() loaded %d groups, want 2", groups.Len())
+	}
+
+	tests := []struct {
+		hash      string
+		wantGroup uint
+		wantOk    bool
+	}{
+		{hashA, 1, true},
+		{hashB, 1, true},
+		{hashC, 2, true},
+		{hashD, 0, false},
+	}
+	for _, tt := range tests {
+		group, ok, err := groups.Find(mustParseHash(t, tt.hash))
+		if err != nil {
+			t.Fatalf("Find(%s) error = %v", tt.hash, err)
+		}
+		if group != tt.wantGroup || ok != tt.wantOk {
+			t.Errorf("Find(%s) = %d, %v, want %d, %v", tt.hash, group, ok, tt.wantGroup, tt.wantOk)
+		}
+	}
+}
+
+func TestRecluster(t *testing.T) {
+	uri, conn := newTestDB(t,
+		&models.Result{URL: "https://a", PerceptionHash: hashA, PerceptionHashGroupId: 7},
+		&models.Result{URL: "https://d", PerceptionHash: hashD, PerceptionHashGroupId: 7},
+		&models.Result{URL: "https://b", PerceptionHash: hashB, PerceptionHashGroupId: 7},
+		&models.Result{URL: "https://c", PerceptionHash: hashC, PerceptionHashGroupId: 7},
+		&models.Result{URL: "https://none", PerceptionHashGroupId: 7},
+	)
+
+	if threshold, err := HammingThreshold(conn); err != nil || threshold != DefaultHammingThreshold {
+		t.Errorf("HammingThreshold() = %d, %v, want %d", threshold, err, DefaultHammingThreshold)
+	}
+
+	tests := []struct {
+		threshold  int
+		wantCount  int
+		wantGroups map[string]uint
+	}{
+		{10, 2, map[string]uint{"https://a": 1, "https://d": 1, "https://b": 1, "https://c": 2, "https://none": 0}},
+		{4, 3, map[string]uint{"https://a": 1, "https://d": 2, "https://b": 1, "https://c": 3, "https://none": 0}},
+	}
+	for _, tt := range tests {
+		count, err := Recluster(conn, tt.threshold)
+		if err != nil {
+			t.Fatalf("Recluster(%d) error = %v", tt.threshold, err)
+		}
+		if count != tt.wantCount {
+			t.Errorf("Recluster(%d) = %d groups, want %d", tt.threshold, count, tt.wantCount)
+		}
+
+		var results []*models.Result
+		if err := conn.Find(&results).Error; err != nil {
+			t.Fatalf("could not read results: %v", err)
+		}
+		for _, result := range results {
+			if want := tt.wantGroups[result.URL]; result.PerceptionHashGroupId != want {
+				t.Errorf("Recluster(%d) put %s in group %d, want %d",
+					tt.threshold, result.URL, result.PerceptionHashGroupId, want)
+			}
+		}
+
+		if threshold, err := HammingThreshold(conn); err != nil || threshold != tt.threshold {
+			t.Errorf("HammingThreshold() after Recluster(%d) = %d, %v", tt.threshold, threshold, err)
+		}
+	}
+
+	// later writers group with the stored threshold
+	writer, err := NewDbWriter(uri, false)
+	if err != nil {
+		t.Fatalf("NewDbWriter() error = %v", err)
+	}
+	defer writer.Close()
+
+	if writer.hammingGroups.Threshold != 4 {
+		t.Errorf("NewDbWriter() grouped with threshold %d, want 4", writer.hammingGroups.Threshold)
+	}
+	if group, err := writer.AssignGroupID(hashD); err != nil || group != 2 {
+		t.Errorf("AssignGroupID(%s) = %d, %v, want 2", hashD, group, err)
+	}
+}
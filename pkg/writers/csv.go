@@ -5,13 +5,43 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"slices"
+	"strconv"
 
 	"github.com/sensepost/gowitness/internal/islazy"
 	"github.com/sensepost/gowitness/pkg/models"
 )
 
 // fields in the main model to ignore
-var csvExludedFields = []string{"HTML"}
+var csvExludedFields = []string{"HTML", "Favicon"}
+
+// csvSummaryColumns summarise the favicon and the elements extracted from
+// a page, as the raw icon and slice fields don't fit in a column
+var csvSummaryColumns = []struct {
+	name  string
+	value func(result *models.Result) string
+}{
+	{"LinkCount", func(r *models.Result) string { return strconv.Itoa(len(r.Links)) }},
+	{"ExternalLinkCount", func(r *models.Result) string {
+		return strconv.Itoa(countFunc(r.Links, func(l models.Link) bool { return l.External }))
+	}},
+	{"FormCount", func(r *models.Result) string { return strconv.Itoa(len(r.Forms)) }},
+	{"HasPasswordForm", func(r *models.Result) string {
+		return strconv.FormatBool(slices.ContainsFunc(r.Forms, func(f models.Form) bool { return f.HasPassword }))
+	}},
+	{"HasFileUploadForm", func(r *models.Result) string {
+		return strconv.FormatBool(slices.ContainsFunc(r.Forms, func(f models.Form) bool { return f.HasFile }))
+	}},
+	{"ScriptCount", func(r *models.Result) string { return strconv.Itoa(len(r.Scripts)) }},
+	{"MetaTagCount", func(r *models.Result) string { return strconv.Itoa(len(r.MetaTags)) }},
+	{"FaviconMMH3", func(r *models.Result) string {
+		if r.Favicon.MD5 == "" {
+			return ""
+		}
+		return strconv.Itoa(int(r.Favicon.MMH3))
+	}},
+	{"FaviconMD5", func(r *models.Result) string { return r.Favicon.MD5 }},
+}
 
 // CsvWriter writes CSV files
 type CsvWriter struct {
@@ -75,9 +105,25 @@ func (cw *CsvWriter) Write(result *models.Result) error {
 		values = append(values, fmt.Sprintf("%v", val.Field(i).Interface()))
 	}
 
+	for _, column := range csvSummaryColumns {
+		values = append(values, column.value(result))
+	}
+
 	return writer.Write(values)
 }
 
+// countFunc counts the elements of a slice that f is true for
+func countFunc[T any](s []T, f func(T) bool) int {
+	count := 0
+	for _, v := range s {
+		if f(v) {
+			count++
+		}
+	}
+
+	return count
+}
+
 // headers returns the headers a CSV file should have.
 func csvHeaders() []string {
 	val := reflect.ValueOf(models.Result{})
@@ -98,5 +144,9 @@ func csvHeaders() []string {
 		fieldNames = append(fieldNames, val.Type().Field(i).Name)
 	}
 
+	for _, column := range csvSummaryColumns {
+		fieldNames = append(fieldNames, column.name)
+	}
+
 	return fieldNames
 }
@@ -0,0 +1,63 @@
+package writers
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/sensepost/gowitness/pkg/models"
+)
+
+func TestCsvWriterFavicon(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.csv")
+	cw, err := NewCsvWriter(path)
+	if err != nil {
+		t.Fatalf("NewCsvWriter() error = %v", err)
+	}
+
+	if err := cw.Write(&models.Result{
+		URL: "https://example.com",
+		Favicon: models.Favicon{
+			URL:     "https://example.com/favicon.ico",
+			Content: []byte{137, 80, 78, 71},
+			MMH3:    -1,
+			MD5:     "abc",
+		},
+	}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := cw.Write(&models.Result{URL: "https://example.org"}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("could not open the csv: %v", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("could not read the csv: %v", err)
+	}
+
+	header := records[0]
+	if slices.Contains(header, "Favicon") {
+		t.Errorf("header has the raw Favicon column: %v", header)
+	}
+
+	column := func(row []string, name string) string {
+		return row[slices.Index(header, name)]
+	}
+	if got := column(records[1], "FaviconMMH3"); got != "-1" {
+		t.Errorf("FaviconMMH3 = %q, want %q", got, "-1")
+	}
+	if got := column(records[1], "FaviconMD5"); got != "abc" {
+		t.Errorf("FaviconMD5 = %q, want %q", got, "abc")
+	}
+	if got := column(records[2], "FaviconMMH3"); got != "" {
+		t.Errorf("FaviconMMH3 without a favicon = %q, want it empty", got)
+	}
+}
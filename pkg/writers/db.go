@@ -1,23 +1,67 @@
 package writers
 
 import (
+	"context"
+	"errors"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/sensepost/gowitness/internal/islazy"
+	"github.com/sensepost/gowitness/pkg/alerts"
 	"github.com/sensepost/gowitness/pkg/database"
+	"github.com/sensepost/gowitness/pkg/events"
 	"github.com/sensepost/gowitness/pkg/log"
 	"github.com/sensepost/gowitness/pkg/models"
 	"gorm.io/gorm"
 )
 
-var hammingThreshold = 10
+// DefaultHammingThreshold is the number of bits two perception hashes
+// may differ by and still be grouped together
+const DefaultHammingThreshold = 10
+
+// alertQueueSize is how many written results may wait to be matched
+// against saved searches. alerts for results written while the queue is
+// full are dropped, so that slow webhooks never hold up writes.
+const alertQueueSize = 256
+
+// alertWorkers is how many queued results are evaluated, and have their
+// alerts sent, at a time
+const alertWorkers = 4
+
+// alertsCloseTimeout is how long Close waits for queued alerts to be sent
+// before it cancels the ones left
+const alertsCloseTimeout = 30 * time.Second
+
+// ErrWriterClosed is returned when a closed database writer is used
+var ErrWriterClosed = errors.New("database writer is closed")
 
 // DbWriter is a Database writer
 type DbWriter struct {
 	URI           string
 	conn          *gorm.DB
 	mutex         sync.Mutex
-	hammingGroups []islazy.HammingGroup
+	hammingGroups *islazy.HammingGroups
+
+	// Events is an optional hub that written results are published to
+	Events *events.Hub
+	// Update replaces the existing result when a result has an id, as
+	// rescans do, instead of adding a new one
+	Update bool
+	// Alerts limits where the alert rules of saved searches may send
+	// notifications
+	Alerts alerts.Policy
+
+	// alertQueue are ids of written results to evaluate alerts for, away
+	// from the writes, as alerts may wait on slow webhooks
+	alertQueue   chan uint
+	alertsDone   chan struct{}
+	alertsCtx    context.Context
+	alertsCancel context.CancelFunc
+
+	// closed is set, under closeMutex, once the alert queue is closed
+	closed     bool
+	closeMutex sync.Mutex
 }
 
 // NewDbWriter initialises a database writer
@@ -27,16 +71,67 @@ func NewDbWriter(uri string, debug bool) (*DbWriter, error) {
 		return nil, err
 	}
 
-	return &DbWriter{
+	// pick up the groups from earlier runs, so that similar pages land
+	// in the same group no matter which run found them. the threshold is
+	// the one the database was last reclustered with, if it was.
+	threshold, err := HammingThreshold(c)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := LoadHammingGroups(c, threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	dw := &DbWriter{
 		URI:           uri,
 		conn:          c,
 		mutex:         sync.Mutex{},
-		hammingGroups: []islazy.HammingGroup{},
-	}, nil
+		hammingGroups: groups,
+		alertQueue:    make(chan uint, alertQueueSize),
+		alertsDone:    make(chan struct{}),
+	}
+	dw.alertsCtx, dw.alertsCancel = context.WithCancel(context.Background())
+
+	var workers sync.WaitGroup
+	for range alertWorkers {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			dw.evaluateAlerts()
+		}()
+	}
+	go func() {
+		workers.Wait()
+		close(dw.alertsDone)
+	}()
+
+	return dw, nil
 }
 
 // Write results to the database
 func (dw *DbWriter) Write(result *models.Result) error {
+	dw.closeMutex.Lock()
+	defer dw.closeMutex.Unlock()
+
+	if dw.closed {
+		return ErrWriterClosed
+	}
+
+	if err := dw.write(result); err != nil {
+		return err
+	}
+
+	select {
+	case dw.alertQueue <- result.ID:
+	default:
+		log.Warn("too many results are waiting for alerts, not sending alerts for result", "url", result.URL)
+	}
+	return nil
+}
+
+// write saves a result, and publishes it
+func (dw *DbWriter) write(result *models.Result) error {
 	dw.mutex.Lock()
 	defer dw.mutex.Unlock()
 
@@ -50,7 +145,116 @@ func (dw *DbWriter) Write(result *models.Result) error {
 		log.Debug("could not get group id for perception hash", "hash", result.PerceptionHash)
 	}
 
-	return dw.conn.Create(result).Error
+	if dw.Update && result.ID != 0 {
+		if err := dw.replace(result); err != nil {
+			return err
+		}
+	} else if err := dw.conn.Create(result).Error; err != nil {
+		return err
+	}
+
+	// a missing index entry only affects search, so don't fail the write
+	if index := database.FullText(dw.conn); index != nil {
+		if err := index.Index(dw.conn, result); err != nil {
+			log.Error("could not add result to the full-text index", "err", err)
+		}
+	}
+
+	if dw.Events != nil {
+		dw.Events.Publish(events.ResultEvent, events.NewResult(result))
+	}
+
+	return nil
+}
+
+// evaluateAlerts matches queued results against saved searches, sending
+// their alerts, until the queue is closed. results left once the alerts
+// are cancelled are skipped.
+func (dw *DbWriter) evaluateAlerts() {
+	for id := range dw.alertQueue {
+		if dw.alertsCtx.Err() != nil {
+			continue
+		}
+
+		var result models.Result
+		if err := dw.conn.Model(&models.Result{}).
+			Select("id", "probed_at", "url", "response_code", "title", "filename", "failed").
+			Where("id = ?", id).Take(&result).Error; err != nil {
+			log.Error("could not get a written result to send alerts for", "id", id, "err", err)
+			continue
+		}
+
+		alerts.Evaluate(dw.alertsCtx, dw.conn, &result, dw.Events, dw.Alerts)
+	}
+}
+
+// Close waits for the alerts of written results to be sent, for up to
+// alertsCloseTimeout, cancelling the ones left after that. Writing to, or
+// closing, the writer after it is closed returns ErrWriterClosed.
+func (dw *DbWriter) Close() error {
+	dw.closeMutex.Lock()
+	if dw.closed {
+		dw.closeMutex.Unlock()
+		return ErrWriterClosed
+	}
+	dw.closed = true
+	close(dw.alertQueue)
+	dw.closeMutex.Unlock()
+
+	defer dw.alertsCancel()
+
+	select {
+	case <-dw.alertsDone:
+	case <-time.After(alertsCloseTimeout):
+		log.Warn("timed out waiting for alerts to be sent, cancelling the rest")
+		dw.alertsCancel()
+		<-dw.alertsDone
+	}
+
+	return nil
+}
+
+// replace swaps the result with the same id for a rescan of it, keeping
+// the analyst's triage and how the result was discovered
+func (dw *DbWriter) replace(result *models.Result) error {
+	return dw.conn.Transaction(func(tx *gorm.DB) error {
+		var existing models.Result
+		err := tx.Preload("Tags").Where("id = ?", result.ID).Take(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// deleted since the rescan started, so add it back
+			return tx.Create(result).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		result.Reviewed = existing.Reviewed
+		result.Notes = existing.Notes
+		for _, tag := range existing.Tags {
+			if !slices.ContainsFunc(result.Tags, func(t models.Tag) bool { return t.Value == tag.Value }) {
+				result.Tags = append(result.Tags, models.Tag{Value: tag.Value})
+			}
+		}
+
+		if result.DiscoveredFromURL == "" {
+			result.DiscoveredFromID = existing.DiscoveredFromID
+			result.DiscoveredFromURL = existing.DiscoveredFromURL
+			result.DiscoveredVia = existing.DiscoveredVia
+			result.DiscoveryDepth = existing.DiscoveryDepth
+		}
+		if result.OriginalMethod == "" {
+			result.OriginalMethod = existing.OriginalMethod
+		}
+
+		if err := database.DeleteRelated(tx, []uint{result.ID}); err != nil {
+			return err
+		}
+		if err := tx.Delete(&models.Result{}, result.ID).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(result).Error
+	})
 }
 
 // AssignGroupID assigns a PerceptionHashGroupId based on Hamming distance
@@ -61,16 +265,13 @@ func (dw *DbWriter) AssignGroupID(perceptionHashStr string) (uint, error) {
 		return 0, err
 	}
 
-	// Iterate through existing groups to find a match
-	for _, group := range dw.hammingGroups {
-		dist, err := islazy.HammingDistance(parsedHash, group.Hash)
-		if err != nil {
-			return 0, err
-		}
-
-		if dist <= hammingThreshold {
-			return group.GroupID, nil
-		}
+	// Look for an existing group to match
+	groupID, ok, err := dw.hammingGroups.Find(parsedHash)
+	if err != nil {
+		return 0, err
+	}
+	if ok {
+		return groupID, nil
 	}
 
 	// No matching group found; create a new group
@@ -84,11 +285,7 @@ func (dw *DbWriter) AssignGroupID(perceptionHashStr string) (uint, error) {
 	nextGroupID := maxGroupID + 1
 
 	// Add the new group to in-memory cache
-	newGroup := islazy.HammingGroup{
-		GroupID: nextGroupID,
-		Hash:    parsedHash,
-	}
-	dw.hammingGroups = append(dw.hammingGroups, newGroup)
+	dw.hammingGroups.Add(nextGroupID, parsedHash)
 
 	return nextGroupID, nil
 }
@@ -0,0 +1,31 @@
+package writers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sensepost/gowitness/pkg/models"
+)
+
+func TestDbWriterClose(t *testing.T) {
+	uri, _ := newTestDB(t)
+
+	dw, err := NewDbWriter(uri, false)
+	if err != nil {
+		t.Fatalf("NewDbWriter() error = %v", err)
+	}
+
+	if err := dw.Write(&models.Result{URL: "https://example.com"}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := dw.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if err := dw.Write(&models.Result{URL: "https://example.org"}); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("Write() after Close() error = %v, want %v", err, ErrWriterClosed)
+	}
+	if err := dw.Close(); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("second Close() error = %v, want %v", err, ErrWriterClosed)
+	}
+}
@@ -1,8 +1,22 @@
 package writers
 
-import "github.com/sensepost/gowitness/pkg/models"
+import (
+	"io"
+
+	"github.com/sensepost/gowitness/pkg/models"
+)
 
 // Writer is a results writer
 type Writer interface {
 	Write(*models.Result) error
 }
+
+// Close closes the writers that finish work in the background, like the
+// database writer's alerts
+func Close(writers []Writer) {
+	for _, writer := range writers {
+		if closer, ok := writer.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sensepost/gowitness/pkg/log"
+	"github.com/sensepost/gowitness/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// clusterTopValues is the number of common titles and technologies
+// returned for a cluster
+const clusterTopValues = 5
+
+type clustersResponse struct {
+	Clusters   []*cluster `json:"clusters"`
+	Page       int        `json:"page"`
+	Limit      int        `json:"limit"`
+	TotalCount int64      `json:"total_count"`
+}
+
+type cluster struct {
+	ID             uint            `json:"id"`
+	Count          int64           `json:"count"`
+	Reviewed       int64           `json:"reviewed"`
+	Representative *clusterResult  `json:"representative"`
+	Titles         []*clusterValue `json:"titles"`
+	Technologies   []*clusterValue `json:"technologies"`
+}
+
+type clusterResult struct {
+	ID           uint   `json:"id"`
+	URL          string `json:"url"`
+	ResponseCode int    `json:"response_code"`
+	Title        string `json:"title"`
+	Filename     string `json:"file_name"`
+	Screenshot   string `json:"screenshot"`
+}
+
+type clusterValue struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// ClustersHandler lists perception hash clusters
+//
+//	@Summary		Clusters
+//	@Description	Get a paginated list of perception hash clusters (groups of results with screenshots
+//	@Description	that look alike), largest first. Each cluster has its size, a representative result
+//	@Description	and its most common titles and technologies.
+//	@Tags			Results
+//	@Accept			json
+//	@Produce		json
+//	@Param			page		query		int		false	"The page to load."
+//	@Param			limit		query		int		false	"Number of clusters per page."
+//	@Param			min_size	query		int		false	"Only include clusters with at least this many results. Defaults to 2."
+//	@Param			reviewed	query		boolean	false	"Only count results that have (or have not) been reviewed."
+//	@Success		200			{object}	clustersResponse
+//	@Router			/results/clusters [get]
+func (h *ApiHandler) ClustersHandler(w http.ResponseWriter, r *http.Request) {
+	var response = &clustersResponse{
+		Clusters: []*cluster{},
+		Page:     1,
+		Limit:    24,
+	}
+
+	// pagination
+	if p, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && p > 0 {
+		response.Page = p
+	}
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		response.Limit = l
+	}
+	offset := (response.Page - 1) * response.Limit
+
+	minSize := 2
+	if m, err := strconv.Atoi(r.URL.Query().Get("min_size")); err == nil && m > 0 {
+		minSize = m
+	}
+
+	// reviewed filtering. an empty value means no filter
+	var reviewed *bool
+	if reviewedValue, err := strconv.ParseBool(r.URL.Query().Get("reviewed")); err == nil {
+		reviewed = &reviewedValue
+	}
+
+	// members returns the results to consider for clusters
+	members := func() *gorm.DB {
+		query := h.DB.Model(&models.Result{}).Where("perception_hash_group_id > 0")
+		if reviewed != nil {
+			query = query.Where("reviewed = ?", *reviewed)
+		}
+		return query
+	}
+
+	grouped := members().
+		Select("perception_hash_group_id as id, COUNT(*) as count, "+
+			"SUM(CASE WHEN reviewed THEN 1 ELSE 0 END) as reviewed, MIN(id) as representative").
+		Group("perception_hash_group_id").
+		Having("COUNT(*) >= ?", minSize)
+
+	if err := h.DB.Table("(?) as clusters", grouped).Count(&response.TotalCount).Error; err != nil {
+		log.Error("could not count clusters", "err", err)
+		http.Error(w, "Error getting clusters", http.StatusInternalServerError)
+		return
+	}
+
+	var rows []struct {
+		ID             uint
+		Count          int64
+		Reviewed       int64
+		Representative uint
+	}
+	if err := grouped.Order("count DESC, id").Limit(response.Limit).Offset(offset).
+		Scan(&rows).Error; err != nil {
+		log.Error("could not get clusters", "err", err)
+		http.Error(w, "Error getting clusters", http.StatusInternalServerError)
+		return
+	}
+
+	for _, row := range rows {
+		c := &cluster{
+			ID:             row.ID,
+			Count:          row.Count,
+			Reviewed:       row.Reviewed,
+			Representative: &clusterResult{},
+			Titles:         []*clusterValue{},
+			Technologies:   []*clusterValue{},
+		}
+
+		if err := h.DB.Model(&models.Result{}).
+			Select("id", "url", "response_code", "title", "filename", "screenshot").
+			First(c.Representative, row.Representative).Error; err != nil {
+			log.Error("could not get cluster representative", "cluster", row.ID, "err", err)
+			http.Error(w, "Error getting clusters", http.StatusInternalServerError)
+			return
+		}
+
+		if err := members().Where("perception_hash_group_id = ?", row.ID).
+			Select("title as value, COUNT(*) as count").Group("title").
+			Order("count DESC, value").Limit(clusterTopValues).
+			Scan(&c.Titles).Error; err != nil {
+			log.Error("could not get cluster titles", "cluster", row.ID, "err", err)
+			http.Error(w, "Error getting clusters", http.StatusInternalServerError)
+			return
+		}
+
+		if err := h.DB.Model(&models.Technology{}).
+			Select("value, COUNT(DISTINCT result_id) as count").
+			Where("result_id in (?)", members().Select("id").
+				Where("perception_hash_group_id = ?", row.ID)).
+			Group("value").Order("count DESC, value").Limit(clusterTopValues).
+			Scan(&c.Technologies).Error; err != nil {
+			log.Error("could not get cluster technologies", "cluster", row.ID, "err", err)
+			http.Error(w, "Error getting clusters", http.StatusInternalServerError)
+			return
+		}
+
+		response.Clusters = append(response.Clusters, c)
+	}
+
+	jsonData, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(jsonData)
+}
+
+// ClusterExportHandler exports the results in a cluster
+//
+//	@Summary		Export a cluster
+//	@Description	Download the results in a perception hash cluster, either as JSON lines (the
+//	@Description	default, in the same format as the JSON lines writer) or as a list of URLs.
+//	@Tags			Results
+//	@Produce		plain
+//	@Param			id		path		int		true	"The cluster id."
+//	@Param			format	query		string	false	"The export format, jsonl or urls."
+//	@Success		200		{string}	string
+//	@Router			/results/clusters/{id}/export [get]
+func (h *ApiHandler) ClusterExportHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 0)
+	if err != nil || id == 0 {
+		http.Error(w, "Invalid cluster id", http.StatusBadRequest)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "jsonl"
+	}
+	if format != "jsonl" && format != "urls" {
+		http.Error(w, "Unknown export format", http.StatusBadRequest)
+		return
+	}
+
+	query := h.DB.Model(&models.Result{}).Where("perception_hash_group_id = ?", id).Order("id")
+	if format == "jsonl" {
+		query = query.Preload(clause.Associations)
+	}
+
+	var results []*models.Result
+	if err := query.Find(&results).Error; err != nil {
+		log.Error("could not get cluster results", "cluster", id, "err", err)
+		http.Error(w, "Error getting cluster results", http.StatusInternalServerError)
+		return
+	}
+
+	extension := map[string]string{"jsonl": "jsonl", "urls": "txt"}[format]
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="cluster-%d.%s"`, id, extension))
+
+	for _, result := range results {
+		if format == "urls" {
+			fmt.Fprintln(w, result.URL)
+			continue
+		}
+
+		j, err := json.Marshal(result)
+		if err != nil {
+			log.Error("could not marshal result", "id", result.ID, "err", err)
+			return
+		}
+		w.Write(append(j, '\n'))
+	}
+}
@@ -2,20 +2,39 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"slices"
 
+	"github.com/sensepost/gowitness/pkg/database"
+	"github.com/sensepost/gowitness/pkg/events"
 	"github.com/sensepost/gowitness/pkg/log"
 	"github.com/sensepost/gowitness/pkg/models"
+	"github.com/sensepost/gowitness/pkg/search"
+	"gorm.io/gorm"
 )
 
 type deleteResultRequest struct {
 	ID int `json:"id"`
 }
 
+type bulkDeleteRequest struct {
+	IDs    []uint `json:"ids"`
+	Query  string `json:"query"`
+	DryRun bool   `json:"dry_run"`
+}
+
+type bulkDeleteResponse struct {
+	DryRun bool     `json:"dry_run"`
+	IDs    []uint   `json:"ids"`
+	Files  []string `json:"files"`
+}
+
 // DeleteResultHandler deletes results from the database
 //
 //	@Summary		Delete a result
-//	@Description	Deletes a result, by id, and all of its associated data from the database.
+//	@Description	Deletes a result, by id, and all of its associated data from the database. The
+//	@Description	screenshot file is removed too, unless another result uses it.
 //	@Tags			Results
 //	@Accept			json
 //	@Produce		json
@@ -32,11 +51,14 @@ func (h *ApiHandler) DeleteResultHandler(w http.ResponseWriter, r *http.Request)
 
 	log.Info("deleting id", "id", request.ID)
 
-	if err := h.DB.Delete(&models.Result{}, request.ID).Error; err != nil {
+	if _, err := database.DeleteResults(h.DB, h.ScreenshotPath, []uint{uint(request.ID)}); err != nil {
 		log.Error("failed to delete result", "err", err)
+		http.Error(w, "Error deleting result", http.StatusInternalServerError)
 		return
 	}
 
+	h.Events.Publish(events.DeleteEvent, &events.Delete{IDs: []uint{uint(request.ID)}})
+
 	response := `ok`
 	jsonData, err := json.Marshal(response)
 	if err != nil {
@@ -46,3 +68,82 @@ func (h *ApiHandler) DeleteResultHandler(w http.ResponseWriter, r *http.Request)
 
 	w.Write(jsonData)
 }
+
+// BulkDeleteHandler deletes many results at once
+//
+//	@Summary		Delete many results
+//	@Description	Deletes results by id, by search query, or both (in which case only the listed ids
+//	@Description	that match the query are deleted), along with their associated data and screenshot
+//	@Description	files. With `dry_run` set nothing is deleted, and the response lists what would be.
+//	@Tags			Results
+//	@Accept			json
+//	@Produce		json
+//	@Param			query	body		bulkDeleteRequest	true	"The results to delete"
+//	@Success		200		{object}	bulkDeleteResponse
+//	@Router			/results/delete/bulk [post]
+func (h *ApiHandler) BulkDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	var request bulkDeleteRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		log.Error("failed to read json request", "err", err)
+		http.Error(w, "Error reading JSON request", http.StatusInternalServerError)
+		return
+	}
+
+	query, err := search.Parse(request.Query)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid search query: %s", err), http.StatusBadRequest)
+		return
+	}
+	if query == nil && len(request.IDs) == 0 {
+		http.Error(w, "ids or a search query are required", http.StatusBadRequest)
+		return
+	}
+
+	// a session, so that the selection can be narrowed to each batch of
+	// the listed ids in turn
+	selection := search.Apply(h.DB.Model(&models.Result{}), query).Session(&gorm.Session{})
+
+	var response = bulkDeleteResponse{DryRun: request.DryRun, IDs: []uint{}, Files: []string{}}
+	if len(request.IDs) == 0 {
+		if err := selection.Pluck("id", &response.IDs).Error; err != nil {
+			log.Error("could not select results to delete", "err", err)
+			http.Error(w, "Error selecting results", http.StatusInternalServerError)
+			return
+		}
+	}
+	for batch := range slices.Chunk(request.IDs, database.DeleteBatchSize) {
+		var ids []uint
+		if err := selection.Where("id in ?", batch).Pluck("id", &ids).Error; err != nil {
+			log.Error("could not select results to delete", "err", err)
+			http.Error(w, "Error selecting results", http.StatusInternalServerError)
+			return
+		}
+		response.IDs = append(response.IDs, ids...)
+	}
+
+	var files []string
+	if request.DryRun {
+		files, err = database.Artifacts(h.DB, h.ScreenshotPath, response.IDs)
+	} else {
+		log.Info("deleting results", "count", len(response.IDs))
+		files, err = database.DeleteResults(h.DB, h.ScreenshotPath, response.IDs)
+	}
+	if err != nil {
+		log.Error("failed to delete results", "err", err)
+		http.Error(w, "Error deleting results", http.StatusInternalServerError)
+		return
+	}
+	response.Files = append(response.Files, files...)
+
+	if !request.DryRun && len(response.IDs) > 0 {
+		h.Events.Publish(events.DeleteEvent, &events.Delete{IDs: response.IDs})
+	}
+
+	jsonData, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, "Error creating JSON response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(jsonData)
+}
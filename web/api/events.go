@@ -0,0 +1,233 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sensepost/gowitness/pkg/alerts"
+	"github.com/sensepost/gowitness/pkg/events"
+	"github.com/sensepost/gowitness/pkg/log"
+	"github.com/sensepost/gowitness/pkg/models"
+)
+
+// keepAliveInterval is how often a comment is sent to idle event streams
+const keepAliveInterval = 15 * time.Second
+
+// EventsHandler streams live events using server-sent events
+//
+//	@Summary		Live events
+//	@Description	Streams newly written results, job progress and deletions as server-sent events.
+//	@Tags			Results
+//	@Produce		text/event-stream
+//	@Success		200	{object}	events.Event
+//	@Router			/events [get]
+func (h *ApiHandler) EventsHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	stream, unsubscribe := h.Events.Subscribe()
+	defer unsubscribe()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case event, ok := <-stream:
+			if !ok {
+				return
+			}
+
+			jsonData, err := json.Marshal(event.Data)
+			if err != nil {
+				log.Error("failed to marshal event", "type", event.Type, "err", err)
+				continue
+			}
+
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, jsonData)
+			flusher.Flush()
+		}
+	}
+}
+
+// publishAlerts publishes the events alerts for a result written by another
+// process. That process has no access to the hub, but will have sent any
+// webhook and file alerts itself.
+func (h *ApiHandler) publishAlerts(result *models.Result) {
+	matched, err := alerts.Match(h.DB, result)
+	if err != nil {
+		log.Error("could not match result against saved searches", "err", err)
+		return
+	}
+
+	for _, alert := range matched {
+		if alert.Rule.Type != alerts.EventsAlert {
+			continue
+		}
+		// events alerts are only published, so there is nothing to cancel
+		if err := alerts.Notify(context.Background(), alert, result, h.Events, h.Alerts); err != nil {
+			log.Error("could not send alert", "search", alert.Search.Name, "err", err)
+		}
+	}
+}
+
+// watchPageSize is the number of results WatchResults reads at a time
+const watchPageSize = 500
+
+// watchColumns are the result columns needed to publish a result event
+var watchColumns = []string{"id", "probed_at", "url", "response_code", "title", "filename", "failed"}
+
+// resultWatch is the state of WatchResults
+type resultWatch struct {
+	// lastID is the highest result id polled. results written by
+	// in-process writers don't move it, as other processes may still
+	// write lower ids.
+	lastID uint
+	// lastProbedAt is the latest probe time polled. a result replaced in
+	// place keeps its id, but is probed after it.
+	lastProbedAt time.Time
+	// published are the results in-process writers already published,
+	// with the probe time they were published for
+	published map[uint]time.Time
+}
+
+// WatchResults polls the database for results written by other processes
+// (i.e., a scan writing to the same database) and publishes them as events,
+// until ctx is done. Results replaced in place are published again. Results
+// already published on the hub are not published again.
+func (h *ApiHandler) WatchResults(ctx context.Context, interval time.Duration) {
+	watch := &resultWatch{published: make(map[uint]time.Time)}
+	if err := h.DB.Model(&models.Result{}).
+		Select("COALESCE(MAX(id), 0)").Scan(&watch.lastID).Error; err != nil {
+		log.Error("could not determine the latest result id to watch from", "err", err)
+		return
+	}
+
+	var latest []*models.Result
+	if err := h.DB.Model(&models.Result{}).Select("probed_at").
+		Order("probed_at DESC").Limit(1).Find(&latest).Error; err != nil {
+		log.Error("could not determine the latest probe time to watch from", "err", err)
+		return
+	}
+	if len(latest) > 0 {
+		watch.lastProbedAt = latest[0].ProbedAt
+	}
+
+	stream, unsubscribe := h.Events.Subscribe()
+	defer unsubscribe()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-stream:
+			if !ok {
+				return
+			}
+
+			if result, ok := event.Data.(*events.Result); ok && event.Type == events.ResultEvent {
+				watch.published[result.ID] = result.ProbedAt
+			}
+		case <-ticker.C:
+			if err := h.pollResults(ctx, watch); err != nil {
+				log.Error("could not poll for new results", "err", err)
+			}
+		}
+	}
+}
+
+// pollResults publishes the results replaced, or written, since the last
+// poll, a page at a time
+func (h *ApiHandler) pollResults(ctx context.Context, watch *resultWatch) error {
+	// replaced results have an id that was already polled, and are paged
+	// by probe time and then id. this goes first, as new results move the
+	// probe time on.
+	condition, args := "probed_at > ?", []interface{}{watch.lastProbedAt}
+	for ctx.Err() == nil {
+		var results []*models.Result
+		if err := h.DB.Model(&models.Result{}).Select(watchColumns).
+			Where("id <= ?", watch.lastID).Where(condition, args...).
+			Order("probed_at").Order("id").Limit(watchPageSize).
+			Find(&results).Error; err != nil {
+			return err
+		}
+
+		for _, result := range results {
+			h.publishPolled(watch, result)
+		}
+		if len(results) < watchPageSize {
+			break
+		}
+
+		last := results[len(results)-1]
+		condition = "probed_at > ? OR (probed_at = ? AND id > ?)"
+		args = []interface{}{last.ProbedAt, last.ProbedAt, last.ID}
+	}
+
+	// new results
+	for ctx.Err() == nil {
+		var results []*models.Result
+		if err := h.DB.Model(&models.Result{}).Select(watchColumns).
+			Where("id > ?", watch.lastID).Order("id").Limit(watchPageSize).
+			Find(&results).Error; err != nil {
+			return err
+		}
+
+		for _, result := range results {
+			watch.lastID = max(watch.lastID, result.ID)
+			h.publishPolled(watch, result)
+		}
+		if len(results) < watchPageSize {
+			break
+		}
+	}
+
+	// what in-process writers published, and was not polled, was deleted
+	for id, probedAt := range watch.published {
+		if id <= watch.lastID && !probedAt.After(watch.lastProbedAt) {
+			delete(watch.published, id)
+		}
+	}
+
+	return nil
+}
+
+// publishPolled publishes a polled result, unless an in-process writer
+// already did
+func (h *ApiHandler) publishPolled(watch *resultWatch, result *models.Result) {
+	if result.ProbedAt.After(watch.lastProbedAt) {
+		watch.lastProbedAt = result.ProbedAt
+	}
+
+	// databases store times at different precisions, so only roughly
+	// compare them
+	if probedAt, ok := watch.published[result.ID]; ok {
+		delete(watch.published, result.ID)
+		if diff := result.ProbedAt.Sub(probedAt); diff > -time.Second && diff < time.Second {
+			return
+		}
+	}
+
+	h.Events.Publish(events.ResultEvent, events.NewResult(result))
+	h.publishAlerts(result)
+}
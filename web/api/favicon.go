@@ -0,0 +1,55 @@
+package api
+
+import (
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sensepost/gowitness/pkg/log"
+	"github.com/sensepost/gowitness/pkg/models"
+)
+
+// FaviconHandler serves a favicon by its hash
+//
+//	@Summary		Favicon
+//	@Description	Get a favicon's image, by its MD5 hash. Favicons are served separately from
+//	@Description	results, so that the many results sharing an icon load it once.
+//	@Tags			Results
+//	@Produce		image/x-icon
+//	@Param			md5	path		string	true	"The favicon's MD5 hash."
+//	@Success		200	{file}		binary
+//	@Router			/results/favicons/{md5} [get]
+func (h *ApiHandler) FaviconHandler(w http.ResponseWriter, r *http.Request) {
+	hash := strings.ToLower(chi.URLParam(r, "md5"))
+	if _, err := hex.DecodeString(hash); err != nil || len(hash) != 32 {
+		http.Error(w, "Invalid favicon hash", http.StatusBadRequest)
+		return
+	}
+
+	var favicons []*models.Favicon
+	if err := h.DB.Model(&models.Favicon{}).Select("mime_type", "content").
+		Where("md5 = ?", hash).Limit(1).Find(&favicons).Error; err != nil {
+		log.Error("could not get favicon", "md5", hash, "err", err)
+		http.Error(w, "Error getting favicon", http.StatusInternalServerError)
+		return
+	}
+	if len(favicons) == 0 {
+		http.Error(w, "Favicon not found", http.StatusNotFound)
+		return
+	}
+
+	// the mime type comes from the scanned site, so only trust image types,
+	// and keep anything active in an svg from running
+	contentType := favicons[0].MIMEType
+	if !strings.HasPrefix(contentType, "image/") {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; sandbox")
+	// the content of a hash never changes
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+
+	w.Write(favicons[0].Content)
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/sensepost/gowitness/pkg/log"
 	"github.com/sensepost/gowitness/pkg/models"
+	"gorm.io/gorm"
 )
 
 type galleryResponse struct {
@@ -19,15 +20,30 @@ type galleryResponse struct {
 }
 
 type galleryContent struct {
-	ID           uint      `json:"id"`
-	ProbedAt     time.Time `json:"probed_at"`
-	URL          string    `json:"url"`
-	ResponseCode int       `json:"response_code"`
-	Title        string    `json:"title"`
-	Filename     string    `json:"file_name"`
-	Screenshot   string    `json:"screenshot"`
-	Failed       bool      `json:"failed"`
-	Technologies []string  `json:"technologies"`
+	ID           uint            `json:"id"`
+	ProbedAt     time.Time       `json:"probed_at"`
+	URL          string          `json:"url"`
+	ResponseCode int             `json:"response_code"`
+	Title        string          `json:"title"`
+	Filename     string          `json:"file_name"`
+	Screenshot   string          `json:"screenshot"`
+	Failed       bool            `json:"failed"`
+	Reviewed     bool            `json:"reviewed"`
+	Blank        bool            `json:"blank"`
+	ErrorPage    bool            `json:"error_page"`
+	Soft404      bool            `json:"soft404"`
+	Technologies []string        `json:"technologies"`
+	Tags         []string        `json:"tags"`
+	Categories   []string        `json:"categories"`
+	Favicon      *galleryFavicon `json:"favicon,omitempty"`
+}
+
+// galleryFavicon identifies a result's favicon. The image itself is served
+// by FaviconHandler, keyed by MD5.
+type galleryFavicon struct {
+	MMH3     int32  `json:"mmh3"`
+	MD5      string `json:"md5"`
+	MIMEType string `json:"mime_type"`
 }
 
 // GalleryHandler gets a paginated gallery
@@ -42,6 +58,14 @@ type galleryContent struct {
 //	@Param			technologies	query		string	false	"A comma seperated list of technologies to filter by."
 //	@Param			status			query		string	false	"A comma seperated list of HTTP status codes to filter by."
 //	@Param			perception		query		boolean	false	"Order the results by perception hash."
+//	@Param			favicon			query		boolean	false	"Order the results by favicon hash, grouping sites with the same favicon."
+//	@Param			tags			query		string	false	"A comma seperated list of tags to filter by."
+//	@Param			categories		query		string	false	"A comma seperated list of page categories to filter by."
+//	@Param			reviewed		query		boolean	false	"Only include results that have (or have not) been reviewed."
+//	@Param			blank			query		boolean	false	"Only include results that have (or do not have) a blank screenshot."
+//	@Param			error_page		query		boolean	false	"Only include results that are (or are not) browser error pages."
+//	@Param			soft404			query		boolean	false	"Only include results that are (or are not) soft 404s."
+//	@Param			cluster			query		int		false	"Only include results in this perception hash cluster."
 //	@Param			failed			query		boolean	false	"Include failed screenshots in the results."
 //	@Success		200				{object}	galleryResponse
 //	@Router			/results/gallery [get]
@@ -70,6 +94,12 @@ func (h *ApiHandler) GalleryHandler(w http.ResponseWriter, r *http.Request) {
 		perceptionSort = false
 	}
 
+	// favicon sorting
+	faviconSort, err := strconv.ParseBool(r.URL.Query().Get("favicon"))
+	if err != nil {
+		faviconSort = false
+	}
+
 	// status code filtering
 	var statusCodes []int
 	statusFilterValue := r.URL.Query().Get("status")
@@ -91,6 +121,39 @@ func (h *ApiHandler) GalleryHandler(w http.ResponseWriter, r *http.Request) {
 		technologies = append(technologies, strings.Split(technologyFilterValue, ",")...)
 	}
 
+	// tag filtering
+	var tags []string
+	tagFilterValue := r.URL.Query().Get("tags")
+	if tagFilterValue != "" {
+		tags = append(tags, strings.Split(tagFilterValue, ",")...)
+	}
+
+	// category filtering
+	var categories []string
+	if categoryFilterValue := r.URL.Query().Get("categories"); categoryFilterValue != "" {
+		categories = append(categories, strings.Split(categoryFilterValue, ",")...)
+	}
+
+	// reviewed filtering. an empty value means no filter
+	var reviewed *bool
+	if reviewedValue, err := strconv.ParseBool(r.URL.Query().Get("reviewed")); err == nil {
+		reviewed = &reviewedValue
+	}
+
+	// page check filtering, like reviewed. an empty value means no filter
+	pageChecks := make(map[string]bool)
+	for _, column := range []string{"blank", "error_page", "soft404"} {
+		if value, err := strconv.ParseBool(r.URL.Query().Get(column)); err == nil {
+			pageChecks[column] = value
+		}
+	}
+
+	// cluster filtering
+	var cluster uint64
+	if clusterValue := r.URL.Query().Get("cluster"); clusterValue != "" {
+		cluster, _ = strconv.ParseUint(clusterValue, 10, 0)
+	}
+
 	// failed result filtering
 	var showFailed bool
 	showFailed, err = strconv.ParseBool(r.URL.Query().Get("failed"))
@@ -101,12 +164,21 @@ func (h *ApiHandler) GalleryHandler(w http.ResponseWriter, r *http.Request) {
 	// query the db
 	var queryResults []*models.Result
 	query := h.DB.Model(&models.Result{}).Limit(results.Limit).
-		Offset(offset).Preload("Technologies")
+		Offset(offset).Preload("Technologies").Preload("Tags").Preload("Categories").
+		Preload("Favicon", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "result_id", "mmh3", "md5", "mime_type")
+		})
 
 	if perceptionSort {
 		query.Order("perception_hash_group_id DESC")
 	}
 
+	if faviconSort {
+		// sites without a favicon sort last
+		query.Order("(SELECT COUNT(*) FROM favicons WHERE favicons.result_id = results.id) DESC").
+			Order("(SELECT MIN(mmh3) FROM favicons WHERE favicons.result_id = results.id)")
+	}
+
 	if len(statusCodes) > 0 {
 		query.Where("response_code IN ?", statusCodes)
 	}
@@ -117,6 +189,30 @@ func (h *ApiHandler) GalleryHandler(w http.ResponseWriter, r *http.Request) {
 			Where("value IN (?)", technologies))
 	}
 
+	if len(tags) > 0 {
+		query.Where("id in (?)", h.DB.Model(&models.Tag{}).
+			Select("result_id").Distinct("result_id").
+			Where("value IN (?)", tags))
+	}
+
+	if len(categories) > 0 {
+		query.Where("id in (?)", h.DB.Model(&models.Category{}).
+			Select("result_id").Distinct("result_id").
+			Where("value IN (?)", categories))
+	}
+
+	if reviewed != nil {
+		query.Where("reviewed = ?", *reviewed)
+	}
+
+	for column, value := range pageChecks {
+		query.Where(column+" = ?", value)
+	}
+
+	if cluster > 0 {
+		query.Where("perception_hash_group_id = ?", cluster)
+	}
+
 	if !showFailed {
 		query.Where("failed = ?", showFailed)
 	}
@@ -134,6 +230,25 @@ func (h *ApiHandler) GalleryHandler(w http.ResponseWriter, r *http.Request) {
 			technologies = append(technologies, tech.Value)
 		}
 
+		var tags []string
+		for _, tag := range result.Tags {
+			tags = append(tags, tag.Value)
+		}
+
+		var categories []string
+		for _, category := range result.Categories {
+			categories = append(categories, category.Value)
+		}
+
+		var favicon *galleryFavicon
+		if result.Favicon.MD5 != "" {
+			favicon = &galleryFavicon{
+				MMH3:     result.Favicon.MMH3,
+				MD5:      result.Favicon.MD5,
+				MIMEType: result.Favicon.MIMEType,
+			}
+		}
+
 		// Append the processed data to the response
 		results.Results = append(results.Results, &galleryContent{
 			ID:           result.ID,
@@ -144,7 +259,14 @@ func (h *ApiHandler) GalleryHandler(w http.ResponseWriter, r *http.Request) {
 			Filename:     result.Filename,
 			Screenshot:   result.Screenshot,
 			Failed:       result.Failed,
+			Reviewed:     result.Reviewed,
+			Blank:        result.Blank,
+			ErrorPage:    result.ErrorPage,
+			Soft404:      result.Soft404,
 			Technologies: technologies,
+			Tags:         tags,
+			Categories:   categories,
+			Favicon:      favicon,
 		})
 	}
 
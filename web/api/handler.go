@@ -2,7 +2,9 @@ package api
 
 import (
 	wappalyzer "github.com/projectdiscovery/wappalyzergo"
+	"github.com/sensepost/gowitness/pkg/alerts"
 	"github.com/sensepost/gowitness/pkg/database"
+	"github.com/sensepost/gowitness/pkg/events"
 	"gorm.io/gorm"
 )
 
@@ -12,6 +14,9 @@ type ApiHandler struct {
 	ScreenshotPath string
 	DB             *gorm.DB
 	Wappalyzer     *wappalyzer.Wappalyze
+	Events         *events.Hub
+	// Alerts limits where alert rules may send notifications
+	Alerts alerts.Policy
 }
 
 // NewApiHandler returns a new ApiHandler
@@ -30,5 +35,6 @@ func NewApiHandler(uri string, screenshotPath string) (*ApiHandler, error) {
 		ScreenshotPath: screenshotPath,
 		DB:             conn,
 		Wappalyzer:     wap,
+		Events:         events.NewHub(),
 	}, nil
 }
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+
+	// image formats that may be uploaded
+	_ "image/jpeg"
+	_ "image/png"
+
+	"github.com/sensepost/gowitness/pkg/imagehash"
+	"github.com/sensepost/gowitness/pkg/log"
+)
+
+const (
+	// maxImageSize is the largest image that may be uploaded to be hashed
+	maxImageSize = 20 << 20
+	// maxImagePixels is the most pixels an uploaded image may decode to,
+	// as a small compressed file can describe a huge image. It fits a
+	// full page screenshot of 1920x20000.
+	maxImagePixels = 40_000_000
+)
+
+type imageHashResponse struct {
+	Hashes map[string]string `json:"hashes"`
+}
+
+// ImageHashHandler hashes an uploaded image
+//
+//	@Summary		Hash an image
+//	@Description	Calculates the perceptual hashes of an uploaded PNG or JPEG image, keyed by algorithm.
+//	@Description	Use a hash in a search query to find similar screenshots, e.g. similar:dhash:<hash>~8.
+//	@Tags			Search
+//	@Accept			mpfd
+//	@Produce		json
+//	@Param			image	formData	file	true	"The image to hash"
+//	@Success		200		{object}	imageHashResponse
+//	@Router			/imagehash [post]
+func (h *ApiHandler) ImageHashHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImageSize)
+
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		http.Error(w, "An image is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	config, _, err := image.DecodeConfig(file)
+	if err != nil {
+		http.Error(w, "Could not decode the image", http.StatusBadRequest)
+		return
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxImagePixels {
+		http.Error(w, fmt.Sprintf("Images may be at most %d pixels", maxImagePixels), http.StatusBadRequest)
+		return
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		log.Error("could not rewind uploaded image", "err", err)
+		http.Error(w, "Error reading the image", http.StatusInternalServerError)
+		return
+	}
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		http.Error(w, "Could not decode the image", http.StatusBadRequest)
+		return
+	}
+
+	hashes, err := imagehash.Hashes(img)
+	if err != nil {
+		log.Error("could not hash image", "err", err)
+		http.Error(w, "Error hashing image", http.StatusInternalServerError)
+		return
+	}
+
+	jsonData, err := json.Marshal(&imageHashResponse{Hashes: hashes})
+	if err != nil {
+		http.Error(w, "Error creating JSON response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(jsonData)
+}
@@ -4,11 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"slices"
-	"strings"
+	"sort"
 
 	"github.com/sensepost/gowitness/pkg/log"
 	"github.com/sensepost/gowitness/pkg/models"
+	"github.com/sensepost/gowitness/pkg/search"
 )
 
 type searchRequest struct {
@@ -29,21 +29,25 @@ type searchResult struct {
 	FailedReason   string   `json:"failed_reason"`
 	Filename       string   `json:"file_name"`
 	Screenshot     string   `json:"screenshot"`
+	Categories     []string `json:"categories"`
 	MatchedFields  []string `json:"matched_fields"`
+	Rank           float64  `json:"rank,omitempty"`
+	Snippet        string   `json:"snippet,omitempty"`
 }
 
-// searchOperators are the operators we support. everything else is
-// "free text"
-var searchOperators = []string{"title", "body", "tech", "header", "p"}
-
 // SearchHandler handles search
 //
 //	@Summary		Search for results
-//	@Description	Searches for results based on free form text, or operators.
+//	@Description	Searches for results using the gowitness query language. Free text matches the URL,
+//	@Description	final URL and title. Field terms use `field:value` (contains), `field=value` (exact) or,
+//	@Description	for numbers and dates, `field>value`, `>=`, `<` and `<=`. Terms may be combined with
+//	@Description	AND, OR, NOT (or a leading `-`) and parentheses; adjacent terms are AND'd.
+//	@Description	`body:` and `content:` use the full-text index, and results are then ordered by rank
+//	@Description	with a highlighted snippet.
 //	@Tags			Results
 //	@Accept			json
 //	@Produce		json
-//	@Param			query	body		searchRequest	true	"The search term to search for. Supports search operators: `title:`, `tech:`, `header:`, `body:`, `p:`"
+//	@Param			query	body		searchRequest	true	"The search query. Supported fields: `title`, `body`, `content`, `url`, `final_url`, `protocol`, `reason`, `tech`, `header`, `tag`, `category`, `cookie`, `console`, `link`, `form`, `input`, `script`, `meta`, `blocked`, `cert`, `ip`, `vhost`, `p`, `favicon`, `similar`, `code`, `size`, `id`, `probed_at`, `is`"
 //	@Success		200		{object}	searchResult
 //	@Router			/search [post]
 func (h *ApiHandler) SearchHandler(w http.ResponseWriter, r *http.Request) {
@@ -54,99 +58,33 @@ func (h *ApiHandler) SearchHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	parsed, freeText := parseSearchQuery(request.Query)
-	var searchResults []searchResult
-	resultIDs := make(map[uint]bool)
-
-	// iterate over parsed search operators
-	for key, value := range parsed {
-		lowerValue := fmt.Sprintf("%%%s%%", value)
-
-		switch key {
-		case "title":
-			var titleResults []models.Result
-			if err := h.DB.Model(&models.Result{}).Where("LOWER(title) LIKE ?", lowerValue).
-				Find(&titleResults).Error; err != nil {
-
-				log.Error("failed to get title results", "err", err)
-				return
-			}
-
-			searchResults = appendResults(searchResults, resultIDs, titleResults, key)
-		case "tech":
-			var techResults []models.Result
-			if err := h.DB.Model(&models.Result{}).
-				Where("id in (?)", h.DB.Model(&models.Technology{}).
-					Select("result_id").Distinct("result_id").
-					Where("value LIKE ?", lowerValue)).
-				Find(&techResults).Error; err != nil {
-
-				log.Error("failed to get tech results", "err", err)
-				return
-			}
-
-			searchResults = appendResults(searchResults, resultIDs, techResults, key)
-
-		case "body":
-			var bodyResults []models.Result
-			if err := h.DB.Model(&models.Result{}).
-				Where("LOWER(html) LIKE ?", lowerValue).Find(&bodyResults).Error; err != nil {
-				log.Error("failed to get html results", "err", err)
-				return
-			}
-			searchResults = appendResults(searchResults, resultIDs, bodyResults, key)
-
-		case "header":
-			var headerResults []models.Result
-			if err := h.DB.Model(&models.Result{}).
-				Where("id in (?)", h.DB.Model(&models.Header{}).
-					Select("result_id").Distinct("result_id").
-					Where("value LIKE ?", lowerValue)).
-				Find(&headerResults).Error; err != nil {
-
-				log.Error("failed to get tech results", "err", err)
-				return
-			}
-
-			searchResults = appendResults(searchResults, resultIDs, headerResults, key)
-		case "p":
-			var perceptionHashResults []models.Result
-			if err := h.DB.Model(&models.Result{}).
-				Where("perception_hash_group_id in (?)", h.DB.Model(&models.Result{}).
-					Select("perception_hash_group_id").Distinct("perception_hash_group_id").
-					Where(
-						"perception_hash = ?",
-						// p: was used as the operatator trigger, but we need it
-						// back to resolve the group_id.
-						fmt.Sprintf("p:%s", value),
-					)).
-				Find(&perceptionHashResults).Error; err != nil {
-
-				log.Error("failed to get perception hash results", "err", err)
-				return
-			}
-
-			searchResults = appendResults(searchResults, resultIDs, perceptionHashResults, key)
-		}
-
+	query, err := search.Parse(request.Query)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid search query: %s", err), http.StatusBadRequest)
+		return
 	}
 
-	// process any freetext if there is
-	if freeText != "" {
-		lowerFreeText := fmt.Sprintf("%%%s%%", freeText)
-		var freeTextResults []models.Result
-
-		if err := h.DB.Model(&models.Result{}).
-			Where("LOWER(url) LIKE ?", lowerFreeText).
-			Or("LOWER(final_url) LIKE ?", lowerFreeText).
-			Or("LOWER(title) LIKE ?", lowerFreeText).
-			Find(&freeTextResults).Error; err != nil {
+	var searchResults = []searchResult{}
+	if query != nil {
+		var results []models.Result
+		if err := search.Apply(h.DB.Model(&models.Result{}), query).
+			Preload("Categories").Find(&results).Error; err != nil {
+			log.Error("failed to get search results", "err", err)
+			http.Error(w, "Error searching results", http.StatusInternalServerError)
+			return
+		}
 
-			log.Error("failed to get freetext results", "err", err)
+		if searchResults, err = h.matchedFields(query, results); err != nil {
+			log.Error("failed to get matched fields", "err", err)
+			http.Error(w, "Error searching results", http.StatusInternalServerError)
 			return
 		}
 
-		searchResults = appendResults(searchResults, resultIDs, freeTextResults, "text")
+		if err := h.rankResults(query, searchResults); err != nil {
+			log.Error("failed to rank search results", "err", err)
+			http.Error(w, "Error searching results", http.StatusInternalServerError)
+			return
+		}
 	}
 
 	jsonData, err := json.Marshal(searchResults)
@@ -158,112 +96,82 @@ func (h *ApiHandler) SearchHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonData)
 }
 
-// parseSearchQuery parses a search query string into key-value pairs for known operators
-// and captures any remaining free-form text.
-func parseSearchQuery(query string) (map[string]string, string) {
-	// Operators that we know of and that will be parsed
-
-	result := make(map[string]string)
-
-	var freeText string
-	var currentKey string
-	var currentValue []string
-
-	parts := strings.Fields(query)
-
-	for i := 0; i < len(parts); i++ {
-		part := parts[i]
+// matchedFields converts results to searchResults, recording which of the
+// terms in the query each result matched
+func (h *ApiHandler) matchedFields(query search.Node, results []models.Result) ([]searchResult, error) {
+	ids := make([]uint, 0, len(results))
+	for _, res := range results {
+		ids = append(ids, res.ID)
+	}
 
-		// Check if the part contains an operator (e.g., title: or tech:)
-		if index := strings.Index(part, ":"); index != -1 {
-			operator := part[:index]
-			if slices.Contains(searchOperators, operator) {
-				// If we are processing an operator, finalize the previous key-value pair
-				if currentKey != "" {
-					result[currentKey] = strings.Join(currentValue, " ")
-					currentValue = nil
-				}
-				// Set the current key to the new operator
-				currentKey = operator
+	matched, err := search.MatchedTerms(h.DB, query, ids)
+	if err != nil {
+		return nil, err
+	}
 
-				// Handle the value right after the colon
-				remainingPart := part[index+1:]
-				// quoted value?
-				if strings.HasPrefix(remainingPart, `"`) {
-					// Quoted value (with spaces)
-					remainingPart = strings.Trim(remainingPart, `"`)
-					currentValue = append(currentValue, remainingPart)
+	searchResults := make([]searchResult, 0, len(results))
+	for _, res := range results {
+		categories := []string{}
+		for _, category := range res.Categories {
+			categories = append(categories, category.Value)
+		}
 
-					// Continue appending parts until the closing quote
-					for i+1 < len(parts) && !strings.HasSuffix(parts[i+1], `"`) {
-						i++
-						currentValue = append(currentValue, parts[i])
-					}
-					if i+1 < len(parts) && strings.HasSuffix(parts[i+1], `"`) {
-						i++
-						closingPart := strings.Trim(parts[i], `"`)
-						currentValue = append(currentValue, closingPart)
-					}
-				} else if remainingPart != "" {
-					// Unquoted single word after colon
-					currentValue = append(currentValue, remainingPart)
-				} else if i+1 < len(parts) && !strings.HasPrefix(parts[i+1], `"`) {
-					// Unquoted value in the next part
-					i++
-					currentValue = append(currentValue, parts[i])
-				}
-				continue
+		fields := []string{}
+		for _, term := range matched[res.ID] {
+			field := term.Field
+			if field == "" {
+				field = "text"
 			}
+			fields = appendUnique(fields, field)
 		}
 
-		// Add remaining parts as free text
-		freeText += part + " "
-	}
-
-	// If we have an unprocessed key-value pair, store it
-	if currentKey != "" {
-		result[currentKey] = strings.Join(currentValue, " ")
+		searchResults = append(searchResults, searchResult{
+			ID:             res.ID,
+			URL:            res.URL,
+			FinalURL:       res.FinalURL,
+			ResponseCode:   res.ResponseCode,
+			ResponseReason: res.ResponseReason,
+			Protocol:       res.Protocol,
+			ContentLength:  res.ContentLength,
+			Title:          res.Title,
+			Failed:         res.Failed,
+			FailedReason:   res.FailedReason,
+			Filename:       res.Filename,
+			Screenshot:     res.Screenshot,
+			Categories:     categories,
+			MatchedFields:  fields,
+		})
 	}
 
-	// Trim any excess spaces from freeText
-	freeText = strings.TrimSpace(freeText)
-
-	return result, freeText
+	return searchResults, nil
 }
 
-// appendResults adds results to searchResults, ensuring unique results are added,
-// and also tracks which field caused the match
-func appendResults(searchResults []searchResult, resultIDs map[uint]bool, newResults []models.Result, matchedField string) []searchResult {
-	for _, res := range newResults {
-		if resultIDs[res.ID] {
-			for i := range searchResults {
-				if searchResults[i].ID == res.ID {
-					searchResults[i].MatchedFields = appendUnique(searchResults[i].MatchedFields, matchedField)
-					break
-				}
-			}
-		} else {
-			searchResults = append(searchResults, searchResult{
-				ID:             res.ID,
-				URL:            res.URL,
-				FinalURL:       res.FinalURL,
-				ResponseCode:   res.ResponseCode,
-				ResponseReason: res.ResponseReason,
-				Protocol:       res.Protocol,
-				ContentLength:  res.ContentLength,
-				Title:          res.Title,
-				Failed:         res.Failed,
-				FailedReason:   res.FailedReason,
-				Filename:       res.Filename,
-				Screenshot:     res.Screenshot,
-				MatchedFields:  []string{matchedField},
-			})
+// rankResults orders searchResults by their full-text rank, adding a
+// highlighted snippet to each. Queries without full-text terms are left
+// as is.
+func (h *ApiHandler) rankResults(query search.Node, searchResults []searchResult) error {
+	ids := make([]uint, 0, len(searchResults))
+	for _, result := range searchResults {
+		ids = append(ids, result.ID)
+	}
+
+	hits, err := search.Rank(h.DB, query, ids)
+	if err != nil || hits == nil {
+		return err
+	}
 
-			// Mark the result ID as added
-			resultIDs[res.ID] = true
+	for i := range searchResults {
+		if hit, ok := hits[searchResults[i].ID]; ok {
+			searchResults[i].Rank = hit.Rank
+			searchResults[i].Snippet = hit.Snippet
 		}
 	}
-	return searchResults
+
+	sort.SliceStable(searchResults, func(i, j int) bool {
+		return searchResults[i].Rank > searchResults[j].Rank
+	})
+
+	return nil
 }
 
 // appendUnique ensures no duplicates in the list of matched fields
@@ -0,0 +1,331 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sensepost/gowitness/pkg/alerts"
+	"github.com/sensepost/gowitness/pkg/log"
+	"github.com/sensepost/gowitness/pkg/models"
+	"github.com/sensepost/gowitness/pkg/search"
+	"gorm.io/gorm"
+)
+
+type savedSearchRequest struct {
+	Name   string             `json:"name"`
+	Query  string             `json:"query"`
+	Alerts []alertRuleRequest `json:"alerts"`
+}
+
+type alertRuleRequest struct {
+	SavedSearchID uint   `json:"saved_search_id"`
+	Type          string `json:"type"`
+	Target        string `json:"target"`
+	Enabled       *bool  `json:"enabled"`
+}
+
+type alertRuleUpdateRequest struct {
+	ID      uint    `json:"id"`
+	Target  *string `json:"target"`
+	Enabled *bool   `json:"enabled"`
+}
+
+type idRequest struct {
+	ID uint `json:"id"`
+}
+
+// SavedSearchListHandler lists saved searches
+//
+//	@Summary		Get saved searches
+//	@Description	Get all saved searches, along with their alert rules.
+//	@Tags			Searches
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	[]models.SavedSearch
+//	@Router			/searches [get]
+func (h *ApiHandler) SavedSearchListHandler(w http.ResponseWriter, r *http.Request) {
+	var searches = []*models.SavedSearch{}
+
+	if err := h.DB.Model(&models.SavedSearch{}).Preload("Alerts").
+		Order("name").Find(&searches).Error; err != nil {
+		log.Error("could not get saved searches", "err", err)
+		http.Error(w, "Error getting saved searches", http.StatusInternalServerError)
+		return
+	}
+
+	jsonData, err := json.Marshal(searches)
+	if err != nil {
+		http.Error(w, "Error creating JSON response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(jsonData)
+}
+
+// SavedSearchCreateHandler saves a search
+//
+//	@Summary		Save a search
+//	@Description	Saves a search query, optionally with alert rules. Alert rules notify a `webhook`
+//	@Description	(the target is a URL on a host allowed by --alerts-webhook-host), append to a `file`
+//	@Description	(the target is a relative path inside --alerts-dir) or publish to the `events`
+//	@Description	stream whenever a new result matches the query.
+//	@Tags			Searches
+//	@Accept			json
+//	@Produce		json
+//	@Param			query	body		savedSearchRequest	true	"The search to save"
+//	@Success		200		{object}	models.SavedSearch
+//	@Router			/searches [post]
+func (h *ApiHandler) SavedSearchCreateHandler(w http.ResponseWriter, r *http.Request) {
+	var request savedSearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		log.Error("failed to read json request", "err", err)
+		http.Error(w, "Error reading JSON request", http.StatusInternalServerError)
+		return
+	}
+
+	saved := &models.SavedSearch{
+		Name:  strings.TrimSpace(request.Name),
+		Query: strings.TrimSpace(request.Query),
+	}
+
+	if saved.Name == "" {
+		http.Error(w, "A name is required", http.StatusBadRequest)
+		return
+	}
+	if err := validateQuery(saved.Query); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for _, alert := range request.Alerts {
+		rule, err := h.newAlertRule(alert)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		saved.Alerts = append(saved.Alerts, *rule)
+	}
+
+	if err := h.DB.Create(saved).Error; err != nil {
+		log.Error("could not save search", "err", err)
+		http.Error(w, "Error saving search", http.StatusInternalServerError)
+		return
+	}
+
+	jsonData, err := json.Marshal(saved)
+	if err != nil {
+		http.Error(w, "Error creating JSON response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(jsonData)
+}
+
+// SavedSearchDeleteHandler deletes a saved search
+//
+//	@Summary		Delete a saved search
+//	@Description	Deletes a saved search, by id, and all of its alert rules.
+//	@Tags			Searches
+//	@Accept			json
+//	@Produce		json
+//	@Param			query	body		idRequest	true	"The saved search ID to delete"
+//	@Success		200		{string}	string		"ok"
+//	@Router			/searches/delete [post]
+func (h *ApiHandler) SavedSearchDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	var request idRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		log.Error("failed to read json request", "err", err)
+		http.Error(w, "Error reading JSON request", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("saved_search_id = ?", request.ID).Delete(&models.AlertRule{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.SavedSearch{}, request.ID).Error
+	}); err != nil {
+		log.Error("could not delete saved search", "err", err)
+		http.Error(w, "Error deleting saved search", http.StatusInternalServerError)
+		return
+	}
+
+	response := `ok`
+	jsonData, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, "Error creating JSON response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(jsonData)
+}
+
+// AlertRuleCreateHandler adds an alert rule to a saved search
+//
+//	@Summary		Add an alert rule
+//	@Description	Adds an alert rule to a saved search. Rules are enabled unless `enabled` is false.
+//	@Tags			Searches
+//	@Accept			json
+//	@Produce		json
+//	@Param			query	body		alertRuleRequest	true	"The alert rule to add"
+//	@Success		200		{object}	models.AlertRule
+//	@Router			/searches/alerts [post]
+func (h *ApiHandler) AlertRuleCreateHandler(w http.ResponseWriter, r *http.Request) {
+	var request alertRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		log.Error("failed to read json request", "err", err)
+		http.Error(w, "Error reading JSON request", http.StatusInternalServerError)
+		return
+	}
+
+	rule, err := h.newAlertRule(request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.DB.First(&models.SavedSearch{}, request.SavedSearchID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "Saved search not found", http.StatusNotFound)
+			return
+		}
+		log.Error("could not get saved search", "err", err)
+		http.Error(w, "Error getting saved search", http.StatusInternalServerError)
+		return
+	}
+
+	rule.SavedSearchID = request.SavedSearchID
+	if err := h.DB.Create(rule).Error; err != nil {
+		log.Error("could not save alert rule", "err", err)
+		http.Error(w, "Error saving alert rule", http.StatusInternalServerError)
+		return
+	}
+
+	jsonData, err := json.Marshal(rule)
+	if err != nil {
+		http.Error(w, "Error creating JSON response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(jsonData)
+}
+
+// AlertRuleUpdateHandler updates an alert rule
+//
+//	@Summary		Update an alert rule
+//	@Description	Enables or disables an alert rule, or changes its target. Omitted fields are left unchanged.
+//	@Tags			Searches
+//	@Accept			json
+//	@Produce		json
+//	@Param			query	body		alertRuleUpdateRequest	true	"The alert rule changes"
+//	@Success		200		{object}	models.AlertRule
+//	@Router			/searches/alerts/update [post]
+func (h *ApiHandler) AlertRuleUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	var request alertRuleUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		log.Error("failed to read json request", "err", err)
+		http.Error(w, "Error reading JSON request", http.StatusInternalServerError)
+		return
+	}
+
+	var rule models.AlertRule
+	if err := h.DB.First(&rule, request.ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "Alert rule not found", http.StatusNotFound)
+			return
+		}
+		log.Error("could not get alert rule", "err", err)
+		http.Error(w, "Error getting alert rule", http.StatusInternalServerError)
+		return
+	}
+
+	if request.Target != nil {
+		rule.Target = strings.TrimSpace(*request.Target)
+	}
+	if request.Enabled != nil {
+		rule.Enabled = *request.Enabled
+	}
+	if err := alerts.ValidateRule(&rule, h.Alerts); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.DB.Save(&rule).Error; err != nil {
+		log.Error("could not update alert rule", "err", err)
+		http.Error(w, "Error updating alert rule", http.StatusInternalServerError)
+		return
+	}
+
+	jsonData, err := json.Marshal(rule)
+	if err != nil {
+		http.Error(w, "Error creating JSON response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(jsonData)
+}
+
+// AlertRuleDeleteHandler deletes an alert rule
+//
+//	@Summary		Delete an alert rule
+//	@Description	Deletes an alert rule, by id.
+//	@Tags			Searches
+//	@Accept			json
+//	@Produce		json
+//	@Param			query	body		idRequest	true	"The alert rule ID to delete"
+//	@Success		200		{string}	string		"ok"
+//	@Router			/searches/alerts/delete [post]
+func (h *ApiHandler) AlertRuleDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	var request idRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		log.Error("failed to read json request", "err", err)
+		http.Error(w, "Error reading JSON request", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.DB.Delete(&models.AlertRule{}, request.ID).Error; err != nil {
+		log.Error("could not delete alert rule", "err", err)
+		http.Error(w, "Error deleting alert rule", http.StatusInternalServerError)
+		return
+	}
+
+	response := `ok`
+	jsonData, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, "Error creating JSON response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(jsonData)
+}
+
+// validateQuery checks that a query is not empty and parses
+func validateQuery(query string) error {
+	parsed, err := search.Parse(query)
+	if err != nil {
+		return fmt.Errorf("invalid search query: %w", err)
+	}
+	if parsed == nil {
+		return errors.New("a search query is required")
+	}
+
+	return nil
+}
+
+// newAlertRule converts a request to an alert rule the alerts policy allows
+func (h *ApiHandler) newAlertRule(request alertRuleRequest) (*models.AlertRule, error) {
+	rule := &models.AlertRule{
+		Type:    strings.ToLower(strings.TrimSpace(request.Type)),
+		Target:  strings.TrimSpace(request.Target),
+		Enabled: request.Enabled == nil || *request.Enabled,
+	}
+
+	if err := alerts.ValidateRule(rule, h.Alerts); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
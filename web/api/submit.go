@@ -1,11 +1,16 @@
 package api
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"time"
 
+	"github.com/sensepost/gowitness/pkg/events"
 	"github.com/sensepost/gowitness/pkg/log"
+	"github.com/sensepost/gowitness/pkg/readers"
 	"github.com/sensepost/gowitness/pkg/runner"
 	driver "github.com/sensepost/gowitness/pkg/runner/drivers"
 	"github.com/sensepost/gowitness/pkg/writers"
@@ -78,11 +83,14 @@ func (h *ApiHandler) SubmitHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Error connecting to DB for writer", http.StatusInternalServerError)
 		return
 	}
+	writer.Events = h.Events
+	writer.Alerts = h.Alerts
 
 	logger := slog.New(log.Logger)
 
 	driver, err := driver.NewChromedp(logger, *options)
 	if err != nil {
+		writer.Close()
 		http.Error(w, "Error sarting driver", http.StatusInternalServerError)
 		return
 	}
@@ -90,12 +98,14 @@ func (h *ApiHandler) SubmitHandler(w http.ResponseWriter, r *http.Request) {
 	runner, err := runner.NewRunner(logger, driver, *options, []writers.Writer{writer})
 	if err != nil {
 		log.Error("error starting runner", "err", err)
+		driver.Close()
+		writer.Close()
 		http.Error(w, "Error starting runner", http.StatusInternalServerError)
 		return
 	}
 
 	// have everything we need! start ther runner goroutine
-	go dispatchRunner(runner, request.URLs)
+	go dispatchRunner(runner, request.URLs, h.Events)
 
 	response := `Probing started`
 	jsonData, err := json.Marshal(response)
@@ -107,16 +117,58 @@ func (h *ApiHandler) SubmitHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonData)
 }
 
-// dispatchRunner run's a runner in a separate goroutine
-func dispatchRunner(runner *runner.Runner, targets []string) {
+// dispatchRunner run's a runner in a separate goroutine, publishing job
+// progress to the events hub while it runs.
+func dispatchRunner(runner *runner.Runner, targets []string, hub *events.Hub) {
+	job := &events.Job{
+		ID:    newJobID(),
+		Total: len(targets),
+	}
+	hub.Publish(events.JobEvent, *job)
+
 	// feed in targets
 	go func() {
 		for _, url := range targets {
-			runner.Targets <- url
+			runner.Targets <- &readers.Target{URL: url}
 		}
 		close(runner.Targets)
 	}()
 
+	// report progress while the runner is busy
+	done, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if processed := runner.Processed(); processed != job.Done {
+					job.Done = processed
+					hub.Publish(events.JobEvent, *job)
+				}
+			}
+		}
+	}()
+
 	runner.Run()
 	runner.Close()
+	close(done)
+	<-stopped
+
+	job.Done = runner.Processed()
+	job.Finished = true
+	hub.Publish(events.JobEvent, *job)
+}
+
+// newJobID returns a random identifier for a submitted job
+func newJobID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
 }
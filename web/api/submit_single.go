@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/sensepost/gowitness/pkg/log"
+	"github.com/sensepost/gowitness/pkg/readers"
 	"github.com/sensepost/gowitness/pkg/runner"
 	driver "github.com/sensepost/gowitness/pkg/runner/drivers"
 	"github.com/sensepost/gowitness/pkg/writers"
@@ -87,7 +88,7 @@ func (h *ApiHandler) SubmitSingleHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	go func() {
-		runner.Targets <- request.URL
+		runner.Targets <- &readers.Target{URL: request.URL}
 		close(runner.Targets)
 	}()
 
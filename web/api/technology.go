@@ -39,3 +39,35 @@ func (h *ApiHandler) TechnologyListHandler(w http.ResponseWriter, r *http.Reques
 
 	w.Write(jsonData)
 }
+
+type categoryListResponse struct {
+	Value []string `json:"categories"`
+}
+
+// CategoryListHandler lists page categories
+//
+//	@Summary		Get categories
+//	@Description	Get all the unique page categories that signatures have assigned to results.
+//	@Tags			Results
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	categoryListResponse
+//	@Router			/results/categories [get]
+func (h *ApiHandler) CategoryListHandler(w http.ResponseWriter, r *http.Request) {
+	var results = &categoryListResponse{Value: []string{}}
+
+	if err := h.DB.Model(&models.Category{}).Distinct("value").Order("value").
+		Find(&results.Value).Error; err != nil {
+
+		log.Error("could not find distinct categories", "err", err)
+		return
+	}
+
+	jsonData, err := json.Marshal(results)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(jsonData)
+}
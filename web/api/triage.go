@@ -0,0 +1,198 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/sensepost/gowitness/pkg/database"
+	"github.com/sensepost/gowitness/pkg/log"
+	"github.com/sensepost/gowitness/pkg/models"
+	"gorm.io/gorm"
+)
+
+type triageRequest struct {
+	IDs        []uint   `json:"ids"`
+	Clusters   []uint   `json:"clusters"`
+	AddTags    []string `json:"add_tags"`
+	RemoveTags []string `json:"remove_tags"`
+	Notes      *string  `json:"notes"`
+	Reviewed   *bool    `json:"reviewed"`
+}
+
+type tagListResponse struct {
+	Value []string `json:"tags"`
+}
+
+// TriageHandler updates tags, notes and the reviewed state of results
+//
+//	@Summary		Triage results
+//	@Description	Adds or removes tags, sets notes and marks results as (un)reviewed, in bulk.
+//	@Description	Results are selected by id, and/or by perception hash cluster to triage every
+//	@Description	result in a cluster. Fields that are omitted from the request are left unchanged.
+//	@Tags			Results
+//	@Accept			json
+//	@Produce		json
+//	@Param			query	body		triageRequest	true	"The result IDs and the triage changes to apply"
+//	@Success		200		{string}	string			"ok"
+//	@Router			/results/triage [post]
+func (h *ApiHandler) TriageHandler(w http.ResponseWriter, r *http.Request) {
+	var request triageRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		log.Error("failed to read json request", "err", err)
+		http.Error(w, "Error reading JSON request", http.StatusInternalServerError)
+		return
+	}
+
+	if len(request.IDs) == 0 && len(request.Clusters) == 0 {
+		http.Error(w, "No result IDs or clusters provided", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if len(request.Clusters) > 0 {
+			var ids []uint
+			if err := tx.Model(&models.Result{}).
+				Where("perception_hash_group_id IN ?", request.Clusters).
+				Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+			// a result may be listed by id and be in a cluster
+			request.IDs = append(request.IDs, ids...)
+			slices.Sort(request.IDs)
+			request.IDs = slices.Compact(request.IDs)
+		}
+
+		// clusters may hold many results, so keep the bind variables of a
+		// statement in bounds
+		for batch := range slices.Chunk(request.IDs, database.DeleteBatchSize) {
+			batchRequest := request
+			batchRequest.IDs = batch
+			if err := triageResults(tx, batchRequest); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		log.Error("failed to triage results", "err", err)
+		http.Error(w, "Error updating results", http.StatusInternalServerError)
+		return
+	}
+
+	response := `ok`
+	jsonData, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, "Error creating JSON response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(jsonData)
+}
+
+// TagListHandler lists tags
+//
+//	@Summary		Get tags
+//	@Description	Get all the unique tags that have been applied to results.
+//	@Tags			Results
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	tagListResponse
+//	@Router			/results/tags [get]
+func (h *ApiHandler) TagListHandler(w http.ResponseWriter, r *http.Request) {
+	var results = &tagListResponse{}
+
+	if err := h.DB.Model(&models.Tag{}).Distinct("value").Order("value").
+		Find(&results.Value).Error; err != nil {
+
+		log.Error("could not find distinct tags", "err", err)
+		return
+	}
+
+	jsonData, err := json.Marshal(results)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(jsonData)
+}
+
+// triageResults applies a triage request to results
+func triageResults(tx *gorm.DB, request triageRequest) error {
+	if remove := cleanTags(request.RemoveTags); len(remove) > 0 {
+		if err := tx.Where("result_id IN ? AND value IN ?", request.IDs, remove).
+			Delete(&models.Tag{}).Error; err != nil {
+			return err
+		}
+	}
+
+	if add := cleanTags(request.AddTags); len(add) > 0 {
+		// find the tags results already have so we don't duplicate them
+		var existing []models.Tag
+		if err := tx.Where("result_id IN ? AND value IN ?", request.IDs, add).
+			Find(&existing).Error; err != nil {
+			return err
+		}
+
+		has := make(map[uint]map[string]bool)
+		for _, tag := range existing {
+			if has[tag.ResultID] == nil {
+				has[tag.ResultID] = make(map[string]bool)
+			}
+			has[tag.ResultID][tag.Value] = true
+		}
+
+		var tags []models.Tag
+		for _, id := range request.IDs {
+			for _, value := range add {
+				if has[id][value] {
+					continue
+				}
+				tags = append(tags, models.Tag{ResultID: id, Value: value})
+			}
+		}
+
+		if len(tags) > 0 {
+			if err := tx.CreateInBatches(&tags, database.DeleteBatchSize).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	updates := make(map[string]interface{})
+	if request.Notes != nil {
+		updates["notes"] = *request.Notes
+	}
+	if request.Reviewed != nil {
+		updates["reviewed"] = *request.Reviewed
+	}
+
+	if len(updates) > 0 {
+		if err := tx.Model(&models.Result{}).Where("id IN ?", request.IDs).
+			Updates(updates).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// cleanTags trims tag values, dropping empty and duplicate ones
+func cleanTags(tags []string) []string {
+	var cleaned []string
+	seen := make(map[string]bool)
+
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+
+		seen[tag] = true
+		cleaned = append(cleaned, tag)
+	}
+
+	return cleaned
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/sensepost/gowitness/internal/testdb"
+	"github.com/sensepost/gowitness/pkg/database"
+	"github.com/sensepost/gowitness/pkg/models"
+)
+
+func newTestHandler(t *testing.T) *ApiHandler {
+	t.Helper()
+
+	_, conn := testdb.Open(t, database.Connection)
+	return &ApiHandler{DB: conn}
+}
+
+func triage(t *testing.T, h *ApiHandler, request triageRequest) {
+	t.Helper()
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("could not marshal the triage request: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	h.TriageHandler(rec, httptest.NewRequest(http.MethodPost, "/api/results/triage", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("TriageHandler() status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+}
+
+func resultTags(t *testing.T, h *ApiHandler, id uint) []string {
+	t.Helper()
+
+	var tags []string
+	if err := h.DB.Model(&models.Tag{}).Where("result_id = ?", id).Order("value").
+		Pluck("value", &tags).Error; err != nil {
+		t.Fatalf("could not get tags: %v", err)
+	}
+
+	return tags
+}
+
+func TestTriageHandler(t *testing.T) {
+	h := newTestHandler(t)
+
+	results := []*models.Result{
+		{URL: "https://a.example.com", Tags: []models.Tag{{Value: "login"}}},
+		{URL: "https://b.example.com"},
+		{URL: "https://c.example.com"},
+	}
+	if err := h.DB.Create(results).Error; err != nil {
+		t.Fatalf("could not create test results: %v", err)
+	}
+
+	notes, reviewed := "checked", true
+	triage(t, h, triageRequest{
+		IDs:      []uint{results[0].ID, results[1].ID},
+		AddTags:  []string{" login ", "interesting", "interesting", ""},
+		Notes:    &notes,
+		Reviewed: &reviewed,
+	})
+
+	// tags are trimmed and not duplicated
+	if got, want := resultTags(t, h, results[0].ID), []string{"interesting", "login"}; !slices.Equal(got, want) {
+		t.Errorf("tags of a = %v, want %v", got, want)
+	}
+	if got, want := resultTags(t, h, results[1].ID), []string{"interesting", "login"}; !slices.Equal(got, want) {
+		t.Errorf("tags of b = %v, want %v", got, want)
+	}
+	if got := resultTags(t, h, results[2].ID); len(got) != 0 {
+		t.Errorf("tags of c = %v, want none", got)
+	}
+
+	triage(t, h, triageRequest{
+		IDs:        []uint{results[0].ID},
+		RemoveTags: []string{"login"},
+	})
+	if got, want := resultTags(t, h, results[0].ID), []string{"interesting"}; !slices.Equal(got, want) {
+		t.Errorf("tags of a after removing = %v, want %v", got, want)
+	}
+
+	// omitted fields are left unchanged
+	var got []models.Result
+	if err := h.DB.Order("id").Find(&got).Error; err != nil {
+		t.Fatalf("could not get results: %v", err)
+	}
+	for i, want := range []struct {
+		notes    string
+		reviewed bool
+	}{{"checked", true}, {"checked", true}, {"", false}} {
+		if got[i].Notes != want.notes || got[i].Reviewed != want.reviewed {
+			t.Errorf("result %d notes, reviewed = %q, %v, want %q, %v",
+				i, got[i].Notes, got[i].Reviewed, want.notes, want.reviewed)
+		}
+	}
+}
+
+func TestTriageHandlerClusters(t *testing.T) {
+	h := newTestHandler(t)
+
+	// a cluster with more results than fit in one batch of ids
+	var results []*models.Result
+	for range database.DeleteBatchSize + 10 {
+		results = append(results, &models.Result{URL: "https://example.com", PerceptionHashGroupId: 1})
+	}
+	other := &models.Result{URL: "https://example.org", PerceptionHashGroupId: 2}
+	results = append(results, other)
+	if err := h.DB.CreateInBatches(results, 100).Error; err != nil {
+		t.Fatalf("could not create test results: %v", err)
+	}
+
+	reviewed := true
+	triage(t, h, triageRequest{
+		Clusters: []uint{1},
+		AddTags:  []string{"parked"},
+		Reviewed: &reviewed,
+	})
+
+	var tagged, marked int64
+	if err := h.DB.Model(&models.Tag{}).Where("value = ?", "parked").Count(&tagged).Error; err != nil {
+		t.Fatalf("could not count tags: %v", err)
+	}
+	if err := h.DB.Model(&models.Result{}).Where("reviewed = ?", true).Count(&marked).Error; err != nil {
+		t.Fatalf("could not count reviewed results: %v", err)
+	}
+	if want := int64(database.DeleteBatchSize + 10); tagged != want || marked != want {
+		t.Errorf("tagged, reviewed = %d, %d, want %d of the cluster", tagged, marked, want)
+	}
+	if got := resultTags(t, h, other.ID); len(got) != 0 {
+		t.Errorf("tags of a result in another cluster = %v, want none", got)
+	}
+}
@@ -1,8 +1,10 @@
 package web
 
 import (
+	"context"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/sensepost/gowitness/web/docs"
 	httpSwagger "github.com/swaggo/http-swagger"
@@ -10,6 +12,8 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
+	"github.com/sensepost/gowitness/pkg/alerts"
+	"github.com/sensepost/gowitness/pkg/database"
 	"github.com/sensepost/gowitness/pkg/log"
 	"github.com/sensepost/gowitness/web/api"
 )
@@ -20,6 +24,8 @@ type Server struct {
 	Port           int
 	DbUri          string
 	ScreenshotPath string
+	// Alerts limits where alert rules may send notifications
+	Alerts alerts.Policy
 }
 
 // NewServer returns a new server intance
@@ -62,6 +68,21 @@ func (s *Server) Run() {
 		log.Error("could not get api handler up", "err", err)
 		return
 	}
+	apih.Alerts = s.Alerts
+
+	// index results from before the database had a full-text index.
+	// searches don't use the index until this is done.
+	go func() {
+		if err := database.BackfillFullText(apih.DB); err != nil {
+			log.Warn("could not build the full-text search index", "err", err)
+		}
+	}()
+
+	// publish results written by other processes to the events hub, until
+	// the server stops
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go apih.WatchResults(ctx, 2*time.Second)
 
 	r.Route("/api", func(r chi.Router) {
 		r.Use(isJSON)
@@ -72,15 +93,32 @@ func (s *Server) Run() {
 		r.Get("/ping", apih.PingHandler)
 		r.Get("/statistics", apih.StatisticsHandler)
 		r.Get("/wappalyzer", apih.WappalyzerHandler)
-		r.Post("/search", apih.SearchHandler)
-		r.Post("/submit", apih.SubmitHandler)
-		r.Post("/submit/single", apih.SubmitSingleHandler)
+		r.Get("/events", apih.EventsHandler)
+		r.Post("/imagehash", apih.ImageHashHandler)
 
 		r.Get("/results/gallery", apih.GalleryHandler)
 		r.Get("/results/list", apih.ListHandler)
+		r.Get("/results/clusters", apih.ClustersHandler)
+		r.Get("/results/clusters/{id}/export", apih.ClusterExportHandler)
 		r.Get("/results/detail/{id}", apih.DetailHandler)
-		r.Post("/results/delete", apih.DeleteResultHandler)
+		r.Get("/results/favicons/{md5}", apih.FaviconHandler)
 		r.Get("/results/technology", apih.TechnologyListHandler)
+		r.Get("/results/tags", apih.TagListHandler)
+		r.Get("/results/categories", apih.CategoryListHandler)
+
+		r.Get("/searches", apih.SavedSearchListHandler)
+
+		r.Post("/search", apih.SearchHandler)
+		r.Post("/submit", apih.SubmitHandler)
+		r.Post("/submit/single", apih.SubmitSingleHandler)
+		r.Post("/results/delete", apih.DeleteResultHandler)
+		r.Post("/results/delete/bulk", apih.BulkDeleteHandler)
+		r.Post("/results/triage", apih.TriageHandler)
+		r.Post("/searches", apih.SavedSearchCreateHandler)
+		r.Post("/searches/delete", apih.SavedSearchDeleteHandler)
+		r.Post("/searches/alerts", apih.AlertRuleCreateHandler)
+		r.Post("/searches/alerts/update", apih.AlertRuleUpdateHandler)
+		r.Post("/searches/alerts/delete", apih.AlertRuleDeleteHandler)
 	})
 
 	// screenshot files
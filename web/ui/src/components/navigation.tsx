@@ -1,38 +1,90 @@
 import { useState, useRef, useEffect } from "react";
-import { ImageIcon, ImagePlusIcon, LayoutDashboardIcon, ScanIcon, SearchIcon, TableIcon } from "lucide-react";
-import { Form, NavLink, useSubmit } from "react-router-dom";
+import { ImageIcon, ImagePlusIcon, ImageUpIcon, LayersIcon, LayoutDashboardIcon, ScanIcon, SearchIcon, TableIcon } from "lucide-react";
+import { Form, NavLink, useNavigate, useSubmit } from "react-router-dom";
 import { Button } from "./ui/button";
 import { Input } from "./ui/input";
 import { ModeToggle } from "./mode-toggle";
 import { Popover, PopoverContent, PopoverTrigger } from "./ui/popover";
 import { Badge } from "./ui/badge";
+import { toast } from "@/hooks/use-toast";
+import * as api from "@/lib/api/api";
 
 const navs = [
   { name: `Dashboard`, icon: <LayoutDashboardIcon className="mr-2 h-4 w-4" />, to: `/` },
   { name: `Gallery`, icon: <ImageIcon className="mr-2 h-4 w-4" />, to: `/gallery` },
+  { name: `Clusters`, icon: <LayersIcon className="mr-2 h-4 w-4" />, to: `/clusters` },
   { name: `Overview`, icon: <TableIcon className="mr-2 h-4 w-4" />, to: `/overview` },
   { name: `New Probe`, icon: <ImagePlusIcon className="mr-2 h-4 w-4" />, to: `/submit` }
 ];
 
 const searchOperators = [
   { key: 'title', description: 'search by title' },
-  { key: 'body', description: 'search by html body' },
+  { key: 'body', description: 'full-text search of the page text' },
+  { key: 'content', description: 'full-text search of page text, headers and console' },
+  { key: 'url', description: 'search by url' },
+  { key: 'final_url', description: 'search by final url' },
   { key: 'tech', description: 'search by technology' },
-  { key: 'header', description: 'search by header' },
+  { key: 'header', description: 'search by header name or value' },
+  { key: 'tag', description: 'search by tag' },
+  { key: 'cookie', description: 'search by cookie' },
+  { key: 'console', description: 'search by console log' },
+  { key: 'link', description: 'search by link url on the page' },
+  { key: 'form', description: 'search by form action' },
+  { key: 'input', description: 'search by form input name or type, e.g. input=password' },
+  { key: 'script', description: 'search by script source, e.g. script:jquery-1.' },
+  { key: 'meta', description: 'search by meta tag name or content, e.g. meta:wordpress' },
+  { key: 'blocked', description: 'search by out of scope requests that were blocked' },
+  { key: 'cert', description: 'search by certificate subject, issuer or san' },
+  { key: 'ip', description: 'search by resolved or remote ip, or the ip a virtual host was requested from' },
+  { key: 'vhost', description: 'search by virtual host' },
+  { key: 'code', description: 'filter by status code, e.g. code>=400' },
+  { key: 'size', description: 'filter by content length' },
+  { key: 'probed_at', description: 'filter by date, e.g. probed_at>2026-01-01' },
+  { key: 'is', description: 'filter by state: failed, reviewed, blank, error_page or soft404' },
   { key: 'p', description: 'search by perception hash' },
+  { key: 'category', description: 'filter by page category, e.g. category:"login form"' },
+  { key: 'favicon', description: 'search by favicon mmh3 or md5 hash' },
+  { key: 'similar', description: 'visually similar to a result, e.g. similar:42 or similar:dhash:42~8' },
 ];
 
+// the hash used when searching for screenshots similar to an uploaded image
+const uploadHashAlgorithm = 'phash16';
+
 const Navigation = () => {
   const [searchValue, setSearchValue] = useState("");
   const [usedOperators, setUsedOperators] = useState<string[]>([]);
   const [isPopoverOpen, setIsPopoverOpen] = useState(false);
   const inputRef = useRef<HTMLInputElement>(null);
+  const fileRef = useRef<HTMLInputElement>(null);
   const submit = useSubmit();
+  const navigate = useNavigate();
+
+  // search for screenshots that look like an uploaded image
+  const handleImageUpload = async (e: React.ChangeEvent<HTMLInputElement>) => {
+    const file = e.target.files?.[0];
+    e.target.value = '';
+    if (!file) return;
+
+    const data = new FormData();
+    data.append('image', file);
+
+    try {
+      const { hashes } = await api.upload('imagehash', data);
+      const query = `similar:${uploadHashAlgorithm}:${hashes[uploadHashAlgorithm]}`;
+      navigate(`/search?query=${encodeURIComponent(query)}`);
+    } catch (err) {
+      toast({
+        title: "Error",
+        variant: "destructive",
+        description: `Could not hash image: ${err}`
+      });
+    }
+  };
 
   const handleInputChange = (e: React.ChangeEvent<HTMLInputElement>) => {
     setSearchValue(e.target.value);
-    const operators = e.target.value.match(/(\w+):/g) || [];
-    setUsedOperators(operators.map(op => op.slice(0, -1)));
+    const operators = e.target.value.match(/\w+(?=!=|[:=<>])/g) || [];
+    setUsedOperators(operators);
   };
 
   const handleSubmit = (event: React.FormEvent<HTMLFormElement>) => {
@@ -108,11 +160,12 @@ const Navigation = () => {
                 <div className="space-y-2">
                   <h4 className="font-medium leading-none">Search Operators</h4>
                   <p className="text-sm text-muted-foreground">
-                    Use these operators to refine your search.
+                    Use these operators to refine your search. Combine them with AND, OR,
+                    NOT (or -) and parentheses, and use quotes for phrases.
                   </p>
                 </div>
-                <div className="grid gap-2">
-                  {searchOperators.length === usedOperators.length && <div className="text-sm">No operators left.</div>}
+                <div className="grid gap-2 max-h-[300px] overflow-y-auto">
+                  {searchOperators.every(operator => usedOperators.includes(operator.key)) && <div className="text-sm">No operators left.</div>}
                   {searchOperators.map((operator) => (
                     !usedOperators.includes(operator.key) && (
                       <div key={operator.key} className="flex items-center">
@@ -128,6 +181,10 @@ const Navigation = () => {
             </PopoverContent>
           </Popover>
         </Form>
+        <input ref={fileRef} type="file" accept="image/png,image/jpeg" className="hidden" onChange={handleImageUpload} />
+        <Button variant="outline" size="icon" title="Find screenshots similar to an image" onClick={() => fileRef.current?.click()}>
+          <ImageUpIcon className="h-4 w-4" />
+        </Button>
         <ModeToggle />
       </div>
     </>
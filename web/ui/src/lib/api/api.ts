@@ -1,4 +1,7 @@
-import { gallery, list, statistics, wappalyzer, detail, searchresult, technologylist } from "@/lib/api/types";
+import {
+  gallery, list, statistics, wappalyzer, detail, searchresult, technologylist, taglist, categorylist, clusters, imagehashes,
+  liveresult, livejob, livedelete, livealert, savedsearch, alertrule
+} from "@/lib/api/types";
 
 const endpoints = {
   // api base path
@@ -33,14 +36,38 @@ const endpoints = {
     path: `/results/list`,
     returnas: [] as list[]
   },
+  clusters: {
+    path: `/results/clusters`,
+    returnas: {} as clusters
+  },
+  clusterexport: {
+    path: `/results/clusters/:id/export`,
+    returnas: "" as string
+  },
   detail: {
     path: `/results/detail/:id`,
     returnas: {} as detail
   },
+  favicon: {
+    path: `/results/favicons/:md5`,
+    returnas: [] // n/a, an image
+  },
   technology: {
     path: `/results/technology`,
     returnas: {} as technologylist
   },
+  tags: {
+    path: `/results/tags`,
+    returnas: {} as taglist
+  },
+  categories: {
+    path: `/results/categories`,
+    returnas: {} as categorylist
+  },
+  searches: {
+    path: `/searches`,
+    returnas: [] as savedsearch[]
+  },
 
   // post endpoints
   search: {
@@ -51,6 +78,34 @@ const endpoints = {
     path: `/results/delete`,
     returnas: "" as string
   },
+  triage: {
+    path: `/results/triage`,
+    returnas: "" as string
+  },
+  savesearch: {
+    path: `/searches`,
+    returnas: {} as savedsearch
+  },
+  deletesearch: {
+    path: `/searches/delete`,
+    returnas: "" as string
+  },
+  addalert: {
+    path: `/searches/alerts`,
+    returnas: {} as alertrule
+  },
+  updatealert: {
+    path: `/searches/alerts/update`,
+    returnas: {} as alertrule
+  },
+  deletealert: {
+    path: `/searches/alerts/delete`,
+    returnas: "" as string
+  },
+  imagehash: {
+    path: `/imagehash`,
+    returnas: {} as imagehashes
+  },
   submit: {
     path: `/submit`,
     returnas: "" as string
@@ -129,9 +184,52 @@ const post = async <K extends keyof Endpoints>(
     body: JSON.stringify(data)
   });
 
-  if (!res.ok) throw new Error(`HTTP Error: ${res.status}`);
+  if (!res.ok) {
+    const message = (await res.text()).trim();
+    throw new Error(message ? `HTTP Error: ${res.status} (${message})` : `HTTP Error: ${res.status}`);
+  }
 
   return await res.json() as EndpointReturnType<K>;
 };
 
-export { endpoints, get, post };
\ No newline at end of file
+// upload posts a multipart form, like a file upload
+const upload = async <K extends keyof Endpoints>(
+  endpointKey: K,
+  data: FormData
+): Promise<EndpointReturnType<K>> => {
+
+  const endpoint = endpoints[endpointKey];
+  const res = await fetch(`${endpoints.base.path}${endpoint.path}`, {
+    method: 'POST',
+    body: data
+  });
+
+  if (!res.ok) {
+    const message = (await res.text()).trim();
+    throw new Error(message ? `HTTP Error: ${res.status} (${message})` : `HTTP Error: ${res.status}`);
+  }
+
+  return await res.json() as EndpointReturnType<K>;
+};
+
+type liveEventHandlers = {
+  result?: (data: liveresult) => void;
+  job?: (data: livejob) => void;
+  delete?: (data: livedelete) => void;
+  alert?: (data: livealert) => void;
+};
+
+// subscribe to the live events stream. returns a function that closes the stream.
+const subscribe = (handlers: liveEventHandlers): (() => void) => {
+  const source = new EventSource(`${endpoints.base.path}/events`);
+
+  Object.entries(handlers).forEach(([type, handler]) => {
+    source.addEventListener(type, (event) => {
+      (handler as (data: unknown) => void)(JSON.parse((event as MessageEvent).data));
+    });
+  });
+
+  return () => source.close();
+};
+
+export { endpoints, get, post, upload, subscribe };
\ No newline at end of file
@@ -35,7 +35,18 @@ type galleryResult = {
   file_name: string;
   screenshot: string;
   failed: boolean;
+  reviewed: boolean;
+  blank: boolean;
+  error_page: boolean;
+  soft404: boolean;
   technologies: string[];
+  tags: string[];
+  categories: string[];
+  favicon?: {
+    mmh3: number;
+    md5: string;
+    mime_type: string;
+  };
 };
 
 // list
@@ -68,6 +79,16 @@ interface tls {
   encrypted_client_hello: boolean;
 }
 
+interface favicon {
+  id: number;
+  result_id: number;
+  url: string;
+  mime_type: string;
+  content: string;
+  mmh3: number;
+  md5: string;
+}
+
 interface sanlist {
   id: number;
   tls_id: number;
@@ -124,6 +145,76 @@ interface cookie {
   source_port: number;
 }
 
+interface link {
+  id: number;
+  result_id: number;
+  url: string;
+  text: string;
+  external: boolean;
+}
+
+interface forminput {
+  id: number;
+  form_id: number;
+  name: string;
+  type: string;
+  value: string;
+}
+
+interface form {
+  id: number;
+  result_id: number;
+  action: string;
+  method: string;
+  inputs: forminput[];
+  has_password: boolean;
+  has_file: boolean;
+}
+
+interface script {
+  id: number;
+  result_id: number;
+  src: string;
+}
+
+interface metatag {
+  id: number;
+  result_id: number;
+  name: string;
+  content: string;
+}
+
+interface blockedrequest {
+  id: number;
+  result_id: number;
+  url: string;
+  reason: string;
+}
+
+interface tag {
+  id: number;
+  result_id: number;
+  value: string;
+}
+
+interface category {
+  id: number;
+  result_id: number;
+  value: string;
+  signature: string;
+}
+
+interface defaultcredential {
+  id: number;
+  result_id: number;
+  product: string;
+  username: string;
+  password: string;
+  match: string;
+  notes: string;
+  reference: string;
+}
+
 interface detail {
   id: number;
   url: string;
@@ -140,13 +231,36 @@ interface detail {
   is_pdf: boolean;
   failed: boolean;
   failed_reason: string;
+  blank: boolean;
+  error_page: boolean;
+  soft404: boolean;
+  discovered_from_id: number;
+  discovered_from_url: string;
+  discovered_via: string;
+  discovery_depth: number;
+  original_method: string;
+  vhost: string;
+  vhost_ip: string;
+  resolved_ip: string;
+  proxy: string;
+  reviewed: boolean;
+  notes: string;
   screenshot: string;
   tls: tls;
+  favicon: favicon;
   technologies: technology[];
   headers: header[];
   network: networklog[];
   console: consolelog[];
   cookies: cookie[];
+  links: link[];
+  forms: form[];
+  scripts: script[];
+  meta_tags: metatag[];
+  blocked_requests: blockedrequest[];
+  tags: tag[];
+  categories: category[];
+  default_credentials: defaultcredential[];
 }
 
 interface searchresult {
@@ -156,15 +270,108 @@ interface searchresult {
   response_code: number;
   content_length: number;
   title: string;
+  categories: string[];
   matched_fields: string[];
+  rank?: number;
+  snippet?: string;
   file_name: string;
   screenshot: string;
 }
 
+// clusters
+interface clustervalue {
+  value: string;
+  count: number;
+}
+
+interface cluster {
+  id: number;
+  count: number;
+  reviewed: number;
+  representative: {
+    id: number;
+    url: string;
+    response_code: number;
+    title: string;
+    file_name: string;
+    screenshot: string;
+  };
+  titles: clustervalue[];
+  technologies: clustervalue[];
+}
+
+type clusters = {
+  clusters: cluster[];
+  page: number;
+  limit: number;
+  total_count: number;
+};
+
+interface imagehashes {
+  hashes: { [algorithm: string]: string; };
+}
+
 interface technologylist {
   technologies: string[];
 }
 
+interface taglist {
+  tags: string[];
+}
+
+interface categorylist {
+  categories: string[];
+}
+
+// live events
+interface liveresult {
+  id: number;
+  probed_at: string;
+  url: string;
+  response_code: number;
+  title: string;
+  file_name: string;
+  failed: boolean;
+}
+
+interface livejob {
+  id: string;
+  total: number;
+  done: number;
+  finished: boolean;
+}
+
+interface livedelete {
+  ids: number[];
+}
+
+interface livealert {
+  text: string;
+  search: {
+    id: number;
+    name: string;
+    query: string;
+  };
+  result: liveresult;
+}
+
+// saved searches
+interface alertrule {
+  id: number;
+  saved_search_id: number;
+  type: "webhook" | "file" | "events";
+  target: string;
+  enabled: boolean;
+}
+
+interface savedsearch {
+  id: number;
+  name: string;
+  query: string;
+  created_at: string;
+  alerts: alertrule[];
+}
+
 export type {
   statistics,
   wappalyzer,
@@ -172,13 +379,34 @@ export type {
   list,
   galleryResult,
   tls,
+  favicon,
   sanlist,
   technology,
   header,
   networklog,
   consolelog,
   cookie,
+  link,
+  forminput,
+  form,
+  script,
+  metatag,
+  blockedrequest,
+  tag,
   detail,
   searchresult,
+  cluster,
+  clusters,
   technologylist,
+  imagehashes,
+  taglist,
+  categorylist,
+  category,
+  defaultcredential,
+  liveresult,
+  livejob,
+  livedelete,
+  livealert,
+  alertrule,
+  savedsearch,
 };
\ No newline at end of file
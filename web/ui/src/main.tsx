@@ -8,6 +8,7 @@ import ErrorPage from '@/pages/Error';
 
 import DashboardPage from '@/pages/dashboard/Dashboard';
 import GalleryPage from '@/pages/gallery/Gallery';
+import ClustersPage from '@/pages/clusters/Clusters';
 import TablePage from '@/pages/table/Table';
 import ScreenshotDetailPage from '@/pages/detail/Detail';
 import SearchResultsPage from '@/pages/search/Search';
@@ -32,6 +33,10 @@ const router = createBrowserRouter([
         path: 'gallery',
         element: <GalleryPage />
       },
+      {
+        path: 'clusters',
+        element: <ClustersPage />
+      },
       {
         path: 'overview',
         element: <TablePage />
@@ -2,8 +2,21 @@ import Navigation from "@/components/navigation";
 import { ThemeProvider } from "@/components/theme-provider";
 import { Toaster } from "@/components/ui/toaster";
 import { Outlet } from "react-router-dom";
+import { useEffect } from "react";
+import { toast } from "@/hooks/use-toast";
+import * as api from "@/lib/api/api";
 
 const App = () => {
+  // surface saved search alerts wherever the user is
+  useEffect(() => {
+    return api.subscribe({
+      alert: (alert) => toast({
+        title: `Saved search: ${alert.search.name}`,
+        description: alert.result.url,
+      })
+    });
+  }, []);
+
   return (
     <ThemeProvider defaultTheme="dark" storageKey="ui-theme">
       <div className="flex min-h-screen w-full flex-col">
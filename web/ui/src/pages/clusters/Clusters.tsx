@@ -0,0 +1,283 @@
+import { useEffect, useState } from "react";
+import { Link, useSearchParams } from "react-router-dom";
+import {
+  ChevronLeftIcon, ChevronRightIcon, DownloadIcon, EyeOffIcon, LayersIcon, TagIcon
+} from "lucide-react";
+import { Button } from "@/components/ui/button";
+import { Badge } from "@/components/ui/badge";
+import { Card, CardContent, CardFooter, CardHeader, CardTitle } from "@/components/ui/card";
+import { Dialog, DialogContent, DialogHeader, DialogTitle, DialogTrigger } from "@/components/ui/dialog";
+import {
+  DropdownMenu, DropdownMenuContent, DropdownMenuItem, DropdownMenuTrigger
+} from "@/components/ui/dropdown-menu";
+import { Popover, PopoverContent, PopoverTrigger } from "@/components/ui/popover";
+import { Input } from "@/components/ui/input";
+import { Label } from "@/components/ui/label";
+import { Switch } from "@/components/ui/switch";
+import { WideSkeleton } from "@/components/loading";
+import { getStatusColor } from "@/lib/common";
+import * as api from "@/lib/api/api";
+import * as apitypes from "@/lib/api/types";
+import { clusterExportUrl, getClusterMembers, getClusters, triageCluster } from "./data";
+
+
+const screenshotSrc = (result: { screenshot: string; file_name: string; }) =>
+  result.screenshot
+    ? `data:image/png;base64,${result.screenshot}`
+    : api.endpoints.screenshot.path + "/" + result.file_name;
+
+// ClusterMembers lists the results in a cluster
+const ClusterMembers = ({ cluster }: { cluster: apitypes.cluster; }) => {
+  const [members, setMembers] = useState<apitypes.galleryResult[]>();
+
+  useEffect(() => {
+    getClusterMembers(setMembers, cluster.id);
+  }, [cluster.id]);
+
+  if (!members) return <WideSkeleton />;
+
+  return (
+    <div className="grid gap-4 grid-cols-2 md:grid-cols-3 lg:grid-cols-4 max-h-[70vh] overflow-y-auto">
+      {members.map(result => (
+        <Link to={`/screenshot/${result.id}`} key={result.id} className="group">
+          <div className="relative">
+            <img
+              src={screenshotSrc(result)}
+              alt={result.url}
+              loading="lazy"
+              className="w-full h-32 object-cover rounded-md"
+            />
+            <Badge className={`absolute top-1 right-1 ${getStatusColor(result.response_code)} text-white`}>
+              {result.response_code}
+            </Badge>
+          </div>
+          <p className="text-xs text-muted-foreground mt-1 truncate group-hover:underline">{result.url}</p>
+        </Link>
+      ))}
+      {cluster.count > members.length && (
+        <p className="col-span-full text-sm text-muted-foreground">
+          Showing {members.length} of {cluster.count} results. Export the cluster to get them all.
+        </p>
+      )}
+    </div>
+  );
+};
+
+// TagCluster tags every result in a cluster
+const TagCluster = ({ cluster, onDone }: { cluster: apitypes.cluster; onDone: () => void; }) => {
+  const [open, setOpen] = useState(false);
+  const [tag, setTag] = useState('');
+
+  const apply = async () => {
+    if (!tag.trim()) return;
+    if (await triageCluster(cluster.id, { add_tags: [tag.trim()] }, `Tagged ${cluster.count} results`)) {
+      setOpen(false);
+      setTag('');
+      onDone();
+    }
+  };
+
+  return (
+    <Popover open={open} onOpenChange={setOpen}>
+      <PopoverTrigger asChild>
+        <Button variant="outline" size="sm" title="Tag every result in this cluster">
+          <TagIcon className="h-4 w-4" />
+        </Button>
+      </PopoverTrigger>
+      <PopoverContent className="w-64">
+        <form
+          className="grid gap-2"
+          onSubmit={(e) => { e.preventDefault(); apply(); }}
+        >
+          <Label htmlFor={`cluster-tag-${cluster.id}`}>Tag all {cluster.count} results</Label>
+          <Input
+            id={`cluster-tag-${cluster.id}`}
+            placeholder="e.g. default-page"
+            value={tag}
+            onChange={(e) => setTag(e.target.value)}
+          />
+          <Button type="submit" size="sm">Apply</Button>
+        </form>
+      </PopoverContent>
+    </Popover>
+  );
+};
+
+const ClustersPage = () => {
+  const [clusters, setClusters] = useState<apitypes.cluster[]>();
+  const [totalPages, setTotalPages] = useState(0);
+  const [loading, setLoading] = useState(true);
+
+  const [searchParams, setSearchParams] = useSearchParams();
+  const page = parseInt(searchParams.get("page") || "1");
+  const limit = parseInt(searchParams.get("limit") || "24");
+  const hideReviewed = searchParams.get("reviewed") === "false";
+
+  const refresh = (quiet: boolean = false) =>
+    getClusters(setLoading, setClusters, setTotalPages, page, limit, hideReviewed, quiet);
+
+  useEffect(() => {
+    refresh();
+  }, [page, limit, hideReviewed]);
+
+  // new results can grow clusters, or make new ones
+  useEffect(() => {
+    return api.subscribe({ result: () => refresh(true), delete: () => refresh(true) });
+  }, [page, limit, hideReviewed]);
+
+  const handlePageChange = (newPage: number) => {
+    setSearchParams(prev => {
+      prev.set("page", newPage.toString());
+      return prev;
+    });
+  };
+
+  const handleToggleHideReviewed = (checked: boolean) => {
+    setSearchParams(prev => {
+      if (checked) {
+        prev.set("reviewed", "false");
+      } else {
+        prev.delete("reviewed");
+      }
+      prev.set("page", "1");
+      return prev;
+    });
+  };
+
+  const hideCluster = async (cluster: apitypes.cluster) => {
+    if (await triageCluster(cluster.id, { reviewed: true }, `Marked ${cluster.count} results as reviewed`)) {
+      refresh(true);
+    }
+  };
+
+  const renderClusterCard = (cluster: apitypes.cluster) => (
+    <Card key={cluster.id} className="flex flex-col h-full">
+      <CardHeader className="relative p-0">
+        <Link to={`/screenshot/${cluster.representative.id}`}>
+          <img
+            src={screenshotSrc(cluster.representative)}
+            alt={cluster.representative.url}
+            loading="lazy"
+            className="w-full h-48 object-cover rounded-t-lg"
+          />
+        </Link>
+        <Badge className="absolute top-2 right-2" title="Results in this cluster">
+          <LayersIcon className="mr-1 h-3 w-3" />
+          {cluster.count}
+        </Badge>
+        {cluster.reviewed > 0 && (
+          <Badge variant="secondary" className="absolute top-2 left-2">
+            {cluster.reviewed} reviewed
+          </Badge>
+        )}
+      </CardHeader>
+      <CardContent className="flex-grow p-4 space-y-2">
+        <CardTitle className="text-base line-clamp-1">
+          {cluster.titles[0]?.value || <span className="text-muted-foreground">No title</span>}
+        </CardTitle>
+        {cluster.titles.length > 1 && (
+          <ul className="text-xs text-muted-foreground">
+            {cluster.titles.slice(1).map(title => (
+              <li key={title.value} className="truncate">
+                {title.value || "No title"} <span>({title.count})</span>
+              </li>
+            ))}
+          </ul>
+        )}
+        <div className="flex flex-wrap gap-1">
+          {cluster.technologies.map(tech => (
+            <Badge key={tech.value} variant="outline" className="text-xs">
+              {tech.value} ({tech.count})
+            </Badge>
+          ))}
+        </div>
+      </CardContent>
+      <CardFooter className="p-4 pt-0 flex gap-2">
+        <Dialog>
+          <DialogTrigger asChild>
+            <Button variant="secondary" size="sm" className="flex-grow">Expand</Button>
+          </DialogTrigger>
+          <DialogContent className="max-w-5xl">
+            <DialogHeader>
+              <DialogTitle>
+                {cluster.titles[0]?.value || "Cluster"} <span className="text-muted-foreground">({cluster.count})</span>
+              </DialogTitle>
+            </DialogHeader>
+            <ClusterMembers cluster={cluster} />
+          </DialogContent>
+        </Dialog>
+        <TagCluster cluster={cluster} onDone={() => refresh(true)} />
+        <Button variant="outline" size="sm" title="Mark every result in this cluster as reviewed" onClick={() => hideCluster(cluster)}>
+          <EyeOffIcon className="h-4 w-4" />
+        </Button>
+        <DropdownMenu>
+          <DropdownMenuTrigger asChild>
+            <Button variant="outline" size="sm" title="Export this cluster">
+              <DownloadIcon className="h-4 w-4" />
+            </Button>
+          </DropdownMenuTrigger>
+          <DropdownMenuContent align="end">
+            <DropdownMenuItem asChild>
+              <a href={clusterExportUrl(cluster.id, 'jsonl')} download>JSON lines</a>
+            </DropdownMenuItem>
+            <DropdownMenuItem asChild>
+              <a href={clusterExportUrl(cluster.id, 'urls')} download>URL list</a>
+            </DropdownMenuItem>
+          </DropdownMenuContent>
+        </DropdownMenu>
+      </CardFooter>
+    </Card>
+  );
+
+  if (loading) return <WideSkeleton />;
+
+  return (
+    <div className="space-y-6">
+      <div className="flex flex-wrap justify-between items-center gap-4">
+        <div className="flex items-center gap-4">
+          <h1 className="text-2xl font-bold">Clusters</h1>
+          <div className="flex items-center space-x-2 p-2">
+            <Switch
+              id="hide-reviewed"
+              checked={hideReviewed}
+              onCheckedChange={handleToggleHideReviewed}
+            />
+            <Label htmlFor="hide-reviewed" className="text-sm">
+              Hide Reviewed
+            </Label>
+          </div>
+        </div>
+        <div className="flex items-center space-x-2">
+          <Button
+            variant="outline"
+            size="icon"
+            onClick={() => handlePageChange(page - 1)}
+            disabled={page <= 1}
+            title="Previous page"
+          >
+            <ChevronLeftIcon className="h-4 w-4" />
+          </Button>
+          <Button
+            variant="outline"
+            size="icon"
+            onClick={() => handlePageChange(page + 1)}
+            disabled={page >= totalPages}
+            title="Next page"
+          >
+            <ChevronRightIcon className="h-4 w-4" />
+          </Button>
+        </div>
+      </div>
+
+      {clusters && clusters.length > 0 ? (
+        <div className="grid gap-6 md:grid-cols-2 lg:grid-cols-3 xl:grid-cols-4">
+          {clusters.map(cluster => renderClusterCard(cluster))}
+        </div>
+      ) : (
+        <div className="text-center mt-8">No clusters found.</div>
+      )}
+    </div>
+  );
+};
+
+export default ClustersPage;
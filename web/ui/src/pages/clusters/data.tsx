@@ -0,0 +1,73 @@
+import * as api from "@/lib/api/api";
+import * as apitypes from "@/lib/api/types";
+import { toast } from "@/hooks/use-toast";
+
+const getClusters = async (
+  setLoading: React.Dispatch<React.SetStateAction<boolean>>,
+  setClusters: React.Dispatch<React.SetStateAction<apitypes.cluster[] | undefined>>,
+  setTotalPages: React.Dispatch<React.SetStateAction<number>>,
+  page: number,
+  limit: number,
+  hideReviewed: boolean,
+  quiet: boolean = false,
+) => {
+  if (!quiet) setLoading(true);
+  try {
+    const s = await api.get('clusters', {
+      page,
+      limit,
+      ...(hideReviewed ? { reviewed: 'false' } : {}),
+    });
+    setClusters(s.clusters);
+    setTotalPages(Math.ceil(s.total_count / limit));
+  } catch (err) {
+    toast({
+      title: "API Error",
+      variant: "destructive",
+      description: `Failed to get clusters: ${err}`
+    });
+  } finally {
+    if (!quiet) setLoading(false);
+  }
+};
+
+const getClusterMembers = async (
+  setMembers: React.Dispatch<React.SetStateAction<apitypes.galleryResult[] | undefined>>,
+  cluster: number,
+) => {
+  try {
+    const s = await api.get('gallery', { cluster, limit: 96 });
+    setMembers(s.results);
+  } catch (err) {
+    toast({
+      title: "API Error",
+      variant: "destructive",
+      description: `Failed to get cluster results: ${err}`
+    });
+  }
+};
+
+// triageCluster applies a triage change to every result in a cluster
+const triageCluster = async (cluster: number, change: object, description: string) => {
+  try {
+    await api.post('triage', { clusters: [cluster], ...change });
+  } catch (err) {
+    toast({
+      title: "Error",
+      variant: "destructive",
+      description: `Could not update cluster: ${err}`
+    });
+    return false;
+  }
+
+  toast({ description });
+  return true;
+};
+
+// clusterExportUrl returns the download url for a cluster export
+const clusterExportUrl = (cluster: number, format: 'jsonl' | 'urls') =>
+  api.endpoints.base.path +
+  api.endpoints.clusterexport.path.replace(':id', cluster.toString()) +
+  `?format=${format}`;
+
+export { getClusters, getClusterMembers, triageCluster, clusterExportUrl };
@@ -4,6 +4,7 @@ import { Card, CardContent, CardHeader, CardTitle } from "@/components/ui/card";
 import { DatabaseIcon, FileTextIcon, HardDriveIcon, NetworkIcon, TerminalIcon } from "lucide-react";
 import { Bar, BarChart, CartesianGrid, XAxis, YAxis, ResponsiveContainer } from "recharts";
 import { ChartContainer, ChartLegend, ChartLegendContent, ChartTooltip, ChartTooltipContent, type ChartConfig } from "@/components/ui/chart";
+import * as api from "@/lib/api/api";
 import * as apitypes from "@/lib/api/types";
 import { getData } from "./data";
 
@@ -38,6 +39,13 @@ export default function DashboardPage() {
     getData(setLoading, setStats);
   }, []);
 
+  // refresh statistics as results are written or removed
+  useEffect(() => {
+    const refresh = () => getData(setLoading, setStats, true);
+
+    return api.subscribe({ result: refresh, delete: refresh });
+  }, []);
+
   if (loading) return <WideSkeleton />;
 
   return (
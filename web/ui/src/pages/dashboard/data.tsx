@@ -5,8 +5,9 @@ import { toast } from "@/hooks/use-toast";
 const getData = async (
   setLoading: React.Dispatch<React.SetStateAction<boolean>>,
   setStats: React.Dispatch<React.SetStateAction<apitypes.statistics | undefined>>,
+  quiet: boolean = false,
 ) => {
-  setLoading(true);
+  if (!quiet) setLoading(true);
   try {
     const s = await api.get('statistics');
     setStats(s);
@@ -17,7 +18,7 @@ const getData = async (
       description: `Failed to get statistics: ${err}`
     });
   } finally {
-    setLoading(false);
+    if (!quiet) setLoading(false);
   }
 };
 
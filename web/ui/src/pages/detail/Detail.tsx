@@ -4,7 +4,10 @@ import { Table, TableBody, TableCell, TableHead, TableHeader, TableRow } from "@
 import { Badge } from "@/components/ui/badge";
 import { Button } from "@/components/ui/button";
 import { ScrollArea } from "@/components/ui/scroll-area";
-import { ExternalLink, ChevronLeft, ChevronRight, Code, ClockIcon, Trash2Icon, DownloadIcon, ImagesIcon, ZoomInIcon, CopyIcon } from 'lucide-react';
+import { ExternalLink, ChevronLeft, ChevronRight, Code, ClockIcon, Trash2Icon, DownloadIcon, ImagesIcon, ZoomInIcon, CopyIcon, XIcon, PlusIcon } from 'lucide-react';
+import { Input } from '@/components/ui/input';
+import { Label } from '@/components/ui/label';
+import { Switch } from '@/components/ui/switch';
 import { Dialog, DialogContent, DialogDescription, DialogFooter, DialogHeader, DialogTitle, DialogTrigger, } from "@/components/ui/dialog";
 import { WideSkeleton } from '@/components/loading';
 import { Form, Link, useNavigate, useParams } from 'react-router-dom';
@@ -14,7 +17,7 @@ import { Tabs, TabsContent, TabsList, TabsTrigger } from '@/components/ui/tabs';
 import { copyToClipboard, getIconUrl, getStatusColor } from '@/lib/common';
 import * as api from "@/lib/api/api";
 import * as apitypes from "@/lib/api/types";
-import { getData } from './data';
+import { getData, triageResult } from './data';
 
 
 const ScreenshotDetailPage = () => {
@@ -26,6 +29,8 @@ const ScreenshotDetailPage = () => {
   const [duration, setDuration] = useState<string>('');
   const [wappalyzer, setWappalyzer] = useState<apitypes.wappalyzer>({});
   const [loading, setLoading] = useState<boolean>(true);
+  const [newTag, setNewTag] = useState<string>('');
+  const [notes, setNotes] = useState<string>('');
   const navigate = useNavigate();
 
   const { id } = useParams<{ id: string; }>();
@@ -37,6 +42,10 @@ const ScreenshotDetailPage = () => {
     getData(setLoading, setDetail, setWappalyzer, setDuration, id);
   }, [id]);
 
+  useEffect(() => {
+    setNotes(detail?.notes || '');
+  }, [detail?.id, detail?.notes]);
+
   // handle arrowleft and arrowright events
   useEffect(() => {
     const handleKeyDown = (event: KeyboardEvent) => {
@@ -89,7 +98,7 @@ const ScreenshotDetailPage = () => {
 
   const handleSearchRedirect = () => {
     if (detail && detail.perception_hash) {
-      navigate(`/search?query=${detail.perception_hash}`);
+      navigate(`/search?query=${encodeURIComponent(`similar:${detail.id}`)}`);
     }
   };
 
@@ -229,7 +238,62 @@ const ScreenshotDetailPage = () => {
         <CardFooter className="flex justify-between items-center pt-4">
           <div>
             <h2 className="text-xl font-bold">{detail.title}</h2>
-            <p className="text-sm text-muted-foreground">{detail.url}</p>
+            <p className="text-sm text-muted-foreground">
+              {detail.original_method && (
+                <Badge variant="outline" className="mr-2">{detail.original_method}</Badge>
+              )}
+              {detail.url}
+            </p>
+            {detail.proxy ? (
+              <p className="text-xs text-muted-foreground">Scanned via {detail.proxy}</p>
+            ) : detail.resolved_ip && !detail.vhost && (
+              <p className="text-xs text-muted-foreground">
+                Resolved to{" "}
+                <Link to={`/search?query=${encodeURIComponent(`ip=${detail.resolved_ip}`)}`} className="underline">
+                  {detail.resolved_ip}
+                </Link>
+              </p>
+            )}
+            {detail.vhost && (
+              <p className="text-xs text-muted-foreground">
+                Virtual host {detail.vhost} requested from{" "}
+                <Link to={`/search?query=${encodeURIComponent(`ip=${detail.vhost_ip}`)}`} className="underline">
+                  {detail.vhost_ip}
+                </Link>
+              </p>
+            )}
+            {detail.discovered_from_url && (
+              <p className="text-xs text-muted-foreground">
+                Discovered via {detail.discovered_via} on{" "}
+                {detail.discovered_from_id ? (
+                  <Link to={`/screenshot/${detail.discovered_from_id}`} className="underline">
+                    {detail.discovered_from_url}
+                  </Link>
+                ) : (
+                  detail.discovered_from_url
+                )}{" "}
+                (depth {detail.discovery_depth})
+              </p>
+            )}
+            {(detail.blank || detail.error_page || detail.soft404) && (
+              <div className="flex flex-wrap gap-1 mt-2">
+                {detail.blank && (
+                  <Link to={`/search?query=${encodeURIComponent('is:blank')}`}>
+                    <Badge variant="destructive">blank screenshot</Badge>
+                  </Link>
+                )}
+                {detail.error_page && (
+                  <Link to={`/search?query=${encodeURIComponent('is:error_page')}`}>
+                    <Badge variant="destructive">browser error page</Badge>
+                  </Link>
+                )}
+                {detail.soft404 && (
+                  <Link to={`/search?query=${encodeURIComponent('is:soft404')}`}>
+                    <Badge variant="destructive">soft 404</Badge>
+                  </Link>
+                )}
+              </div>
+            )}
           </div>
           <Button onClick={() => window.open(detail.url, '_blank')}>
             <ExternalLink className="mr-2 h-4 w-4" />
@@ -295,6 +359,160 @@ const ScreenshotDetailPage = () => {
     );
   };
 
+  const handleAddTag = async (event: React.FormEvent) => {
+    event.preventDefault();
+    const tag = newTag.trim();
+    if (!tag) return;
+
+    if (await triageResult(setDetail, currentId, { add_tags: [tag] })) setNewTag('');
+  };
+
+  const triageCard = (detail: apitypes.detail) => {
+    return (
+      <Card>
+        <CardHeader className="flex flex-row items-center justify-between space-y-0 pb-2">
+          <CardTitle>Triage</CardTitle>
+          <div className="flex items-center space-x-2">
+            <Switch
+              id="reviewed"
+              checked={detail.reviewed}
+              onCheckedChange={(reviewed) => triageResult(setDetail, currentId, { reviewed })}
+            />
+            <Label htmlFor="reviewed" className="text-sm">
+              Reviewed
+            </Label>
+          </div>
+        </CardHeader>
+        <CardContent className="space-y-4">
+          <div className="flex flex-wrap gap-2">
+            {detail.tags?.length > 0 ? (
+              detail.tags.map((tag) => (
+                <Badge key={tag.id} variant="secondary" className="flex items-center">
+                  {tag.value}
+                  <button
+                    type="button"
+                    className="ml-1"
+                    title="Remove tag"
+                    onClick={() => triageResult(setDetail, currentId, { remove_tags: [tag.value] })}
+                  >
+                    <XIcon className="h-3 w-3" />
+                  </button>
+                </Badge>
+              ))
+            ) : (
+              <p className="text-muted-foreground text-sm">No tags</p>
+            )}
+          </div>
+          {detail.categories?.length > 0 && (
+            <div className="flex flex-wrap gap-2">
+              {detail.categories.map((category) => (
+                <Link key={category.id} to={`/search?query=${encodeURIComponent(`category="${category.value}"`)}`}>
+                  <Badge variant="outline" title={`matched signature ${category.signature}`}>
+                    {category.value}
+                  </Badge>
+                </Link>
+              ))}
+            </div>
+          )}
+          <form className="flex space-x-2" onSubmit={handleAddTag}>
+            <Input
+              placeholder="Add a tag..."
+              value={newTag}
+              onChange={(e) => setNewTag(e.target.value)}
+            />
+            <Button type="submit" variant="outline" size="sm" disabled={!newTag.trim()}>
+              <PlusIcon className="h-4 w-4" />
+            </Button>
+          </form>
+          <div className="space-y-2">
+            <textarea
+              className="flex min-h-[80px] w-full rounded-md border border-input bg-transparent px-3 py-2 text-sm shadow-sm placeholder:text-muted-foreground focus-visible:outline-none focus-visible:ring-1 focus-visible:ring-ring"
+              placeholder="Notes..."
+              value={notes}
+              onChange={(e) => setNotes(e.target.value)}
+            />
+            <Button
+              variant="outline"
+              size="sm"
+              disabled={notes === (detail.notes || '')}
+              onClick={() => triageResult(setDetail, currentId, { notes })}
+            >
+              Save Notes
+            </Button>
+          </div>
+        </CardContent>
+      </Card>
+    );
+  };
+
+  const credentialsCard = (detail: apitypes.detail) => {
+    if (!detail.default_credentials || detail.default_credentials.length === 0) return null;
+
+    // group the hints by product, keeping the order they were matched in
+    const products = detail.default_credentials.reduce((acc, credential) => {
+      if (!acc[credential.product]) acc[credential.product] = [];
+      acc[credential.product].push(credential);
+      return acc;
+    }, {} as Record<string, apitypes.defaultcredential[]>);
+
+    return (
+      <Card>
+        <CardHeader>
+          <CardTitle>Default Credentials</CardTitle>
+        </CardHeader>
+        <CardContent className="space-y-4">
+          <p className="text-sm text-muted-foreground">
+            Known defaults for the identified products. These have not been tried.
+          </p>
+          {Object.entries(products).map(([product, credentials]) => (
+            <div key={product} className="space-y-1">
+              <div className="flex items-center justify-between gap-2">
+                <span className="font-semibold">{product}</span>
+                <Badge variant="outline" className="font-normal">{credentials[0].match}</Badge>
+              </div>
+              <Table>
+                <TableBody>
+                  {credentials.map((credential) => (
+                    <TableRow key={credential.id}>
+                      <TableCell className="font-mono py-1">
+                        {credential.username}
+                        <CopyIcon
+                          className="inline ml-2 w-3 h-3 cursor-pointer"
+                          onClick={() => copyToClipboard(credential.username, 'Username')}
+                        />
+                      </TableCell>
+                      <TableCell className="font-mono py-1">
+                        {credential.password ? (
+                          <>
+                            {credential.password}
+                            <CopyIcon
+                              className="inline ml-2 w-3 h-3 cursor-pointer"
+                              onClick={() => copyToClipboard(credential.password, 'Password')}
+                            />
+                          </>
+                        ) : (
+                          <span className="text-muted-foreground italic">blank</span>
+                        )}
+                      </TableCell>
+                    </TableRow>
+                  ))}
+                </TableBody>
+              </Table>
+              {credentials[0].notes && (
+                <p className="text-xs text-muted-foreground">{credentials[0].notes}</p>
+              )}
+              {credentials[0].reference && (
+                <a href={credentials[0].reference} target="_blank" rel="noopener noreferrer" className="text-xs hover:underline inline-flex items-center">
+                  Reference <ExternalLink className="ml-1 w-3 h-3" />
+                </a>
+              )}
+            </div>
+          ))}
+        </CardContent>
+      </Card>
+    );
+  };
+
   const tlsCard = (detail: apitypes.detail) => {
     return (
       <Card>
@@ -357,6 +575,50 @@ const ScreenshotDetailPage = () => {
     );
   };
 
+  const faviconCard = (detail: apitypes.detail) => {
+    if (!detail.favicon || !detail.favicon.content) return null;
+
+    const hashes = [
+      { name: 'MMH3', value: detail.favicon.mmh3.toString() },
+      { name: 'MD5', value: detail.favicon.md5 },
+    ];
+
+    return (
+      <Card>
+        <CardHeader>
+          <CardTitle>Favicon</CardTitle>
+        </CardHeader>
+        <CardContent>
+          <div className="flex items-start gap-4">
+            <img
+              src={api.endpoints.base.path + api.endpoints.favicon.path.replace(':md5', detail.favicon.md5)}
+              alt="favicon"
+              className="w-12 h-12 object-contain border rounded p-1"
+            />
+            <dl className="grid grid-cols-[auto_1fr] gap-x-2 gap-y-1 text-sm min-w-0">
+              {hashes.map(hash => (
+                <div key={hash.name} className="contents">
+                  <dt className="font-semibold">{hash.name}:</dt>
+                  <dd className="font-mono break-all">
+                    <Link to={`/search?query=${encodeURIComponent(`favicon:${hash.value}`)}`} className="hover:underline">
+                      {hash.value}
+                    </Link>
+                    <CopyIcon
+                      className="inline ml-2 w-3 h-3 cursor-pointer"
+                      onClick={() => copyToClipboard(hash.value, `${hash.name} hash`)}
+                    />
+                  </dd>
+                </div>
+              ))}
+              <dt className="font-semibold">URL:</dt>
+              <dd className="truncate" title={detail.favicon.url}>{detail.favicon.url}</dd>
+            </dl>
+          </div>
+        </CardContent>
+      </Card>
+    );
+  };
+
   const summaryCard = (detail: apitypes.detail) => {
     return (
       <Card className="bg-gradient-to-r from-green-600 to-blue-500 text-white">
@@ -432,9 +694,33 @@ const ScreenshotDetailPage = () => {
     );
   };
 
-  const networkLogTab = (log: apitypes.networklog[]) => {
+  const networkLogTab = (log: apitypes.networklog[], blocked: apitypes.blockedrequest[]) => {
     return (
-      <TabsContent value="network">
+      <TabsContent value="network" className="space-y-4">
+        {blocked.length > 0 && (
+          <Card>
+            <CardHeader>
+              <CardTitle>Blocked Requests ({blocked.length})</CardTitle>
+            </CardHeader>
+            <CardContent>
+              <Table>
+                <TableBody>
+                  {blocked.map((request) => (
+                    <TableRow key={request.id}>
+                      <TableCell
+                        className="font-mono break-all cursor-pointer py-1"
+                        onClick={() => copyToClipboard(request.url, 'URL')}
+                      >
+                        {request.url}
+                      </TableCell>
+                      <TableCell className="text-muted-foreground text-nowrap py-1">{request.reason}</TableCell>
+                    </TableRow>
+                  ))}
+                </TableBody>
+              </Table>
+            </CardContent>
+          </Card>
+        )}
         <Card>
           <CardHeader>
             <div className="flex justify-between items-center">
@@ -686,6 +972,99 @@ const ScreenshotDetailPage = () => {
     );
   };
 
+  const elementsTab = (detail: apitypes.detail) => {
+    const forms = detail.forms || [];
+    const scripts = detail.scripts || [];
+    const links = detail.links || [];
+    const metaTags = detail.meta_tags || [];
+
+    return (
+      <TabsContent value="elements">
+        <Card>
+          <CardHeader>
+            <div className="flex justify-between items-center">
+              <CardTitle>Page Elements</CardTitle>
+            </div>
+          </CardHeader>
+          <CardContent className="space-y-6">
+            {forms.length + scripts.length + links.length + metaTags.length === 0 && (
+              <div className="text-center text-muted-foreground">No data</div>
+            )}
+            {forms.length > 0 && (
+              <div className="space-y-2">
+                <h3 className="font-semibold">Forms ({forms.length})</h3>
+                {forms.map((form) => (
+                  <div key={form.id} className="border rounded p-2 space-y-1">
+                    <div className="flex flex-wrap items-center gap-2 text-sm">
+                      {form.method && <Badge variant="outline">{form.method}</Badge>}
+                      <span className="font-mono break-all">{form.action || "(no form element)"}</span>
+                      {form.has_password && <Badge variant="destructive">password</Badge>}
+                      {form.has_file && <Badge variant="destructive">file upload</Badge>}
+                    </div>
+                    <div className="flex flex-wrap gap-1">
+                      {form.inputs?.map((input) => (
+                        <Link key={input.id} to={`/search?query=${encodeURIComponent(`input=${input.type}`)}`}>
+                          <Badge variant="secondary" className="font-mono font-normal">
+                            {input.name || "(unnamed)"}: {input.type}
+                          </Badge>
+                        </Link>
+                      ))}
+                    </div>
+                  </div>
+                ))}
+              </div>
+            )}
+            {scripts.length > 0 && (
+              <div className="space-y-2">
+                <h3 className="font-semibold">Scripts ({scripts.length})</h3>
+                <ul className="text-sm font-mono space-y-1">
+                  {scripts.map((script) => (
+                    <li key={script.id} className="break-all cursor-pointer" onClick={() => copyToClipboard(script.src, 'Script source')}>
+                      {script.src}
+                    </li>
+                  ))}
+                </ul>
+              </div>
+            )}
+            {metaTags.length > 0 && (
+              <div className="space-y-2">
+                <h3 className="font-semibold">Meta Tags ({metaTags.length})</h3>
+                <Table>
+                  <TableBody>
+                    {metaTags.map((meta) => (
+                      <TableRow key={meta.id}>
+                        <TableCell className="font-mono text-nowrap py-1">{meta.name}</TableCell>
+                        <TableCell className="font-mono break-all py-1">{meta.content}</TableCell>
+                      </TableRow>
+                    ))}
+                  </TableBody>
+                </Table>
+              </div>
+            )}
+            {links.length > 0 && (
+              <div className="space-y-2">
+                <h3 className="font-semibold">Links ({links.length})</h3>
+                <Table>
+                  <TableBody>
+                    {links.map((link) => (
+                      <TableRow key={link.id}>
+                        <TableCell className="font-mono break-all py-1">
+                          <a href={link.url} target="_blank" rel="noopener noreferrer" className="hover:underline">{link.url}</a>
+                        </TableCell>
+                        <TableCell className="py-1">{link.text}</TableCell>
+                        <TableCell className="py-1">{link.external && <Badge variant="outline">external</Badge>}</TableCell>
+                      </TableRow>
+                    ))}
+                  </TableBody>
+                </Table>
+              </div>
+            )}
+          </CardContent>
+        </Card>
+      </TabsContent>
+    );
+  };
+
   return (
     <div className="space-y-6">
       {getNavigation()}
@@ -693,8 +1072,11 @@ const ScreenshotDetailPage = () => {
         {/* Left Column */}
         <div className="w-full lg:w-2/5 space-y-4">
           {infoCard(detail)}
+          {triageCard(detail)}
+          {credentialsCard(detail)}
           {techCard(detail)}
           {tlsCard(detail)}
+          {faviconCard(detail)}
         </div>
 
         {/* Right Column */}
@@ -707,11 +1089,13 @@ const ScreenshotDetailPage = () => {
               <TabsTrigger value="console">Console Log</TabsTrigger>
               <TabsTrigger value="headers">Response Headers</TabsTrigger>
               <TabsTrigger value="cookies">Cookies</TabsTrigger>
+              <TabsTrigger value="elements">Page Elements</TabsTrigger>
             </TabsList>
-            {networkLogTab(detail.network)}
+            {networkLogTab(detail.network, detail.blocked_requests || [])}
             {consoleLogTab(detail.console)}
             {headersTab(detail.headers)}
             {cookiesTab(detail.cookies)}
+            {elementsTab(detail)}
           </Tabs>
         </div>
       </div>
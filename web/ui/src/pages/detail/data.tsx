@@ -58,4 +58,32 @@ const deleteResult = async (id: string): Promise<boolean> => {
   return true;
 };
 
-export { getData, deleteResult };
+type triageChange = {
+  add_tags?: string[];
+  remove_tags?: string[];
+  notes?: string;
+  reviewed?: boolean;
+};
+
+const triageResult = async (
+  setDetail: React.Dispatch<React.SetStateAction<apitypes.detail | undefined>>,
+  id: number,
+  change: triageChange,
+): Promise<boolean> => {
+  try {
+    await api.post('triage', { ids: [id], ...change });
+    setDetail(await api.get('detail', { id }));
+  } catch (error) {
+    toast({
+      title: "API Error",
+      variant: "destructive",
+      description: `Failed to update result: ${error}`
+    });
+
+    return false;
+  }
+
+  return true;
+};
+
+export { getData, deleteResult, triageResult };
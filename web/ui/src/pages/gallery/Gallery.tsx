@@ -5,8 +5,8 @@ import { Link, useSearchParams } from "react-router-dom";
 import { WideSkeleton } from "@/components/loading";
 import { Badge } from "@/components/ui/badge";
 import {
-  AlertOctagonIcon, BanIcon, CheckIcon, ChevronLeftIcon, ChevronRightIcon, ClockIcon, ExternalLinkIcon,
-  FilterIcon, GroupIcon, ShieldCheckIcon, XIcon
+  AlertOctagonIcon, BanIcon, CheckIcon, ChevronLeftIcon, ChevronRightIcon, CircleCheckIcon, ClockIcon,
+  ExternalLinkIcon, FilterIcon, GroupIcon, ShapesIcon, ShieldCheckIcon, StarIcon, TagIcon, XIcon
 } from "lucide-react";
 import { Tooltip, TooltipContent, TooltipProvider, TooltipTrigger } from "@/components/ui/tooltip";
 import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from "@/components/ui/select";
@@ -26,6 +26,8 @@ const GalleryPage = () => {
   const [gallery, setGallery] = useState<apitypes.galleryResult[]>();
   const [wappalyzer, setWappalyzer] = useState<apitypes.wappalyzer>();
   const [technology, setTechnology] = useState<apitypes.technologylist>();
+  const [tags, setTags] = useState<apitypes.taglist>();
+  const [categories, setCategories] = useState<apitypes.categorylist>();
   const [totalPages, setTotalPages] = useState(0);
   const [loading, setLoading] = useState(true);
 
@@ -36,20 +38,35 @@ const GalleryPage = () => {
   //filters
   const technologyFilter = searchParams.get("technologies") || "";
   const statusFilter = searchParams.get("status") || "";
+  const tagFilter = searchParams.get("tags") || "";
+  const categoryFilter = searchParams.get("categories") || "";
   // toggles
   const perceptionGroup = searchParams.get("perception") === "true";
+  const faviconGroup = searchParams.get("favicon") === "true";
   const showFailed = searchParams.get("failed") !== "false"; // Default to true
+  const hideReviewed = searchParams.get("reviewed") === "false";
+  const hideNoise = searchParams.get("blank") === "false";
 
   useEffect(() => {
-    getWappalyzerData(setWappalyzer, setTechnology);
+    getWappalyzerData(setWappalyzer, setTechnology, setTags, setCategories);
   }, []);
 
   useEffect(() => {
     getData(
       setLoading, setGallery, setTotalPages,
-      page, limit, technologyFilter, statusFilter, perceptionGroup, showFailed
+      page, limit, technologyFilter, statusFilter, tagFilter, categoryFilter, perceptionGroup, faviconGroup, showFailed, hideReviewed, hideNoise
     );
-  }, [page, limit, perceptionGroup, statusFilter, technologyFilter, showFailed]);
+  }, [page, limit, perceptionGroup, faviconGroup, statusFilter, technologyFilter, tagFilter, categoryFilter, showFailed, hideReviewed, hideNoise]);
+
+  // keep the gallery up to date as results are written or removed
+  useEffect(() => {
+    const refresh = () => getData(
+      setLoading, setGallery, setTotalPages,
+      page, limit, technologyFilter, statusFilter, tagFilter, categoryFilter, perceptionGroup, faviconGroup, showFailed, hideReviewed, hideNoise, true
+    );
+
+    return api.subscribe({ result: refresh, delete: refresh });
+  }, [page, limit, perceptionGroup, faviconGroup, statusFilter, technologyFilter, tagFilter, categoryFilter, showFailed, hideReviewed, hideNoise]);
 
   useEffect(() => {
     const handleKeyDown = (event: KeyboardEvent) => {
@@ -101,6 +118,40 @@ const GalleryPage = () => {
     handlePageChange(1); // back to page 1
   };
 
+  const handleTagChange = (tag: string) => {
+    const field = "tags";
+    setSearchParams(prev => {
+      const currentTags = prev.get(field)?.split(",").filter(Boolean) || [];
+
+      if (currentTags.includes(tag)) {
+        prev.set(field, currentTags.filter(t => t !== tag).join(","));
+      } else {
+        currentTags.push(tag);
+        prev.set(field, currentTags.join(","));
+      }
+
+      return prev;
+    });
+    handlePageChange(1); // back to page 1
+  };
+
+  const handleCategoryChange = (category: string) => {
+    const field = "categories";
+    setSearchParams(prev => {
+      const currentCategories = prev.get(field)?.split(",").filter(Boolean) || [];
+
+      if (currentCategories.includes(category)) {
+        prev.set(field, currentCategories.filter(c => c !== category).join(","));
+      } else {
+        currentCategories.push(category);
+        prev.set(field, currentCategories.join(","));
+      }
+
+      return prev;
+    });
+    handlePageChange(1); // back to page 1
+  };
+
   const handleStatusFilter = (status: string) => {
     setSearchParams(prev => {
       const currentStatus = prev.get("status")?.split(",").filter(Boolean) || [];
@@ -124,6 +175,38 @@ const GalleryPage = () => {
     });
   };
 
+  const handleGroupByFavicon = () => {
+    setSearchParams(prev => {
+      prev.set("favicon", (!faviconGroup).toString());
+      return prev;
+    });
+  };
+
+  const handleToggleHideReviewed = () => {
+    setSearchParams(prev => {
+      if (hideReviewed) {
+        prev.delete("reviewed");
+      } else {
+        prev.set("reviewed", "false");
+      }
+      return prev;
+    });
+  };
+
+  // blank screenshots, browser error pages and soft 404s are hidden together
+  const handleToggleHideNoise = () => {
+    setSearchParams(prev => {
+      for (const check of ["blank", "error_page", "soft404"]) {
+        if (hideNoise) {
+          prev.delete(check);
+        } else {
+          prev.set(check, "false");
+        }
+      }
+      return prev;
+    });
+  };
+
   const handleToggleShowFailed = () => {
     setSearchParams(prev => {
       prev.set("failed", (!showFailed).toString());
@@ -140,6 +223,24 @@ const GalleryPage = () => {
     ];
   }, [technology, technologyFilter]);
 
+  const sortedTags = useMemo(() => {
+    if (!tags) return [];
+    const selectedTags = tagFilter.split(',').filter(Boolean);
+    return [
+      ...selectedTags,
+      ...tags.tags.filter(tag => !selectedTags.includes(tag))
+    ];
+  }, [tags, tagFilter]);
+
+  const sortedCategories = useMemo(() => {
+    if (!categories) return [];
+    const selectedCategories = categoryFilter.split(',').filter(Boolean);
+    return [
+      ...selectedCategories,
+      ...categories.categories.filter(category => !selectedCategories.includes(category))
+    ];
+  }, [categories, categoryFilter]);
+
   const renderPageButtons = (visible: number) => {
     const pageButtons = [];
     const maxVisiblePages = visible;
@@ -185,6 +286,24 @@ const GalleryPage = () => {
                 className="w-full h-48 object-cover transition-all duration-300 filter group-hover:scale-105"
               />
             )}
+            {screenshot.reviewed && (
+              <div className="absolute top-2 left-2">
+                <Badge variant="secondary">
+                  <CircleCheckIcon className="mr-1 h-3 w-3" />
+                  Reviewed
+                </Badge>
+              </div>
+            )}
+            {screenshot.favicon && (
+              <div className="absolute bottom-2 left-2" title={`favicon mmh3: ${screenshot.favicon.mmh3}`}>
+                <img
+                  src={api.endpoints.base.path + api.endpoints.favicon.path.replace(':md5', screenshot.favicon.md5)}
+                  loading="lazy"
+                  alt="favicon"
+                  className="w-6 h-6 object-contain rounded bg-white p-0.5 shadow"
+                />
+              </div>
+            )}
             <div className="absolute top-2 right-2">
               <Badge variant="default" className={`${getStatusColor(screenshot.response_code)}`}>
                 {screenshot.response_code}
@@ -212,6 +331,27 @@ const GalleryPage = () => {
               <div className="w-full truncate text-xs text-muted-foreground mt-1">
                 {screenshot.url}
               </div>
+              {screenshot.tags && screenshot.tags.length > 0 && (
+                <div className="flex flex-wrap gap-1 mt-1">
+                  {screenshot.tags.map(tag => (
+                    <Badge key={tag} variant="outline" className="text-xs">{tag}</Badge>
+                  ))}
+                </div>
+              )}
+              {(screenshot.blank || screenshot.error_page || screenshot.soft404) && (
+                <div className="flex flex-wrap gap-1 mt-1">
+                  {screenshot.blank && <Badge variant="destructive" className="text-xs">blank</Badge>}
+                  {screenshot.error_page && <Badge variant="destructive" className="text-xs">error page</Badge>}
+                  {screenshot.soft404 && <Badge variant="destructive" className="text-xs">soft 404</Badge>}
+                </div>
+              )}
+              {screenshot.categories && screenshot.categories.length > 0 && (
+                <div className="flex flex-wrap gap-1 mt-1">
+                  {screenshot.categories.map(category => (
+                    <Badge key={category} variant="secondary" className="text-xs">{category}</Badge>
+                  ))}
+                </div>
+              )}
             </div>
             <div className="w-full flex items-center justify-between mt-2">
               <TooltipProvider delayDuration={0}>
@@ -304,6 +444,82 @@ const GalleryPage = () => {
               </Command>
             </PopoverContent>
           </Popover>
+          <Popover>
+            <PopoverTrigger asChild>
+              <Button variant="outline" className="w-[200px] justify-start">
+                <TagIcon className="mr-2 h-4 w-4" />
+                {tagFilter.split(',').filter(n => n).length > 0 ? (
+                  <>
+                    {tagFilter.split(',').filter(n => n).length} selected
+                  </>
+                ) : (
+                  "Filter by Tag"
+                )}
+              </Button>
+            </PopoverTrigger>
+            <PopoverContent className="w-[200px] p-0">
+              <Command>
+                <CommandInput placeholder="Search tags..." />
+                <CommandList>
+                  <CommandEmpty>No tag found.</CommandEmpty>
+                  <CommandGroup>
+                    {sortedTags.map((tag) => (
+                      <CommandItem
+                        key={tag}
+                        onSelect={() => handleTagChange(tag)}
+                      >
+                        <CheckIcon
+                          className={cn(
+                            "mr-2 h-4 w-4",
+                            tagFilter.split(',').includes(tag) ? "opacity-100" : "opacity-0"
+                          )}
+                        />
+                        {tag}
+                      </CommandItem>
+                    ))}
+                  </CommandGroup>
+                </CommandList>
+              </Command>
+            </PopoverContent>
+          </Popover>
+          <Popover>
+            <PopoverTrigger asChild>
+              <Button variant="outline" className="w-[200px] justify-start">
+                <ShapesIcon className="mr-2 h-4 w-4" />
+                {categoryFilter.split(',').filter(n => n).length > 0 ? (
+                  <>
+                    {categoryFilter.split(',').filter(n => n).length} selected
+                  </>
+                ) : (
+                  "Filter by Category"
+                )}
+              </Button>
+            </PopoverTrigger>
+            <PopoverContent className="w-[200px] p-0">
+              <Command>
+                <CommandInput placeholder="Search categories..." />
+                <CommandList>
+                  <CommandEmpty>No category found.</CommandEmpty>
+                  <CommandGroup>
+                    {sortedCategories.map((category) => (
+                      <CommandItem
+                        key={category}
+                        onSelect={() => handleCategoryChange(category)}
+                      >
+                        <CheckIcon
+                          className={cn(
+                            "mr-2 h-4 w-4",
+                            categoryFilter.split(',').includes(category) ? "opacity-100" : "opacity-0"
+                          )}
+                        />
+                        {category}
+                      </CommandItem>
+                    ))}
+                  </CommandGroup>
+                </CommandList>
+              </Command>
+            </PopoverContent>
+          </Popover>
           <Button
             variant={statusFilter.includes("200") ? "secondary" : "outline"}
             onClick={() => handleStatusFilter("200")}
@@ -332,6 +548,13 @@ const GalleryPage = () => {
             <GroupIcon className="mr-2 h-4 w-4" />
             Group by Similar
           </Button>
+          <Button
+            variant={faviconGroup ? "secondary" : "outline"}
+            onClick={handleGroupByFavicon}
+          >
+            <StarIcon className="mr-2 h-4 w-4" />
+            Group by Favicon
+          </Button>
           <div className="flex items-center space-x-2 p-2">
             <Switch
               id="show-failed"
@@ -342,6 +565,26 @@ const GalleryPage = () => {
               Show Failed
             </Label>
           </div>
+          <div className="flex items-center space-x-2 p-2">
+            <Switch
+              id="hide-reviewed"
+              checked={hideReviewed}
+              onCheckedChange={handleToggleHideReviewed}
+            />
+            <Label htmlFor="hide-reviewed" className="text-sm">
+              Hide Reviewed
+            </Label>
+          </div>
+          <div className="flex items-center space-x-2 p-2">
+            <Switch
+              id="hide-noise"
+              checked={hideNoise}
+              onCheckedChange={handleToggleHideNoise}
+            />
+            <Label htmlFor="hide-noise" className="text-sm" title="Hide blank screenshots, browser error pages and soft 404s">
+              Hide Blank &amp; Errors
+            </Label>
+          </div>
         </div>
         <div className="flex items-center space-x-2">
           <Button
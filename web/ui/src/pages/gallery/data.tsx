@@ -4,15 +4,21 @@ import { toast } from "@/hooks/use-toast";
 
 const getWappalyzerData = async (
   setWappalyzer: React.Dispatch<React.SetStateAction<apitypes.wappalyzer | undefined>>,
-  setTechnology: React.Dispatch<React.SetStateAction<apitypes.technologylist | undefined>>
+  setTechnology: React.Dispatch<React.SetStateAction<apitypes.technologylist | undefined>>,
+  setTags: React.Dispatch<React.SetStateAction<apitypes.taglist | undefined>>,
+  setCategories: React.Dispatch<React.SetStateAction<apitypes.categorylist | undefined>>
 ) => {
   try {
-    const [wappalyzerData, technologyData] = await Promise.all([
+    const [wappalyzerData, technologyData, tagData, categoryData] = await Promise.all([
       await api.get('wappalyzer'),
-      await api.get('technology')
+      await api.get('technology'),
+      await api.get('tags'),
+      await api.get('categories')
     ]);
     setWappalyzer(wappalyzerData);
     setTechnology(technologyData);
+    setTags(tagData);
+    setCategories(categoryData);
   } catch (err) {
     toast({
       title: "API Error",
@@ -30,18 +36,29 @@ const getData = async (
   limit: number,
   technologyFilter: string,
   statusFilter: string,
+  tagFilter: string,
+  categoryFilter: string,
   perceptionGroup: boolean,
+  faviconGroup: boolean,
   showFailed: boolean,
+  hideReviewed: boolean,
+  hideNoise: boolean,
+  quiet: boolean = false,
 ) => {
-  setLoading(true);
+  if (!quiet) setLoading(true);
   try {
     const s = await api.get('gallery', {
       page,
       limit,
       technologies: technologyFilter,
       status: statusFilter,
+      tags: tagFilter,
+      categories: categoryFilter,
       perception: perceptionGroup ? 'true' : 'false',
+      favicon: faviconGroup ? 'true' : 'false',
       failed: showFailed ? 'true' : 'false',
+      ...(hideReviewed ? { reviewed: 'false' } : {}),
+      ...(hideNoise ? { blank: 'false', error_page: 'false', soft404: 'false' } : {}),
     });
     setGallery(s.results);
     setTotalPages(Math.ceil(s.total_count / limit));
@@ -52,7 +69,7 @@ const getData = async (
       description: `Failed to get gallery: ${err}`
     });
   } finally {
-    setLoading(false);
+    if (!quiet) setLoading(false);
   }
 };
 
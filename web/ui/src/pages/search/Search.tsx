@@ -1,15 +1,116 @@
-import { Link, useLoaderData, useNavigation } from "react-router-dom";
+import { useState } from "react";
+import { Link, useLoaderData, useNavigation, useSearchParams } from "react-router-dom";
+import { BellIcon } from "lucide-react";
 import { Card, CardContent, CardHeader, CardTitle } from "@/components/ui/card";
 import { Badge } from "@/components/ui/badge";
 import * as api from "@/lib/api/api";
 import * as apitypes from "@/lib/api/types";
 import { WideSkeleton } from "@/components/loading";
 import { getStatusColor } from "@/lib/common";
+import { Button } from "@/components/ui/button";
+import { Input } from "@/components/ui/input";
+import { Label } from "@/components/ui/label";
+import { Popover, PopoverContent, PopoverTrigger } from "@/components/ui/popover";
+import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from "@/components/ui/select";
+import { toast } from "@/hooks/use-toast";
+
+const alertTypes = [
+  { value: 'none', label: 'No alert' },
+  { value: 'events', label: 'Web UI notification' },
+  { value: 'webhook', label: 'Webhook' },
+  { value: 'file', label: 'Append to file' },
+];
+
+// SaveSearch saves the current query, optionally with an alert rule that
+// fires when new results match it
+const SaveSearch = ({ query }: { query: string; }) => {
+  const [open, setOpen] = useState(false);
+  const [name, setName] = useState('');
+  const [alertType, setAlertType] = useState('none');
+  const [target, setTarget] = useState('');
+  const [saving, setSaving] = useState(false);
+
+  const save = async () => {
+    setSaving(true);
+    try {
+      await api.post('savesearch', {
+        name: name || query,
+        query,
+        alerts: alertType === 'none' ? [] : [{ type: alertType, target }],
+      });
+    } catch (err) {
+      toast({
+        title: "Error",
+        description: `Could not save search: ${err}`,
+        variant: "destructive"
+      });
+      return;
+    } finally {
+      setSaving(false);
+    }
+
+    toast({ description: "Search saved" });
+    setOpen(false);
+    setName('');
+    setTarget('');
+    setAlertType('none');
+  };
+
+  return (
+    <Popover open={open} onOpenChange={setOpen}>
+      <PopoverTrigger asChild>
+        <Button variant="outline" size="sm">
+          <BellIcon className="mr-2 h-4 w-4" />
+          Save search
+        </Button>
+      </PopoverTrigger>
+      <PopoverContent className="w-80" align="end">
+        <div className="grid gap-3">
+          <div className="grid gap-1">
+            <Label htmlFor="search-name">Name</Label>
+            <Input id="search-name" placeholder={query} value={name} onChange={(e) => setName(e.target.value)} />
+          </div>
+          <div className="grid gap-1">
+            <Label>Alert on new matches</Label>
+            <Select value={alertType} onValueChange={setAlertType}>
+              <SelectTrigger>
+                <SelectValue />
+              </SelectTrigger>
+              <SelectContent>
+                {alertTypes.map((type) => (
+                  <SelectItem key={type.value} value={type.value}>{type.label}</SelectItem>
+                ))}
+              </SelectContent>
+            </Select>
+          </div>
+          {(alertType === 'webhook' || alertType === 'file') && (
+            <div className="grid gap-1">
+              <Label htmlFor="alert-target">{alertType === 'webhook' ? 'Webhook URL' : 'File path'}</Label>
+              <Input
+                id="alert-target"
+                placeholder={alertType === 'webhook' ? 'https://hooks.example.com/...' : 'alerts.jsonl (inside the alerts directory)'}
+                value={target}
+                onChange={(e) => setTarget(e.target.value)}
+              />
+            </div>
+          )}
+          <Button onClick={save} disabled={saving}>Save</Button>
+        </div>
+      </PopoverContent>
+    </Popover>
+  );
+};
 
 
 export default function SearchResultsPage() {
-  const data = useLoaderData() as apitypes.searchresult[] | undefined;
+  const data = useLoaderData() as apitypes.searchresult[] | { error: string; } | undefined;
   const navigation = useNavigation();
+  const [searchParams] = useSearchParams();
+  const query = searchParams.get("query") || "";
+
+  if (data && 'error' in data) {
+    return <div className="text-center mt-8 text-red-500">{data.error}</div>;
+  }
 
   if (!data || data.length === 0) {
     return <div className="text-center mt-8">No results found.</div>;
@@ -19,9 +120,12 @@ export default function SearchResultsPage() {
 
   return (
     <div className=" mx-auto p-4">
-      <h1 className="text-2xl font-bold mb-4">
-        Search Results <span className="text-muted-foreground">({data.length})</span>
-      </h1>
+      <div className="flex items-center justify-between mb-4">
+        <h1 className="text-2xl font-bold">
+          Search Results <span className="text-muted-foreground">({data.length})</span>
+        </h1>
+        <SaveSearch query={query} />
+      </div>
       <div className="grid gap-4 md:grid-cols-2 lg:grid-cols-4">
         {data.map((result) => (
           <Link to={`/screenshot/${result.id}`} key={result.id} className="group">
@@ -45,6 +149,20 @@ export default function SearchResultsPage() {
               <CardContent className="flex-grow p-4">
                 <CardTitle className="text-lg mb-2 line-clamp-2">{result.title}</CardTitle>
                 <p className="text-sm text-muted-foreground mb-2 line-clamp-1">{result.final_url}</p>
+                {result.snippet && (
+                  // snippets are escaped by the api, with matches wrapped in <mark>
+                  <p
+                    className="text-sm mb-2 line-clamp-3 [&_mark]:bg-yellow-300 [&_mark]:text-black"
+                    dangerouslySetInnerHTML={{ __html: result.snippet }}
+                  />
+                )}
+                {result.categories?.length > 0 && (
+                  <div className="flex flex-wrap gap-1 mb-2">
+                    {result.categories.map((category) => (
+                      <Badge key={category} variant="secondary">{category}</Badge>
+                    ))}
+                  </div>
+                )}
                 <div className="mb-2">
                   <div className="flex flex-wrap gap-2">
                     <p className="text-sm font-semibold mb-1">Matched Fields:</p>
@@ -9,7 +9,11 @@ const searchLoader = async ({ request }: { request: Request; }) => {
     return { error: "No search query provided" };
   }
 
-  return await api.post('search', { query: decodeURIComponent(searchQuery) });
+  try {
+    return await api.post('search', { query: decodeURIComponent(searchQuery) });
+  } catch (err) {
+    return { error: `${err}` };
+  }
 };
 
 export { searchLoader };
\ No newline at end of file